@@ -0,0 +1,104 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/interval"
+)
+
+// TextAttributes is the formatting of a span of an AttributedString. Zero
+// fields use the defaults of the control displaying the string.
+type TextAttributes struct {
+	Font            Font
+	Color           *Color
+	BackgroundColor *Color
+	Underline       bool
+	Strikethrough   bool
+
+	// Link is the target of the span if it is a link, such as a URL.
+	Link string
+}
+
+// AttributedString is a string with TextAttributes applied to spans of its
+// runes. Runes outside of any span use the default attributes.
+type AttributedString struct {
+	runes []rune
+	spans interval.IntDataList
+}
+
+// CreateAttributedString returns an AttributedString of text, with no
+// attributes applied.
+func CreateAttributedString(text string) *AttributedString {
+	return &AttributedString{runes: []rune(text)}
+}
+
+// Text returns the text of the string.
+func (s *AttributedString) Text() string {
+	return string(s.runes)
+}
+
+// Runes returns the runes of the string.
+func (s *AttributedString) Runes() []rune {
+	return s.runes
+}
+
+// Len returns the number of runes in the string.
+func (s *AttributedString) Len() int {
+	return len(s.runes)
+}
+
+// Append adds text with the attributes a to the end of the string.
+func (s *AttributedString) Append(text string, a TextAttributes) {
+	start := len(s.runes)
+	s.runes = append(s.runes, []rune(text)...)
+	s.SetAttributes(start, len(s.runes), a)
+}
+
+// SetAttributes applies the attributes a to the runes [start, end),
+// replacing any attributes previously applied to them.
+func (s *AttributedString) SetAttributes(start, end int, a TextAttributes) {
+	if start < end {
+		interval.Replace(&s.spans, interval.CreateIntData(start, end, a))
+	}
+}
+
+// ClearAttributes removes the attributes from the runes [start, end).
+func (s *AttributedString) ClearAttributes(start, end int) {
+	if start < end {
+		interval.Remove(&s.spans, interval.CreateIntData(start, end, nil))
+	}
+}
+
+// Spans returns the spans of the string. The data of each span is its
+// TextAttributes.
+func (s *AttributedString) Spans() interval.IntDataList {
+	return s.spans
+}
+
+// AttributesAt returns the attributes of the rune at index i.
+func (s *AttributedString) AttributesAt(i int) TextAttributes {
+	idx := interval.IndexOf(&s.spans, uint64(i))
+	if idx < 0 {
+		return TextAttributes{}
+	}
+	return s.spans[idx].Data().(TextAttributes)
+}
+
+// Runs calls f for each run of consecutive runes with the same attributes,
+// in order, covering the whole string.
+func (s *AttributedString) Runs(f func(start, end int, a TextAttributes)) {
+	at := 0
+	for _, span := range s.spans {
+		start, end := span.Range()
+		if start > at {
+			f(at, start, TextAttributes{})
+		}
+		f(start, end, span.Data().(TextAttributes))
+		at = end
+	}
+	if at < len(s.runes) {
+		f(at, len(s.runes), TextAttributes{})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestAttributedString(t *testing.T) {
+	red, blue := Red, Blue
+	s := CreateAttributedString("Hello ")
+	s.Append("world", TextAttributes{Color: &red})
+	s.Append("!", TextAttributes{})
+	test.AssertEquals(t, "Hello world!", s.Text())
+	test.AssertEquals(t, TextAttributes{}, s.AttributesAt(0))
+	test.AssertEquals(t, TextAttributes{Color: &red}, s.AttributesAt(6))
+
+	s.SetAttributes(7, 9, TextAttributes{Color: &blue, Link: "x"})
+	type run struct {
+		start, end int
+		a          TextAttributes
+	}
+	runs := []run{}
+	s.Runs(func(start, end int, a TextAttributes) { runs = append(runs, run{start, end, a}) })
+	test.AssertEquals(t, []run{
+		{0, 6, TextAttributes{}},
+		{6, 7, TextAttributes{Color: &red}},
+		{7, 9, TextAttributes{Color: &blue, Link: "x"}},
+		{9, 11, TextAttributes{Color: &red}},
+		{11, 12, TextAttributes{}},
+	}, runs)
+
+	s.ClearAttributes(0, 8)
+	test.AssertEquals(t, TextAttributes{}, s.AttributesAt(6))
+	test.AssertEquals(t, TextAttributes{Color: &blue, Link: "x"}, s.AttributesAt(8))
+}
@@ -0,0 +1,300 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import "unicode"
+
+// bidiClass is the Bidi_Class property of a rune, as defined by Unicode
+// Standard Annex #9. Explicit embedding and isolate controls are not
+// supported and are treated as boundary neutrals.
+type bidiClass int
+
+const (
+	bcL   bidiClass = iota // Left-to-right
+	bcR                    // Right-to-left
+	bcAL                   // Arabic letter
+	bcEN                   // European number
+	bcES                   // European separator
+	bcET                   // European terminator
+	bcAN                   // Arabic number
+	bcCS                   // Common separator
+	bcNSM                  // Non-spacing mark
+	bcBN                   // Boundary neutral
+	bcB                    // Paragraph separator
+	bcWS                   // Whitespace
+	bcON                   // Other neutral
+)
+
+func bidiClassOf(r rune) bidiClass {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		return bcL
+	case r >= '0' && r <= '9', r >= 0x6f0 && r <= 0x6f9:
+		return bcEN
+	case r == '\n', r == '\r', r == 0x1c, r == 0x1d, r == 0x1e, r == 0x85, r == 0x2029:
+		return bcB
+	case r == '+', r == '-', r == 0x2212:
+		return bcES
+	case r == '#', r == '$', r == '%', r == 0xb0, r == 0x2030, r == 0x2031, unicode.Is(unicode.Sc, r):
+		return bcET
+	case r == ',', r == '.', r == '/', r == ':', r == 0xa0, r == 0x60c:
+		return bcCS
+	case r >= 0x660 && r <= 0x669, r == 0x66b, r == 0x66c:
+		return bcAN
+	case r == 0x200e:
+		return bcL // Left-to-right mark
+	case r == 0x200f:
+		return bcR // Right-to-left mark
+	case r == 0x61c:
+		return bcAL // Arabic letter mark
+	case unicode.IsSpace(r):
+		return bcWS
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bcNSM
+	case unicode.In(r, unicode.Cc, unicode.Cf):
+		return bcBN
+	case r >= 0x590 && r <= 0x5ff, // Hebrew
+		r >= 0x7c0 && r <= 0x85f,   // NKo, Samaritan and Mandaic
+		r >= 0xfb1d && r <= 0xfb4f, // Hebrew presentation forms
+		r >= 0x10800 && r <= 0x10fff,
+		r >= 0x1e800 && r <= 0x1edff,
+		r >= 0x1ef00 && r <= 0x1efff:
+		return bcR
+	case r >= 0x600 && r <= 0x7bf, // Arabic, Syriac, Arabic Supplement and Thaana
+		r >= 0x860 && r <= 0x8ff,
+		r >= 0xfb50 && r <= 0xfdff, // Arabic presentation forms A
+		r >= 0xfe70 && r <= 0xfeff, // Arabic presentation forms B
+		r >= 0x1ee00 && r <= 0x1eeff:
+		return bcAL
+	case unicode.In(r, unicode.L, unicode.Mc, unicode.Nd, unicode.Nl, unicode.Co):
+		return bcL
+	}
+	return bcON
+}
+
+// bidiDirection returns the direction, L or R, of the class c for the
+// purpose of resolving neutrals, with numbers counting as R. strong is false
+// if c is neutral.
+func bidiDirection(c bidiClass) (dir bidiClass, strong bool) {
+	switch c {
+	case bcL:
+		return bcL, true
+	case bcR, bcAL, bcEN, bcAN:
+		return bcR, true
+	}
+	return bcON, false
+}
+
+// BidiLevels returns the resolved embedding level of each of the runes,
+// following the Unicode Bidirectional Algorithm without explicit embeddings.
+// Each line is a separate paragraph, whose direction is that of its first
+// strong rune. Runes at even levels are left-to-right and runes at odd levels
+// are right-to-left.
+func BidiLevels(runes []rune) []int {
+	levels := make([]int, len(runes))
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		classes[i] = bidiClassOf(r)
+	}
+	for s := 0; s < len(runes); {
+		e := s
+		for e < len(runes) && classes[e] != bcB {
+			e++
+		}
+		base := resolveParagraph(classes[s:e], levels[s:e])
+		// L1: Trailing whitespace and paragraph separators take the
+		// paragraph level.
+		for i := e - 1; i >= s; i-- {
+			if c := bidiClassOf(runes[i]); c != bcWS && c != bcBN {
+				break
+			}
+			levels[i] = base
+		}
+		if e < len(runes) {
+			levels[e] = base
+		}
+		s = e + 1
+	}
+	return levels
+}
+
+func paragraphLevel(classes []bidiClass) int {
+	for _, c := range classes {
+		switch c {
+		case bcL:
+			return 0
+		case bcR, bcAL:
+			return 1
+		}
+	}
+	return 0
+}
+
+// resolveParagraph resolves the levels of a single paragraph with the given
+// bidi classes, which are modified, returning the paragraph level.
+func resolveParagraph(classes []bidiClass, levels []int) int {
+	n := len(classes)
+	base := paragraphLevel(classes)
+	sos := bcL
+	if base == 1 {
+		sos = bcR
+	}
+
+	// W1: Non-spacing marks take the class of the previous rune.
+	prev := sos
+	for i, c := range classes {
+		switch c {
+		case bcNSM:
+			classes[i] = prev
+		case bcBN:
+		default:
+			prev = c
+		}
+	}
+
+	// W2 and W3: European numbers after Arabic letters are Arabic numbers,
+	// and Arabic letters are right-to-left.
+	strong := sos
+	for i, c := range classes {
+		switch c {
+		case bcL, bcR:
+			strong = c
+		case bcAL:
+			strong = c
+			classes[i] = bcR
+		case bcEN:
+			if strong == bcAL {
+				classes[i] = bcAN
+			}
+		}
+	}
+
+	// W4: A single separator between two numbers of the same type joins
+	// them.
+	for i := 1; i+1 < n; i++ {
+		a, c, b := classes[i-1], classes[i], classes[i+1]
+		if a == bcEN && b == bcEN && (c == bcES || c == bcCS) {
+			classes[i] = bcEN
+		} else if a == bcAN && b == bcAN && c == bcCS {
+			classes[i] = bcAN
+		}
+	}
+
+	// W5: Terminators next to European numbers are European numbers.
+	for i := 0; i < n; i++ {
+		if classes[i] != bcET {
+			continue
+		}
+		j := i
+		for j < n && classes[j] == bcET {
+			j++
+		}
+		if (i > 0 && classes[i-1] == bcEN) || (j < n && classes[j] == bcEN) {
+			for k := i; k < j; k++ {
+				classes[k] = bcEN
+			}
+		}
+		i = j
+	}
+
+	// W6 and W7: Remaining separators and terminators are neutral, and
+	// European numbers in left-to-right text are left-to-right.
+	strong = sos
+	for i, c := range classes {
+		switch c {
+		case bcES, bcET, bcCS:
+			classes[i] = bcON
+		case bcL, bcR:
+			strong = c
+		case bcEN:
+			if strong == bcL {
+				classes[i] = bcL
+			}
+		}
+	}
+
+	// N1 and N2: Neutrals between runs of the same direction take that
+	// direction, otherwise they take the paragraph direction.
+	for i := 0; i < n; i++ {
+		if _, ok := bidiDirection(classes[i]); ok {
+			continue
+		}
+		j := i
+		for j < n {
+			if _, ok := bidiDirection(classes[j]); ok {
+				break
+			}
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before, _ = bidiDirection(classes[i-1])
+		}
+		if j < n {
+			after, _ = bidiDirection(classes[j])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			classes[k] = dir
+		}
+		i = j
+	}
+
+	// I1 and I2: Resolve the implicit levels.
+	for i, c := range classes {
+		level := base
+		switch {
+		case base%2 == 0 && c == bcR:
+			level++
+		case base%2 == 0 && (c == bcAN || c == bcEN):
+			level += 2
+		case base%2 == 1 && (c == bcL || c == bcEN || c == bcAN):
+			level++
+		}
+		levels[i] = level
+	}
+	return base
+}
+
+// VisualOrder returns the indices of the runes of a line with the given
+// embedding levels, in the order they are displayed from left to right.
+func VisualOrder(levels []int) []int {
+	order := make([]int, len(levels))
+	highest, lowestOdd := 0, 0
+	for i, l := range levels {
+		order[i] = i
+		if l > highest {
+			highest = l
+		}
+		if l%2 == 1 && (lowestOdd == 0 || l < lowestOdd) {
+			lowestOdd = l
+		}
+	}
+	if lowestOdd == 0 {
+		return order // All left-to-right
+	}
+	// L2: From the highest level to the lowest odd level, reverse every
+	// sequence of runes at that level or higher.
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
@@ -0,0 +1,22 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+// BlendMode controls how a layer pushed with Canvas.PushBlendMode is combined
+// with what is beneath it.
+type BlendMode int
+
+const (
+	// BlendNormal paints the layer over what is beneath it.
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the colors of the layer and what is beneath it,
+	// darkening.
+	BlendMultiply
+	// BlendScreen multiplies the inverted colors of the layer and what is
+	// beneath it, lightening.
+	BlendScreen
+	// BlendAdditive adds the colors of the layer to what is beneath it.
+	BlendAdditive
+)
@@ -4,6 +4,10 @@
 
 package guix
 
+import (
+	"github.com/vcaesar/guix/math"
+)
+
 var (
 	WhiteBrush       = CreateBrush(White)
 	TransparentBrush = CreateBrush(Transparent)
@@ -11,10 +15,172 @@ var (
 	DefaultBrush     = WhiteBrush
 )
 
+// Brush describes how the interior of a shape is filled.
 type Brush struct {
+	// Color is the solid color of the brush. It is ignored if Gradient is not
+	// nil.
 	Color Color
+
+	// Gradient, if not nil, fills the shape with a gradient instead of Color.
+	// Gradients are shared between copies of the brush and must not be
+	// modified once the brush has been used.
+	Gradient *Gradient
 }
 
 func CreateBrush(color Color) Brush {
-	return Brush{color}
+	return Brush{Color: color}
+}
+
+// CreateLinearGradientBrush returns a brush that fills with a linear gradient
+// from start to end. start and end are relative to the bounds of the shape
+// being filled, where (0, 0) is the top-left and (1, 1) is the bottom-right.
+func CreateLinearGradientBrush(start, end math.Vec2, stops ...GradientStop) Brush {
+	return Brush{Gradient: &Gradient{
+		Type:  LinearGradient,
+		Start: start,
+		End:   end,
+		Stops: stops,
+	}}
+}
+
+// CreateRadialGradientBrush returns a brush that fills with a radial gradient
+// around center. center and radius are relative to the bounds of the shape
+// being filled, so the gradient is elliptical when the shape is not square.
+func CreateRadialGradientBrush(center math.Vec2, radius float32, stops ...GradientStop) Brush {
+	return Brush{Gradient: &Gradient{
+		Type:   RadialGradient,
+		Start:  center,
+		Radius: radius,
+		Stops:  stops,
+	}}
+}
+
+// IsVisible returns false if the brush paints nothing.
+func (b Brush) IsVisible() bool {
+	if b.Gradient == nil {
+		return b.Color.A > 0
+	}
+	for _, s := range b.Gradient.Stops {
+		if s.Color.A > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GradientType is the shape of a Gradient.
+type GradientType int
+
+const (
+	// LinearGradient varies the color along the line from Start to End.
+	LinearGradient GradientType = iota
+	// RadialGradient varies the color with the distance from Start, reaching
+	// the last stop at Radius.
+	RadialGradient
+)
+
+// GradientRepeat controls how a Gradient is painted beyond its first and last
+// stops.
+type GradientRepeat int
+
+const (
+	// RepeatPad extends the colors of the first and last stops.
+	RepeatPad GradientRepeat = iota
+	// RepeatTile repeats the gradient.
+	RepeatTile
+	// RepeatReflect repeats the gradient, reversing every other repetition.
+	RepeatReflect
+)
+
+// GradientStop is a color at a position along a Gradient, where 0 is the
+// start and 1 is the end.
+type GradientStop struct {
+	Offset float32
+	Color  Color
+}
+
+// Gradient describes a color gradient. All positions are relative to the
+// bounds of the shape being filled, where (0, 0) is the top-left and (1, 1)
+// is the bottom-right.
+type Gradient struct {
+	Type   GradientType
+	Start  math.Vec2 // The start of a linear gradient or the center of a radial gradient
+	End    math.Vec2 // The end of a linear gradient
+	Radius float32   // The radius of a radial gradient
+	Stops  []GradientStop
+	Repeat GradientRepeat
+}
+
+// Offset returns the position along the gradient of the point p, where 0 is
+// the start and 1 is the end. The repeat mode is not applied.
+func (g *Gradient) Offset(p math.Vec2) float32 {
+	switch g.Type {
+	case RadialGradient:
+		if g.Radius <= 0 {
+			return 1
+		}
+		return p.Sub(g.Start).Len() / g.Radius
+	default:
+		d := g.End.Sub(g.Start)
+		l := d.SqrLen()
+		if l == 0 {
+			return 0
+		}
+		return p.Sub(g.Start).Dot(d) / l
+	}
+}
+
+// Wrap applies the repeat mode of the gradient to the offset t, returning an
+// offset between 0 and 1.
+func (g *Gradient) Wrap(t float32) float32 {
+	switch g.Repeat {
+	case RepeatTile:
+		return t - math.Floorf(t)
+	case RepeatReflect:
+		t = math.Absf(t)
+		f := t - 2*math.Floorf(t/2)
+		if f > 1 {
+			f = 2 - f
+		}
+		return f
+	default:
+		return math.Saturate(t)
+	}
+}
+
+// ColorAt returns the color of the gradient at the offset t, after applying
+// the repeat mode. Colors are interpolated between stops, which must be in
+// ascending order of offset.
+func (g *Gradient) ColorAt(t float32) Color {
+	stops := g.Stops
+	if len(stops) == 0 {
+		return Transparent
+	}
+	t = g.Wrap(t)
+	if t <= stops[0].Offset {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		a, b := stops[i-1], stops[i]
+		if t < b.Offset {
+			s := (t - a.Offset) / (b.Offset - a.Offset)
+			return Color{
+				R: math.Lerpf(a.Color.R, b.Color.R, s),
+				G: math.Lerpf(a.Color.G, b.Color.G, s),
+				B: math.Lerpf(a.Color.B, b.Color.B, s),
+				A: math.Lerpf(a.Color.A, b.Color.A, s),
+			}
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+// IsOpaque returns true if every stop of the gradient is fully opaque.
+func (g *Gradient) IsOpaque() bool {
+	for _, s := range g.Stops {
+		if s.Color.A < 1 {
+			return false
+		}
+	}
+	return len(g.Stops) > 0
 }
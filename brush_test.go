@@ -0,0 +1,64 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestGradientOffset(t *testing.T) {
+	l := CreateLinearGradientBrush(math.Vec2{X: 0, Y: 0}, math.Vec2{X: 0, Y: 1}).Gradient
+	test.AssertEquals(t, float32(0), l.Offset(math.Vec2{X: 1, Y: 0}))
+	test.AssertEquals(t, float32(0.5), l.Offset(math.Vec2{X: 0.25, Y: 0.5}))
+	test.AssertEquals(t, float32(2), l.Offset(math.Vec2{X: 0, Y: 2}))
+
+	r := CreateRadialGradientBrush(math.Vec2{X: 0.5, Y: 0.5}, 0.5).Gradient
+	test.AssertEquals(t, float32(0), r.Offset(math.Vec2{X: 0.5, Y: 0.5}))
+	test.AssertEquals(t, float32(1), r.Offset(math.Vec2{X: 1, Y: 0.5}))
+
+	// Degenerate gradients give finite offsets, as the GL shader does.
+	p := CreateLinearGradientBrush(math.Vec2{X: 0.5}, math.Vec2{X: 0.5}).Gradient
+	test.AssertEquals(t, float32(0), p.Offset(math.Vec2{X: 1, Y: 1}))
+	z := CreateRadialGradientBrush(math.Vec2{X: 0.5, Y: 0.5}, 0).Gradient
+	test.AssertEquals(t, float32(1), z.Offset(math.Vec2{X: 0.5, Y: 0.5}))
+}
+
+func TestGradientRepeat(t *testing.T) {
+	g := &Gradient{}
+	for _, c := range []struct {
+		repeat   GradientRepeat
+		in       float32
+		expected float32
+	}{
+		{RepeatPad, -0.5, 0},
+		{RepeatPad, 1.5, 1},
+		{RepeatTile, 1.25, 0.25},
+		{RepeatTile, -0.25, 0.75},
+		{RepeatReflect, 1.25, 0.75},
+		{RepeatReflect, 2.25, 0.25},
+		{RepeatReflect, -0.25, 0.25},
+	} {
+		g.Repeat = c.repeat
+		test.AssertEquals(t, c.expected, g.Wrap(c.in))
+	}
+}
+
+func TestGradientColorAt(t *testing.T) {
+	g := CreateLinearGradientBrush(math.Vec2{}, math.Vec2{X: 1},
+		GradientStop{Offset: 0.25, Color: Black},
+		GradientStop{Offset: 0.75, Color: White},
+	).Gradient
+	test.AssertEquals(t, Black, g.ColorAt(0))
+	test.AssertEquals(t, Gray50, g.ColorAt(0.5))
+	test.AssertEquals(t, White, g.ColorAt(1))
+	test.AssertEquals(t, true, g.IsOpaque())
+
+	test.AssertEquals(t, Transparent, (&Gradient{}).ColorAt(0.5))
+	test.AssertEquals(t, false, Brush{Gradient: &Gradient{}}.IsVisible())
+	test.AssertEquals(t, false, TransparentBrush.IsVisible())
+}
@@ -12,4 +12,10 @@ type BubbleOverlay interface {
 	Control
 	Show(control Control, target math.Point)
 	Hide()
+
+	// Shadow returns the drop shadow drawn beneath the bubble.
+	Shadow() Shadow
+
+	// SetShadow sets the drop shadow drawn beneath the bubble.
+	SetShadow(Shadow)
 }
@@ -14,7 +14,32 @@ type Canvas interface {
 	Complete()
 	Push()
 	Pop()
+
+	// PushOpacity is like Push, but everything drawn until the matching Pop
+	// is composited as a single layer, faded by alpha.
+	PushOpacity(alpha float32)
+
+	// PushBlendMode is like Push, but everything drawn until the matching
+	// Pop is composited as a single layer, combined with what is beneath it
+	// by mode.
+	PushBlendMode(mode BlendMode)
+
 	AddClip(math.Rect)
+
+	// Transform multiplies the canvas's transform by m, so that m is applied to
+	// everything drawn afterwards, before any existing transform. The
+	// transform is saved by Push and restored by Pop, and applies to clips
+	// and nested canvases. Drivers that cannot clip to a rotated rectangle
+	// clip to the bounding rectangle of the transformed clip.
+	Transform(m math.Mat3)
+
+	// Translate, Rotate and Scale call Transform with a translation, a
+	// clockwise rotation in radians about the origin, and a scale about the
+	// origin respectively.
+	Translate(x, y float32)
+	Rotate(radians float32)
+	Scale(x, y float32)
+
 	Clear(Color)
 	DrawCanvas(c Canvas, position math.Point)
 	DrawTexture(t Texture, bounds math.Rect)
@@ -23,4 +48,14 @@ type Canvas interface {
 	DrawPolygon(Polygon, Pen, Brush)
 	DrawRect(math.Rect, Brush)
 	DrawRoundedRect(rect math.Rect, tl, tr, bl, br float32, p Pen, b Brush)
+
+	// DrawShadow draws the drop shadow s of the rounded rectangle with the
+	// given corner radii. The shadow is not clipped by the rectangle, so it
+	// should be drawn before the rectangle it belongs to.
+	DrawShadow(rect math.Rect, tl, tr, bl, br float32, s Shadow)
+
+	// DrawPath fills the path with the brush, using the path's fill rule, and
+	// then strokes its outline with the pen. Unlike DrawPolygon, the stroke is
+	// centered on the outline. The path may be modified after the call.
+	DrawPath(path *Path2D, p Pen, b Brush)
 }
@@ -23,4 +23,61 @@ type CodeEditor interface {
 	SetSuggestionProvider(CodeSuggestionProvider)
 	ShowSuggestionList()
 	HideSuggestionList()
+
+	// FindLayer returns the layer that highlights the matches of the find
+	// query while the find bar is showing.
+	FindLayer() *CodeSyntaxLayer
+
+	// GutterVisible returns true if the gutter, showing the line numbers and
+	// markers to the left of the lines, is visible.
+	GutterVisible() bool
+	SetGutterVisible(bool)
+
+	// AddMarker adds a marker of the given kind to the line, returning it.
+	// The marker moves with the line as the text is edited.
+	AddMarker(line int, kind CodeMarkerKind) *CodeMarker
+	RemoveMarker(*CodeMarker)
+	ClearMarkers()
+	Markers() CodeMarkers
+
+	// MarkersAt returns the markers of the line.
+	MarkersAt(line int) CodeMarkers
+
+	// OnGutterClicked subscribes f to be called when the gutter beside a line
+	// is clicked, after the click events of any markers clicked.
+	OnGutterClicked(f func(line int, ev MouseEvent)) EventSubscription
+
+	// FoldProvider returns the provider of the fold ranges, or nil if the
+	// ranges are computed from the indentation of the lines.
+	FoldProvider() CodeFoldProvider
+	SetFoldProvider(CodeFoldProvider)
+
+	// FoldingEnabled returns true if the fold toggles are shown in the
+	// gutter.
+	FoldingEnabled() bool
+
+	// SetFoldingEnabled shows or hides the fold toggles. Disabling folding
+	// unfolds every range.
+	SetFoldingEnabled(bool)
+
+	// FoldRanges returns the ranges of lines that can be folded.
+	FoldRanges() []CodeFoldRange
+
+	// Fold hides the lines of the fold range starting on the line, moving
+	// any carets in them to the end of the line. It returns false if no
+	// range starts on the line. The folded lines move with edits to the
+	// text, and stay folded until unfolded or deleted.
+	Fold(line int) bool
+
+	// Unfold shows the lines of the fold range starting on the line,
+	// returning false if the range was not folded.
+	Unfold(line int) bool
+
+	ToggleFold(line int) bool
+	IsFolded(line int) bool
+	FoldAll()
+	UnfoldAll()
+
+	// IsLineHidden returns true if the line is hidden by a folded range.
+	IsLineHidden(line int) bool
 }
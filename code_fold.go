@@ -0,0 +1,64 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"sort"
+	"unicode"
+)
+
+// CodeFoldRange is a range of lines of a CodeEditor that can be folded. The
+// Start line shows the fold toggle and stays visible when the range is
+// folded, hiding the lines after it up to and including End.
+type CodeFoldRange struct {
+	Start, End int
+}
+
+// CodeFoldProvider supplies the ranges of lines that can be folded. The
+// ranges are requested when they are next needed after the text changes, and
+// must be ordered by their Start line, with at most one range starting on
+// each line.
+type CodeFoldProvider interface {
+	FoldRanges() []CodeFoldRange
+}
+
+// IndentFoldRanges returns the fold ranges of the text of c computed from the
+// indentation of its lines. Each line is the start of a range spanning the
+// lines after it that are more indented, ignoring blank lines at the end of
+// the range.
+func IndentFoldRanges(c *TextBoxController) []CodeFoldRange {
+	type open struct{ line, indent int }
+	ranges, stack, last := []CodeFoldRange{}, []open{}, -1
+	// closeRanges ends the ranges of the open lines indented at least indent.
+	closeRanges := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			if o := stack[len(stack)-1]; last > o.line {
+				ranges = append(ranges, CodeFoldRange{o.line, last})
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for l, count := 0, c.LineCount(); l < count; l++ {
+		if isBlankLine(c.LineRunes(l)) {
+			continue
+		}
+		indent := c.LineIndent(l)
+		closeRanges(indent)
+		stack = append(stack, open{l, indent})
+		last = l
+	}
+	closeRanges(0)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	return ranges
+}
+
+func isBlankLine(runes []rune) bool {
+	for _, r := range runes {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
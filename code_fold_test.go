@@ -0,0 +1,28 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	test "github.com/vcaesar/guix/testing"
+	"testing"
+)
+
+func TestIndentFoldRanges(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText("func a() {\n  if x {\n    y()\n\n  }\n\n}\nb\n  c\n")
+	test.AssertEquals(t, []CodeFoldRange{{0, 4}, {1, 2}, {7, 8}}, IndentFoldRanges(c))
+}
+
+func TestIndentFoldRangesTrailingBlankLines(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText("a\n  b\n\n  \nc")
+	test.AssertEquals(t, []CodeFoldRange{{0, 1}}, IndentFoldRanges(c))
+}
+
+func TestIndentFoldRangesFlat(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText("a\nb\n\nc")
+	test.AssertEquals(t, []CodeFoldRange{}, IndentFoldRanges(c))
+}
@@ -0,0 +1,96 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// CodeMarkerKind is the kind of a CodeMarker, which decides how it is drawn
+// in the gutter of a CodeEditor.
+type CodeMarkerKind int
+
+const (
+	BreakpointMarker CodeMarkerKind = iota
+	ErrorMarker
+	WarningMarker
+	DiffAddedMarker
+	DiffModifiedMarker
+	DiffRemovedMarker // Marks the line following removed lines
+)
+
+// CodeMarker marks a line in the gutter of a CodeEditor. The marker is
+// anchored to a rune of the line, so that it moves with the line as the text
+// is edited.
+type CodeMarker struct {
+	kind      CodeMarkerKind
+	runeIndex int
+	data      interface{}
+	onClick   Event
+}
+
+func CreateCodeMarker(kind CodeMarkerKind, runeIndex int) *CodeMarker {
+	return &CodeMarker{kind: kind, runeIndex: runeIndex}
+}
+
+func (m *CodeMarker) Kind() CodeMarkerKind {
+	return m.kind
+}
+
+// RuneIndex returns the index of the rune the marker is anchored to.
+func (m *CodeMarker) RuneIndex() int {
+	return m.runeIndex
+}
+
+func (m *CodeMarker) Data() interface{} {
+	return m.data
+}
+
+func (m *CodeMarker) SetData(data interface{}) {
+	m.data = data
+}
+
+// OnClick subscribes f to be called when the marker is clicked in the gutter.
+func (m *CodeMarker) OnClick(f func(MouseEvent)) EventSubscription {
+	if m.onClick == nil {
+		m.onClick = CreateEvent(f)
+	}
+	return m.onClick.Listen(f)
+}
+
+// Click fires the click event of the marker.
+func (m *CodeMarker) Click(ev MouseEvent) {
+	if m.onClick != nil {
+		m.onClick.Fire(ev)
+	}
+}
+
+type CodeMarkers []*CodeMarker
+
+// UpdateRuneIndices moves the markers for the edits, in the same way as
+// CodeSyntaxLayer.UpdateSpans moves the spans. A marker at the start of an
+// insertion moves with the inserted text, so that a marker stays with its
+// line when a line is inserted above. A marker in deleted text moves to the
+// start of the deletion.
+func (l CodeMarkers) UpdateRuneIndices(runeCount int, edits []TextBoxEdit) {
+	for _, e := range edits {
+		for _, m := range l {
+			if m.runeIndex >= e.At {
+				m.runeIndex = math.Clamp(math.Max(m.runeIndex+e.Delta, e.At), 0, runeCount)
+			}
+		}
+	}
+}
+
+// Remove removes m from the list, returning true if it was found.
+func (l *CodeMarkers) Remove(m *CodeMarker) bool {
+	for i, n := range *l {
+		if n == m {
+			*l = append((*l)[:i], (*l)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// CompositionEvent describes text composed with an input method editor (IME),
+// as used to type Chinese, Japanese and Korean text. While a composition is in
+// progress the input method updates the pre-edit text, which is shown in place
+// until it is committed.
+type CompositionEvent struct {
+	// Text is the pre-edit text. When a composition ends, Text is the text
+	// committed, or empty if the composition was cancelled.
+	Text string
+
+	// Cursor is the rune index of the cursor in the pre-edit text.
+	Cursor int
+}
+
+// Composer is the interface implemented by controls that accept text composed
+// with an input method. Composition events are sent to the focused control or,
+// if it is not a Composer, its nearest ancestor that is.
+type Composer interface {
+	Control
+
+	// CompositionStart is called when the input method starts composing text.
+	CompositionStart(CompositionEvent)
+
+	// CompositionUpdate is called when the pre-edit text or cursor changes.
+	CompositionUpdate(CompositionEvent)
+
+	// CompositionEnd is called when the pre-edit text is committed or the
+	// composition is cancelled.
+	CompositionEnd(CompositionEvent)
+
+	// CompositionRect returns the bounds of the pre-edit text, relative to
+	// the control. The input method's candidate window is anchored to it.
+	CompositionRect() math.Rect
+}
@@ -55,6 +55,14 @@ type Control interface {
 	// relayout if the margin has changed.
 	SetMargin(math.Spacing)
 
+	// ID returns the identifier assigned to the control with SetID, or an
+	// empty string if the control has no identifier.
+	ID() string
+
+	// SetID assigns an identifier to the control. Identifiers are not required
+	// to be unique, and are used to locate controls with Query.
+	SetID(string)
+
 	// IsVisible returns true if the control is visible.
 	IsVisible() bool
 
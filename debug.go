@@ -62,7 +62,15 @@ func BreadcrumbsAt(p Container, pnt math.Point) string {
 	return s
 }
 
+// Path returns the types of p and its ancestors, for debugging.
+//
+// Deprecated: Use ControlPath.
 func Path(p interface{}) string {
+	return ControlPath(p)
+}
+
+// ControlPath returns the types of p and its ancestors, for debugging.
+func ControlPath(p interface{}) string {
 	if p == nil {
 		return "nil"
 	}
@@ -71,7 +79,7 @@ func Path(p interface{}) string {
 
 	if c, _ := p.(Control); c != nil {
 		if c.Parent() != nil {
-			return Path(c.Parent()) + " > " + s
+			return ControlPath(c.Parent()) + " > " + s
 		}
 	}
 
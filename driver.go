@@ -25,7 +25,10 @@ type Driver interface {
 	SetClipboard(str string)
 	GetClipboard() (string, error)
 
-	// CreateFont loads a font from the provided TrueType bytes.
+	// CreateFont loads a font from the provided TrueType bytes. Use
+	// gxfont.Faces to load the fonts of a collection, and CreateFontFamily to
+	// chain fonts together. gxfont.Faces also converts fonts with PostScript
+	// (CFF) outlines, such as most .otf fonts, to TrueType.
 	CreateFont(data []byte, size int) (Font, error)
 
 	// CreateWindowedViewport creates a new windowed Viewport with the specified
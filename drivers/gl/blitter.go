@@ -36,6 +36,18 @@ const (
     gl_FragColor = texture2D(source, vTexcoords);
   }`
 
+	fsLayerSrc = `
+  #ifdef GL_ES
+    precision mediump float;
+  #endif
+
+  uniform sampler2D source;
+  uniform float Opacity;
+  varying vec2 vTexcoords;
+  void main() {
+    gl_FragColor = texture2D(source, vTexcoords) * Opacity;
+  }`
+
 	vsColorSrc = `
   attribute vec2 aPosition;
   uniform mat3 mPos;
@@ -55,14 +67,62 @@ const (
     gl_FragColor *= gl_FragColor.a; // PMA
   }`
 
+	vsGradientSrc = `
+  attribute vec2 aPosition;
+  varying vec2 vUnit;
+  uniform mat3 mPos;
+  uniform mat3 mUnit;
+  void main() {
+    vec3 pos3 = vec3(aPosition, 1.0);
+    gl_Position = vec4((mPos * pos3).xy, 0.0, 1.0);
+    vUnit = (mUnit * pos3).xy;
+  }`
+
+	fsGradientSrc = `
+  #ifdef GL_ES
+    precision mediump float;
+  #endif
+
+  uniform sampler2D Ramp;
+  uniform vec2 Start;
+  uniform vec2 End;
+  uniform float Radius;
+  uniform float Radial;
+  uniform float Repeat;
+  varying vec2 vUnit;
+  void main() {
+    // Degenerate gradients are handled as guix.Gradient.Offset does.
+    float t;
+    if (Radial > 0.5) {
+      t = Radius > 0.0 ? length(vUnit - Start) / Radius : 1.0;
+    } else {
+      vec2 d = End - Start;
+      float l = dot(d, d);
+      t = l > 0.0 ? dot(vUnit - Start, d) / l : 0.0;
+    }
+    if (Repeat > 1.5) {
+      t = 1.0 - abs(mod(t, 2.0) - 1.0); // Reflect
+    } else if (Repeat > 0.5) {
+      t = fract(t); // Tile
+    } else {
+      t = clamp(t, 0.0, 1.0); // Pad
+    }
+    // Sample between the centers of the first and last texels.
+    float u = (t * 255.0 + 0.5) / 256.0;
+    gl_FragColor = texture2D(Ramp, vec2(u, 0.5));
+    gl_FragColor.rgb *= gl_FragColor.a; // PMA
+  }`
+
 	vsFontSrc = `
   attribute vec2 aSrc;
   attribute vec2 aDst;
   attribute vec4 aClp;
   attribute vec4 aCol;
+  attribute vec2 aSty;
   varying vec2 vSrc;
   varying vec4 vCol;
   varying vec2 vClp;
+  varying vec2 vSty;
   uniform mat3 mSrc;
   uniform mat3 mDst;
   void main() {
@@ -72,6 +132,7 @@ const (
     vSrc = (mSrc * vec3(aSrc, 1.0)).xy;
     vClp = (gl_Position.xy - vClipMin) / (vClipMax - vClipMin);
     vCol = aCol;
+    vSty = aSty;
   }`
 
 	fsFontSrc = `
@@ -83,9 +144,15 @@ const (
   varying vec2 vSrc;
   varying vec4 vCol;
   varying vec2 vClp;
+  varying vec2 vSty; // The SDF smoothing, or 0 for bitmaps, and 1/gamma
   void main() {
     vec2 clipping = step(vec2(0.0, 0.0), vClp) * step(vClp, vec2(1.0, 1.0));
-    gl_FragColor  = vCol * texture2D(source, vSrc).aaaa;
+    float coverage = texture2D(source, vSrc).a;
+    if (vSty.x > 0.0) {
+      coverage = smoothstep(0.5 - vSty.x, 0.5 + vSty.x, coverage);
+    }
+    coverage = pow(coverage, vSty.y);
+    gl_FragColor  = vCol * coverage;
     gl_FragColor *= clipping.x * clipping.y;
   }`
 )
@@ -95,41 +162,46 @@ type glyphBatch struct {
 	SrcRects  []float32
 	Colors    []float32
 	ClipRects []float32
+	Styles    []float32
 	Indices   []uint16
 	GlyphPage *textureContext
 }
 
 type blitter struct {
-	stats       *contextStats
-	quad        *shape
-	copyShader  *shaderProgram
-	colorShader *shaderProgram
-	fontShader  *shaderProgram
-	glyphBatch  glyphBatch
+	stats          *contextStats
+	quad           *shape
+	copyShader     *shaderProgram
+	layerShader    *shaderProgram
+	colorShader    *shaderProgram
+	gradientShader *shaderProgram
+	fontShader     *shaderProgram
+	glyphBatch     glyphBatch
 }
 
 func newBlitter(ctx *context, stats *contextStats) *blitter {
 	return &blitter{
-		stats:       stats,
-		quad:        newQuadShape(),
-		copyShader:  newShaderProgram(ctx, vsCopySrc, fsCopySrc),
-		colorShader: newShaderProgram(ctx, vsColorSrc, fsColorSrc),
-		fontShader:  newShaderProgram(ctx, vsFontSrc, fsFontSrc),
+		stats:          stats,
+		quad:           newQuadShape(),
+		copyShader:     newShaderProgram(ctx, vsCopySrc, fsCopySrc),
+		layerShader:    newShaderProgram(ctx, vsCopySrc, fsLayerSrc),
+		colorShader:    newShaderProgram(ctx, vsColorSrc, fsColorSrc),
+		gradientShader: newShaderProgram(ctx, vsGradientSrc, fsGradientSrc),
+		fontShader:     newShaderProgram(ctx, vsFontSrc, fsFontSrc),
 	}
 }
 
 func (b *blitter) destroy(ctx *context) {
 	b.copyShader.destroy(ctx)
+	b.layerShader.destroy(ctx)
 	b.colorShader.destroy(ctx)
+	b.gradientShader.destroy(ctx)
 	b.fontShader.destroy(ctx)
 }
 
 func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.Rect, ds *drawState) {
 	b.commitGlyphs(ctx)
 
-	dstRect = dstRect.Offset(ds.OriginPixels)
 	sw, sh := tc.sizePixels.WH()
-	dw, dh := ctx.sizePixels.WH()
 
 	var mUV math.Mat3
 	if tc.flipY {
@@ -147,12 +219,7 @@ func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.R
 			float32(srcRect.Min.Y)/float32(sh), 1,
 		)
 	}
-	mPos := math.CreateMat3(
-		+2.0*float32(dstRect.W())/float32(dw), 0, 0,
-		0, -2.0*float32(dstRect.H())/float32(dh), 0,
-		-1.0+2.0*float32(dstRect.Min.X)/float32(dw),
-		+1.0-2.0*float32(dstRect.Min.Y)/float32(dh), 1,
-	)
+	mPos := ctx.rectToNDC(dstRect, ds)
 	if !tc.pma {
 		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 	}
@@ -167,9 +234,46 @@ func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.R
 	b.stats.drawCallCount++
 }
 
-func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c guix.Color, srcRect, dstRect math.Rect, ds *drawState) {
-	dstRect = dstRect.Offset(ds.OriginPixels)
+// blitLayer composites the target of the layer l, which covers the viewport,
+// with the layer's opacity and blend mode.
+func (b *blitter) blitLayer(ctx *context, l *layer) {
+	b.commitGlyphs(ctx)
+	// Flip the framebuffer's rows, which start at the bottom.
+	mUV := math.CreateMat3(
+		1, 0, 0,
+		0, -1, 0,
+		0, 1, 1,
+	)
+	mPos := math.CreateMat3(
+		+2, 0, 0,
+		0, -2, 0,
+		-1, +1, 1,
+	)
+	// The layer's colors are premultiplied and faded by the shader. Multiply
+	// assumes that what is beneath the layer is opaque.
+	switch l.mode {
+	case guix.BlendMultiply:
+		gl.BlendFunc(gl.DST_COLOR, gl.ONE_MINUS_SRC_ALPHA)
+	case guix.BlendScreen:
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_COLOR)
+	case guix.BlendAdditive:
+		gl.BlendFunc(gl.ONE, gl.ONE)
+	}
+	b.quad.draw(ctx, b.layerShader, uniformBindings{
+		"source":  l.target.texture,
+		"mUV":     mUV,
+		"mPos":    mPos,
+		"Opacity": math.Saturate(l.alpha),
+	})
+	gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+	b.stats.drawCallCount++
+}
 
+// blitGlyph draws the glyph at srcRect of the glyph page tc to the rectangle
+// from dstMin to dstMax, in pixels relative to the origin of ds. smoothing is
+// the half-width of the edge of a signed distance field glyph, or 0 for a
+// bitmap glyph, and gamma corrects the coverage of the glyph.
+func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c guix.Color, srcRect math.Rect, dstMin, dstMax math.Vec2, smoothing, gamma float32, ds *drawState) {
 	if b.glyphBatch.GlyphPage != tc {
 		b.commitGlyphs(ctx)
 		b.glyphBatch.GlyphPage = tc
@@ -181,11 +285,12 @@ func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c guix.Color, srcR
 		float32(ds.ClipPixels.Max.X),
 		float32(ds.ClipPixels.Max.Y),
 	}
-	b.glyphBatch.DstRects = append(b.glyphBatch.DstRects,
-		float32(dstRect.Min.X), float32(dstRect.Min.Y),
-		float32(dstRect.Max.X), float32(dstRect.Min.Y),
-		float32(dstRect.Min.X), float32(dstRect.Max.Y),
-		float32(dstRect.Max.X), float32(dstRect.Max.Y),
+	m := ctx.pixelsToWindow(ds)
+	b.glyphBatch.DstRects = appendVec2(b.glyphBatch.DstRects,
+		m.Transform(dstMin),
+		m.Transform(math.Vec2{X: dstMax.X, Y: dstMin.Y}),
+		m.Transform(math.Vec2{X: dstMin.X, Y: dstMax.Y}),
+		m.Transform(dstMax),
 	)
 	b.glyphBatch.SrcRects = append(b.glyphBatch.SrcRects,
 		float32(srcRect.Min.X), float32(srcRect.Min.Y),
@@ -207,6 +312,12 @@ func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c guix.Color, srcR
 		c.R, c.G, c.B, c.A,
 		c.R, c.G, c.B, c.A,
 	)
+	b.glyphBatch.Styles = append(b.glyphBatch.Styles,
+		smoothing, 1/gamma,
+		smoothing, 1/gamma,
+		smoothing, 1/gamma,
+		smoothing, 1/gamma,
+	)
 	b.glyphBatch.Indices = append(b.glyphBatch.Indices,
 		i, i+1, i+2,
 		i+2, i+1, i+3,
@@ -215,14 +326,7 @@ func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c guix.Color, srcR
 
 func (b *blitter) blitShape(ctx *context, shape shape, color guix.Color, ds *drawState) {
 	b.commitGlyphs(ctx)
-	dipsToPixels := ctx.resolution.dipsToPixels()
-	dw, dh := ctx.sizePixels.WH()
-	mPos := math.CreateMat3(
-		+2.0*dipsToPixels/float32(dw), 0, 0,
-		0, -2.0*dipsToPixels/float32(dh), 0,
-		-1.0+2.0*float32(ds.OriginPixels.X)/float32(dw),
-		+1.0-2.0*float32(ds.OriginPixels.Y)/float32(dh), 1,
-	)
+	mPos := ctx.dipsToNDC(ds)
 
 	shape.draw(ctx, b.colorShader, uniformBindings{
 		"mPos":  mPos,
@@ -241,16 +345,33 @@ func (b *blitter) blitShape(ctx *context, shape shape, color guix.Color, ds *dra
 	b.stats.drawCallCount++
 }
 
-func (b *blitter) blitRect(ctx *context, dstRect math.Rect, color guix.Color, ds *drawState) {
+// blitGradientShape draws shape filled with the gradient g. bounds is the
+// bounding rectangle of the shape in DIPs.
+func (b *blitter) blitGradientShape(ctx *context, shape shape, g *gradient, bounds math.Rect, ds *drawState) {
 	b.commitGlyphs(ctx)
-	dstRect = dstRect.Offset(ds.OriginPixels)
-	dw, dh := ctx.sizePixels.WH()
-	mPos := math.CreateMat3(
-		+2.0*float32(dstRect.W())/float32(dw), 0, 0,
-		0, -2.0*float32(dstRect.H())/float32(dh), 0,
-		-1.0+2.0*float32(dstRect.Min.X)/float32(dw),
-		+1.0-2.0*float32(dstRect.Min.Y)/float32(dh), 1,
+	mPos := ctx.dipsToNDC(ds)
+	bw, bh := float32(math.Max(bounds.W(), 1)), float32(math.Max(bounds.H(), 1))
+	mUnit := math.CreateMat3(
+		1.0/bw, 0, 0,
+		0, 1.0/bh, 0,
+		-float32(bounds.Min.X)/bw, -float32(bounds.Min.Y)/bh, 1,
 	)
+	shape.draw(ctx, b.gradientShader, g.uniforms(ctx, mPos, mUnit))
+	b.stats.drawCallCount++
+}
+
+// blitGradientRect fills dstRect, in pixels, with the gradient g.
+func (b *blitter) blitGradientRect(ctx *context, dstRect math.Rect, g *gradient, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mPos := ctx.rectToNDC(dstRect, ds)
+	// The quad's vertices are already the unit square.
+	b.quad.draw(ctx, b.gradientShader, g.uniforms(ctx, mPos, math.Mat3Ident))
+	b.stats.drawCallCount++
+}
+
+func (b *blitter) blitRect(ctx *context, dstRect math.Rect, color guix.Color, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mPos := ctx.rectToNDC(dstRect, ds)
 
 	b.quad.draw(ctx, b.colorShader, uniformBindings{
 		"mPos":  mPos,
@@ -269,23 +390,19 @@ func (b *blitter) commitGlyphs(ctx *context) {
 		return
 	}
 	sw, sh := tc.sizePixels.WH()
-	dw, dh := ctx.sizePixels.WH()
 
 	mSrc := math.CreateMat3(
 		1.0/float32(sw), 0, 0,
 		0, 1.0/float32(sh), 0,
 		0.0, 0.0, 1,
 	)
-	mDst := math.CreateMat3(
-		+2.0/float32(dw), 0, 0,
-		0, -2.0/float32(dh), 0,
-		-1.0, +1.0, 1,
-	)
+	mDst := ctx.windowToNDC()
 	vb := newVertexBuffer(
 		newVertexStream("aDst", stFloatVec2, b.glyphBatch.DstRects),
 		newVertexStream("aSrc", stFloatVec2, b.glyphBatch.SrcRects),
 		newVertexStream("aClp", stFloatVec4, b.glyphBatch.ClipRects),
 		newVertexStream("aCol", stFloatVec4, b.glyphBatch.Colors),
+		newVertexStream("aSty", stFloatVec2, b.glyphBatch.Styles),
 	)
 	ib := newIndexBuffer(ptUshort, b.glyphBatch.Indices)
 	s := newShape(vb, ib, dmTriangles)
@@ -301,6 +418,7 @@ func (b *blitter) commitGlyphs(ctx *context) {
 	b.glyphBatch.SrcRects = b.glyphBatch.SrcRects[:0]
 	b.glyphBatch.ClipRects = b.glyphBatch.ClipRects[:0]
 	b.glyphBatch.Colors = b.glyphBatch.Colors[:0]
+	b.glyphBatch.Styles = b.glyphBatch.Styles[:0]
 	b.glyphBatch.Indices = b.glyphBatch.Indices[:0]
 	b.stats.drawCallCount++
 }
@@ -30,6 +30,12 @@ type drawState struct {
 	// The below are all in window coordinates
 	ClipPixels   math.Rect
 	OriginPixels math.Point
+
+	// Transform maps local DIPs to DIPs relative to OriginPixels.
+	Transform math.Mat3
+
+	// Layer is the layer being drawn to, or nil if drawing to the viewport.
+	Layer *layer
 }
 
 type canvas struct {
@@ -94,21 +100,61 @@ func (c *canvas) Push() {
 func (c *canvas) Pop() {
 	c.buildingPushCount--
 	c.appendOp("Pop", func(ctx *context, dss *drawStateStack) {
+		l := dss.head().Layer
 		dss.pop()
-		ctx.apply(dss.head())
+		ds := dss.head()
+		ctx.apply(ds)
+		if l != ds.Layer {
+			ctx.popLayer(l, ds.Layer)
+		}
+	})
+}
+
+func (c *canvas) PushOpacity(alpha float32) {
+	c.pushLayer("PushOpacity", alpha, guix.BlendNormal)
+}
+
+func (c *canvas) PushBlendMode(mode guix.BlendMode) {
+	c.pushLayer("PushBlendMode", 1, mode)
+}
+
+func (c *canvas) pushLayer(name string, alpha float32, mode guix.BlendMode) {
+	c.buildingPushCount++
+	c.appendOp(name, func(ctx *context, dss *drawStateStack) {
+		dss.push(*dss.head())
+		if alpha < 1 || mode != guix.BlendNormal {
+			ctx.pushLayer(dss.head(), alpha, mode)
+		}
 	})
 }
 
 func (c *canvas) AddClip(r math.Rect) {
 	c.appendOp("AddClip", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		rectLocalPixels := ctx.resolution.rectDipsToPixels(r)
-		rectWindowPixels := rectLocalPixels.Offset(ds.OriginPixels)
-		ds.ClipPixels = ds.ClipPixels.Intersect(rectWindowPixels)
+		ds.ClipPixels = ds.ClipPixels.Intersect(ctx.rectToWindow(r, ds))
 		ctx.apply(ds)
 	})
 }
 
+func (c *canvas) Transform(m math.Mat3) {
+	c.appendOp("Transform", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		ds.Transform = m.Mul(ds.Transform)
+	})
+}
+
+func (c *canvas) Translate(x, y float32) {
+	c.Transform(math.CreateMat3Translate(math.Vec2{X: x, Y: y}))
+}
+
+func (c *canvas) Rotate(radians float32) {
+	c.Transform(math.CreateMat3Rotate(radians))
+}
+
+func (c *canvas) Scale(x, y float32) {
+	c.Transform(math.CreateMat3Scale(math.Vec2{X: x, Y: y}))
+}
+
 func (c *canvas) Clear(color guix.Color) {
 	c.appendOp("Clear", func(ctx *context, dss *drawStateStack) {
 		gl.ClearColor(
@@ -127,10 +173,15 @@ func (c *canvas) DrawCanvas(cc guix.Canvas, offsetDips math.Point) {
 	}
 	childCanvas := cc.(*canvas)
 	c.appendOp("DrawCanvas", func(ctx *context, dss *drawStateStack) {
-		offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
 		dss.push(*dss.head())
 		ds := dss.head()
-		ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
+		if ds.Transform == math.Mat3Ident {
+			offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
+			ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
+		} else {
+			offset := math.CreateMat3Translate(offsetDips.Vec2())
+			ds.Transform = offset.Mul(ds.Transform)
+		}
 		childCanvas.draw(ctx, dss)
 		dss.pop()
 		ctx.apply(dss.head())
@@ -141,6 +192,12 @@ func (c *canvas) DrawRunes(f guix.Font, r []rune, p []math.Point, col guix.Color
 	if f == nil {
 		panic("Font cannot be nil")
 	}
+	if family, ok := f.(*guix.FontFamily); ok {
+		for _, run := range family.Runs(r, p) {
+			c.DrawRunes(run.Font, run.Runes, run.Offsets, col)
+		}
+		return
+	}
 	runes := append([]rune{}, r...)
 	points := append([]math.Point{}, p...)
 	c.appendOp("DrawRunes", func(ctx *context, dss *drawStateStack) {
@@ -149,31 +206,59 @@ func (c *canvas) DrawRunes(f guix.Font, r []rune, p []math.Point, col guix.Color
 }
 
 func (c *canvas) DrawLines(lines guix.Polygon, pen guix.Pen) {
-	edge := openPolyToShape(lines, pen.Width)
+	edge := openPolyToShape(lines, pen)
 	c.appendOp("DrawLines", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		if edge != nil && pen.Color.A > 0 {
+		if edge != nil && pen.IsVisible() {
 			ctx.blitter.blitShape(ctx, *edge, pen.Color, ds)
 		}
 	})
 }
 
 func (c *canvas) DrawPolygon(poly guix.Polygon, pen guix.Pen, brush guix.Brush) {
-	fill, edge := closedPolyToShape(poly, pen.Width)
+	fill, edge := closedPolyToShape(poly, pen)
+	grad, bounds := newGradient(brush), poly.Bounds()
 	c.appendOp("DrawPolygon", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		if fill != nil && brush.Color.A > 0 {
-			ctx.blitter.blitShape(ctx, *fill, brush.Color, ds)
+		if fill != nil && brush.IsVisible() {
+			if grad != nil {
+				ctx.blitter.blitGradientShape(ctx, *fill, grad, bounds, ds)
+			} else {
+				ctx.blitter.blitShape(ctx, *fill, brush.Color, ds)
+			}
+		}
+		if edge != nil && pen.IsVisible() {
+			ctx.blitter.blitShape(ctx, *edge, pen.Color, ds)
+		}
+	})
+}
+
+func (c *canvas) DrawPath(path *guix.Path2D, pen guix.Pen, brush guix.Brush) {
+	fill, edge := pathToShape(path, pen)
+	grad, bounds := newGradient(brush), path.Bounds()
+	c.appendOp("DrawPath", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if fill != nil && brush.IsVisible() {
+			if grad != nil {
+				ctx.blitter.blitGradientShape(ctx, *fill, grad, bounds, ds)
+			} else {
+				ctx.blitter.blitShape(ctx, *fill, brush.Color, ds)
+			}
 		}
-		if edge != nil && pen.Color.A > 0 {
+		if edge != nil {
 			ctx.blitter.blitShape(ctx, *edge, pen.Color, ds)
 		}
 	})
 }
 
 func (c *canvas) DrawRect(r math.Rect, brush guix.Brush) {
+	grad := newGradient(brush)
 	c.appendOp("DrawRect", func(ctx *context, dss *drawStateStack) {
-		ctx.blitter.blitRect(ctx, ctx.resolution.rectDipsToPixels(r), brush.Color, dss.head())
+		if grad != nil {
+			ctx.blitter.blitGradientRect(ctx, ctx.resolution.rectDipsToPixels(r), grad, dss.head())
+		} else {
+			ctx.blitter.blitRect(ctx, ctx.resolution.rectDipsToPixels(r), brush.Color, dss.head())
+		}
 	})
 }
 
@@ -191,6 +276,24 @@ func (c *canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen guix.P
 	c.DrawPolygon(p, pen, brush)
 }
 
+func (c *canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s guix.Shadow) {
+	if !s.IsVisible() {
+		return
+	}
+	// The shadow is rendered to a texture on first use at each resolution.
+	var t *texture
+	var res resolution
+	c.appendOp("DrawShadow", func(ctx *context, dss *drawStateStack) {
+		if t == nil || res != ctx.resolution {
+			res = ctx.resolution
+			t = newTexture(s.Image(r, tl, tr, bl, br, res.dipsToPixels()), res.dipsToPixels())
+		}
+		tc := ctx.getOrCreateTextureContext(t)
+		b := ctx.resolution.rectDipsToPixels(s.Bounds(r))
+		ctx.blitter.blit(ctx, tc, tc.sizePixels.Rect(), b, dss.head())
+	})
+}
+
 func (c *canvas) DrawTexture(t guix.Texture, r math.Rect) {
 	if t == nil {
 		panic("Texture cannot be nil")
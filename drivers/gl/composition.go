@@ -0,0 +1,70 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// CompositionInput is implemented by the viewports of the gl driver, and
+// feeds the text composed with an input method editor (IME) to the viewport's
+// composition events. Pass the pre-edit text to Preedit and the committed
+// text to Commit, and place the candidate window at CompositionRect:
+//
+//	input := viewport.(gl.CompositionInput) // A viewport created by the driver
+//	input.Preedit("ni", 2)
+//	input.Commit("你")
+//
+// Text passed to Commit must not also be delivered as key strokes, or it is
+// inserted twice.
+//
+// CompositionInput methods may be called from any go-routine.
+type CompositionInput interface {
+	// Preedit sets the pre-edit text and the rune index of its cursor,
+	// starting a composition if none is in progress.
+	Preedit(text string, cursor int)
+
+	// Commit ends the composition, committing text. An empty text cancels the
+	// composition.
+	Commit(text string)
+
+	// CompositionRect returns the bounds of the pre-edit text in screen
+	// coordinates, relative to the top-left of the window's client area.
+	CompositionRect() math.Rect
+}
+
+func (v *viewport) Preedit(text string, cursor int) {
+	v.Lock()
+	started := !v.composing
+	v.composing = true
+	v.Unlock()
+	ev := guix.CompositionEvent{Text: text, Cursor: cursor}
+	if started {
+		v.onCompositionStart.Fire(ev)
+	} else {
+		v.onCompositionUpdate.Fire(ev)
+	}
+}
+
+func (v *viewport) Commit(text string) {
+	v.Lock()
+	composing := v.composing
+	v.composing = false
+	v.Unlock()
+	if !composing {
+		if text == "" {
+			return
+		}
+		v.onCompositionStart.Fire(guix.CompositionEvent{})
+	}
+	v.onCompositionEnd.Fire(guix.CompositionEvent{Text: text, Cursor: len([]rune(text))})
+}
+
+func (v *viewport) CompositionRect() math.Rect {
+	v.Lock()
+	defer v.Unlock()
+	return v.compositionRect.ScaleS(v.scaling)
+}
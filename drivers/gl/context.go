@@ -26,6 +26,8 @@ type context struct {
 	sizeDips, sizePixels math.Size
 	clip                 math.Rect
 	frame                int
+	layers               []*layer       // The open layers, innermost last
+	layerTargets         []*layerTarget // The offscreen targets of layers
 }
 
 func newContext() *context {
@@ -54,6 +56,7 @@ func (c *context) destroy() {
 		ic.destroy()
 		c.stats.indexBufferCount--
 	}
+	c.destroyLayerTargets()
 	c.blitter.destroy(c)
 	c.blitter = nil
 }
@@ -61,6 +64,9 @@ func (c *context) destroy() {
 func (c *context) beginDraw(sizeDips, sizePixels math.Size) {
 	dipsToPixels := float32(sizePixels.W) / float32(sizeDips.W)
 
+	if c.sizePixels != sizePixels {
+		c.destroyLayerTargets()
+	}
 	c.sizeDips = sizeDips
 	c.sizePixels = sizePixels
 	c.resolution = resolution(dipsToPixels*65536 + 0.5)
@@ -141,3 +147,74 @@ func (c *context) apply(ds *drawState) {
 		gl.Scissor(int32(r.Min.X), int32(vs.H)-int32(r.Max.Y), int32(rs.W), int32(rs.H))
 	}
 }
+
+// windowToNDC returns the matrix that transforms window-space pixels to
+// normalized device coordinates.
+func (c *context) windowToNDC() math.Mat3 {
+	dw, dh := c.sizePixels.WH()
+	return math.CreateMat3(
+		+2.0/float32(dw), 0, 0,
+		0, -2.0/float32(dh), 0,
+		-1.0, +1.0, 1,
+	)
+}
+
+// pixelsToWindow returns the matrix that transforms pixels, relative to the
+// origin of ds, to window-space pixels.
+func (c *context) pixelsToWindow(ds *drawState) math.Mat3 {
+	o := math.CreateMat3Translate(ds.OriginPixels.Vec2())
+	if ds.Transform == math.Mat3Ident {
+		return o
+	}
+	s := c.resolution.dipsToPixels()
+	return math.CreateMat3Scale(math.Vec2{X: 1 / s, Y: 1 / s}).
+		Mul(ds.Transform).
+		Mul(math.CreateMat3Scale(math.Vec2{X: s, Y: s})).
+		Mul(o)
+}
+
+// dipsToNDC returns the matrix that transforms local-space DIPs to normalized
+// device coordinates.
+func (c *context) dipsToNDC(ds *drawState) math.Mat3 {
+	s := c.resolution.dipsToPixels()
+	return ds.Transform.
+		Mul(math.CreateMat3Scale(math.Vec2{X: s, Y: s})).
+		Mul(math.CreateMat3Translate(ds.OriginPixels.Vec2())).
+		Mul(c.windowToNDC())
+}
+
+// rectToNDC returns the matrix that transforms the unit square to the
+// rectangle r, in pixels relative to the origin of ds, in normalized device
+// coordinates.
+func (c *context) rectToNDC(r math.Rect, ds *drawState) math.Mat3 {
+	unitToRect := math.CreateMat3(
+		float32(r.W()), 0, 0,
+		0, float32(r.H()), 0,
+		float32(r.Min.X), float32(r.Min.Y), 1,
+	)
+	return unitToRect.Mul(c.pixelsToWindow(ds)).Mul(c.windowToNDC())
+}
+
+// rectToWindow returns the window-space pixel rectangle covering the
+// local-space rectangle r, in DIPs.
+func (c *context) rectToWindow(r math.Rect, ds *drawState) math.Rect {
+	rp := c.resolution.rectDipsToPixels(r)
+	if ds.Transform == math.Mat3Ident {
+		return rp.Offset(ds.OriginPixels)
+	}
+	m := c.pixelsToWindow(ds)
+	var min, max math.Vec2
+	for i, p := range []math.Point{rp.TL(), rp.TR(), rp.BR(), rp.BL()} {
+		p := m.Transform(p.Vec2())
+		if i == 0 {
+			min, max = p, p
+			continue
+		}
+		min = math.Vec2{X: math.Minf(min.X, p.X), Y: math.Minf(min.Y, p.Y)}
+		max = math.Vec2{X: math.Maxf(max.X, p.X), Y: math.Maxf(max.Y, p.Y)}
+	}
+	return math.Rect{
+		Min: math.Point{X: int(math.Floorf(min.X)), Y: int(math.Floorf(min.Y))},
+		Max: math.Point{X: int(math.Ceilf(max.X)), Y: int(math.Ceilf(max.Y))},
+	}
+}
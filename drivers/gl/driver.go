@@ -3,6 +3,14 @@
 // license that can be found in the LICENSE file.
 
 // Package gl contains an OpenGL implementation of the guix.Driver interface.
+//
+// The driver has no input method editor (IME) integration. GLFW delivers the
+// text committed by an input method as key strokes, but never reports the
+// pre-edit text, so the viewports fire no composition events of their own
+// and no candidate window is positioned. To support composed input, an
+// application must receive the pre-edit and committed text from the
+// platform's input method, pass them to the viewport's CompositionInput
+// methods, and place the candidate window at its CompositionRect.
 package gl
 
 import (
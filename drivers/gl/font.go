@@ -15,6 +15,11 @@ import (
 	"golang.org/x/image/math/fixed"
 )
 
+// fontMaxResolutions is the most resolutions that a font holds bitmap glyph
+// tables for. The table of the least recently used resolution is dropped to
+// make room for another.
+const fontMaxResolutions = 2
+
 type font struct {
 	size             int
 	scale            fixed.Int26_6
@@ -22,6 +27,10 @@ type font struct {
 	ascentDips       int
 	ttf              *truetype.Font
 	resolutions      map[resolution]*glyphTable
+	sdf              *glyphTable
+	clock            int // Incremented on each use of a bitmap glyph table
+	glyphMode        guix.GlyphMode
+	gamma            float32
 	glyphAdvanceDips map[rune]int
 }
 
@@ -42,6 +51,7 @@ func newFont(data []byte, size int) (*font, error) {
 		ascentDips:       ascentDips,
 		ttf:              ttf,
 		resolutions:      make(map[resolution]*glyphTable),
+		gamma:            guix.DefaultCoverageGamma,
 		glyphAdvanceDips: make(map[rune]int),
 	}, nil
 }
@@ -63,8 +73,20 @@ func (f *font) advanceDips(r rune) int {
 }
 
 func (f *font) glyphTable(resolution resolution) *glyphTable {
+	f.clock++
 	t, found := f.resolutions[resolution]
 	if !found {
+		if len(f.resolutions) >= fontMaxResolutions {
+			oldest := f.clock
+			for _, t := range f.resolutions {
+				oldest = math.Min(oldest, t.lastUse)
+			}
+			for r, t := range f.resolutions {
+				if t.lastUse == oldest {
+					delete(f.resolutions, r)
+				}
+			}
+		}
 		opt := truetype.Options{
 			Size:              float64(f.size),
 			DPI:               float64(resolution.intDipsToPixels(72)),
@@ -73,12 +95,30 @@ func (f *font) glyphTable(resolution resolution) *glyphTable {
 			SubPixelsX:        1,
 			SubPixelsY:        1,
 		}
-		t = newGlyphTable(truetype.NewFace(f.ttf, &opt))
+		t = newGlyphTable(truetype.NewFace(f.ttf, &opt), 0)
 		f.resolutions[resolution] = t
 	}
+	t.lastUse = f.clock
 	return t
 }
 
+// sdfTable returns the table of signed distance field glyphs, which is
+// shared by all resolutions.
+func (f *font) sdfTable() *glyphTable {
+	if f.sdf == nil {
+		opt := truetype.Options{
+			Size:              sdfGlyphSize,
+			DPI:               72,
+			Hinting:           fnt.HintingNone,
+			GlyphCacheEntries: 1,
+			SubPixelsX:        1,
+			SubPixelsY:        1,
+		}
+		f.sdf = newGlyphTable(truetype.NewFace(f.ttf, &opt), sdfSpread)
+	}
+	return f.sdf
+}
+
 func (f *font) align(rect math.Rect, size math.Size, ascent int, h guix.HorizontalAlignment, v guix.VerticalAlignment) math.Point {
 	var origin math.Point
 	switch h {
@@ -106,62 +146,92 @@ func (f *font) DrawRunes(ctx *context, runes []rune, offsets []math.Point, col g
 			len(runes), len(offsets)))
 	}
 	resolution := ctx.resolution
-	table := f.glyphTable(resolution)
+	// Glyphs are drawn at scale pixels per pixel of the glyph table.
+	// Bitmap glyphs are rasterized at the resolution, and signed distance
+	// field glyphs are smoothed over about a pixel.
+	var table *glyphTable
+	scale, smoothing := float32(1), float32(0)
+	if f.glyphMode == guix.GlyphSDF {
+		table = f.sdfTable()
+		scale = resolution.dipsToPixels() * float32(f.size) / sdfGlyphSize
+		smoothing = math.Minf(1/(4*sdfSpread*scale), 0.5)
+	} else {
+		table = f.glyphTable(resolution)
+	}
 
-	for i, r := range runes {
-		if unicode.IsSpace(r) {
+	for i, r := range guix.ShapeGlyphs(runes, f) {
+		if r == 0 || unicode.IsSpace(r) {
 			continue
 		}
 		page := table.get(r)
 		texture := page.texture()
 		entry := page.get(r)
 		srcRect := entry.bounds.Offset(entry.offset)
-		dstRect := entry.bounds.Offset(resolution.pointDipsToPixels(offsets[i]))
+		origin := resolution.pointDipsToPixels(offsets[i]).Vec2()
+		dstMin := origin.Add(entry.bounds.Min.Vec2().MulS(scale))
+		dstMax := origin.Add(entry.bounds.Max.Vec2().MulS(scale))
 		tc := ctx.getOrCreateTextureContext(texture)
-		ctx.blitter.blitGlyph(ctx, tc, col, srcRect, dstRect, ds)
+		ctx.blitter.blitGlyph(ctx, tc, col, srcRect, dstMin, dstMax, smoothing, f.gamma, ds)
 	}
 }
 
+// guix.FontRenderer compliance
+func (f *font) GlyphMode() guix.GlyphMode {
+	return f.glyphMode
+}
+
+func (f *font) SetGlyphMode(mode guix.GlyphMode) {
+	f.glyphMode = mode
+}
+
+func (f *font) CoverageGamma() float32 {
+	return f.gamma
+}
+
+func (f *font) SetCoverageGamma(gamma float32) {
+	if gamma <= 0 {
+		panic("Coverage gamma must be greater than 0")
+	}
+	f.gamma = gamma
+}
+
 func (f *font) Size() int {
 	return f.size
 }
 
+func (f *font) HasGlyph(r rune) bool {
+	return f.ttf.Index(r) != 0
+}
+
+func (f *font) Advance(r rune) int {
+	return f.advanceDips(r)
+}
+
+func (f *font) Kern(a, b rune) int {
+	k := f.ttf.Kern(f.scale, f.ttf.Index(a), f.ttf.Index(b))
+	return int((k + 0x20) >> 6)
+}
+
 func (f *font) Measure(fl *guix.TextBlock) math.Size {
-	size := math.Size{W: 0, H: f.glyphMaxSizeDips.H}
-	var offset math.Point
-	for _, r := range fl.Runes {
-		if r == '\n' {
-			offset.X = 0
-			offset.Y += f.glyphMaxSizeDips.H
-			continue
-		}
-		offset.X += f.advanceDips(r)
-		size = size.Max(math.Size{W: offset.X, H: offset.Y + f.glyphMaxSizeDips.H})
-	}
-	return size
+	return guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f).Size
 }
 
 func (f *font) Layout(fl *guix.TextBlock) (offsets []math.Point) {
-	sizeDips := math.Size{}
-	offsets = make([]math.Point, len(fl.Runes))
-	var offset math.Point
-	for i, r := range fl.Runes {
-		if r == '\n' {
-			offset.X = 0
-			offset.Y += f.glyphMaxSizeDips.H
-			continue
-		}
-
-		offsets[i] = offset
-		offset.X += f.advanceDips(r)
-		sizeDips = sizeDips.Max(math.Size{W: offset.X, H: offset.Y + f.glyphMaxSizeDips.H})
+	t := guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f)
+	origin := f.align(fl.AlignRect, t.Size, f.ascentDips, fl.H, fl.V)
+	for i, p := range t.Offsets {
+		t.Offsets[i] = p.Add(origin)
 	}
+	return t.Offsets
+}
 
-	origin := f.align(fl.AlignRect, sizeDips, f.ascentDips, fl.H, fl.V)
-	for i, p := range offsets {
-		offsets[i] = p.Add(origin)
+func (f *font) Carets(fl *guix.TextBlock) []math.Point {
+	t := guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f)
+	origin := f.align(fl.AlignRect, t.Size, f.ascentDips, fl.H, fl.V)
+	for i, p := range t.Carets {
+		t.Carets[i] = p.Add(origin)
 	}
-	return offsets
+	return t.Carets
 }
 
 func (f *font) LoadGlyphs(first, last rune) {
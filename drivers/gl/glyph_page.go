@@ -35,6 +35,7 @@ type glyphPage struct {
 	rowHeight int
 	tex       *texture
 	nextPoint math.Point
+	lastUse   int // The glyph table clock when the page was last used
 }
 
 func point26_6toPoint(p fixed.Point26_6) math.Point {
@@ -49,10 +50,13 @@ func align(v, pot int) int {
 	return (v + pot - 1) & ^(pot - 1)
 }
 
-func newGlyphPage(face fnt.Face, r rune) *glyphPage {
+// newGlyphPage returns a new page holding the glyph of face for r. If spread
+// is greater than 0, the page holds signed distance fields padded by spread
+// pixels instead of coverage masks.
+func newGlyphPage(face fnt.Face, r rune, spread int) *glyphPage {
 	// Start the page big enough to hold the initial rune.
 	b, _, _ := face.GlyphBounds(r)
-	bounds := rectangle26_6toRect(b)
+	bounds := rectangle26_6toRect(b).ExpandI(spread)
 	size := math.Size{W: glyphPageWidth, H: glyphPageHeight}.Max(bounds.Size())
 	size.W = align(size.W, glyphSizeAlignment)
 	size.H = align(size.H, glyphSizeAlignment)
@@ -63,7 +67,7 @@ func newGlyphPage(face fnt.Face, r rune) *glyphPage {
 		entries:   make(map[rune]glyphEntry),
 		rowHeight: 0,
 	}
-	page.add(face, r)
+	page.add(face, r, spread)
 	return page
 }
 
@@ -79,13 +83,18 @@ func (p *glyphPage) commit() {
 	}
 }
 
-func (p *glyphPage) add(face fnt.Face, r rune) bool {
+func (p *glyphPage) add(face fnt.Face, r rune, spread int) bool {
 	if _, found := p.entries[r]; found {
 		panic("Glyph already added to glyph page")
 	}
 
 	b, mask, maskp, _, _ := face.Glyph(fixed.Point26_6{}, r)
 	bounds := math.CreateRect(b.Min.X, b.Min.Y, b.Max.X, b.Max.Y)
+	if spread > 0 {
+		mask = signedDistanceField(mask, image.Rectangle{Min: maskp, Max: maskp.Add(b.Size())}, spread)
+		maskp = image.Point{}
+		bounds = bounds.ExpandI(spread)
+	}
 
 	w, h := bounds.Size().WH()
 	x, y := p.nextPoint.X, p.nextPoint.Y
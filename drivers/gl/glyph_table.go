@@ -6,30 +6,64 @@ package gl
 
 import fnt "golang.org/x/image/font"
 
+// glyphTableMaxPages is the most pages a glyph table holds. The least
+// recently used page is evicted to make room for a new page, so the memory
+// used by a table stays bounded however many runes are drawn.
+const glyphTableMaxPages = 8
+
 type glyphTable struct {
-	face  fnt.Face
-	index map[rune]int
-	pages []*glyphPage
+	face     fnt.Face
+	spread   int // The spread of signed distance field glyphs, or 0
+	maxPages int
+	index    map[rune]*glyphPage
+	pages    []*glyphPage
+	clock    int // Incremented on each use of the table
+	lastUse  int // The font clock when the table was last used
 }
 
-func newGlyphTable(face fnt.Face) *glyphTable {
-	return &glyphTable{face: face, index: make(map[rune]int)}
+func newGlyphTable(face fnt.Face, spread int) *glyphTable {
+	return &glyphTable{
+		face:     face,
+		spread:   spread,
+		maxPages: glyphTableMaxPages,
+		index:    make(map[rune]*glyphPage),
+	}
 }
 
 func (t *glyphTable) get(r rune) *glyphPage {
-	if i, found := t.index[r]; found {
-		return t.pages[i]
+	t.clock++
+	page, found := t.index[r]
+	if !found {
+		page = t.add(r)
+	}
+	page.lastUse = t.clock
+	return page
+}
+
+func (t *glyphTable) add(r rune) *glyphPage {
+	if n := len(t.pages); n > 0 && t.pages[n-1].add(t.face, r, t.spread) {
+		t.index[r] = t.pages[n-1]
+		return t.pages[n-1]
 	}
-	if len(t.pages) == 0 {
-		t.pages = append(t.pages, newGlyphPage(t.face, r))
-	} else {
-		page := t.pages[len(t.pages)-1]
-		if !page.add(t.face, r) {
-			page = newGlyphPage(t.face, r)
-			t.pages = append(t.pages, page)
+	if len(t.pages) >= t.maxPages {
+		t.evict()
+	}
+	page := newGlyphPage(t.face, r, t.spread)
+	t.pages = append(t.pages, page)
+	t.index[r] = page
+	return page
+}
+
+// evict removes the least recently used page, and the runes it holds.
+func (t *glyphTable) evict() {
+	lru := 0
+	for i, p := range t.pages {
+		if p.lastUse < t.pages[lru].lastUse {
+			lru = i
 		}
 	}
-	index := len(t.pages) - 1
-	t.index[r] = index
-	return t.pages[index]
+	for r := range t.pages[lru].entries {
+		delete(t.index, r)
+	}
+	t.pages = append(t.pages[:lru], t.pages[lru+1:]...)
 }
@@ -0,0 +1,59 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/gxfont"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestGlyphTableEvict(t *testing.T) {
+	f, err := newFont(gxfont.Default, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := f.glyphTable(resolution(1 << 16))
+	table.maxPages = 2
+	first := table.get('A')
+	for r := 'B'; r <= 'z'; r++ {
+		table.get(r)
+		// Keep the first page in use.
+		test.AssertEquals(t, true, first == table.get('A'))
+	}
+	test.AssertEquals(t, 2, len(table.pages))
+
+	// Evicted runes are added again, to a new page.
+	evicted := rune(0)
+	for r := 'B'; r <= 'z' && evicted == 0; r++ {
+		if _, found := table.index[r]; !found {
+			evicted = r
+		}
+	}
+	test.AssertEquals(t, true, evicted != 0)
+	page := table.get(evicted)
+	test.AssertEquals(t, 2, len(table.pages))
+	test.AssertEquals(t, true, table.pages[len(table.pages)-1] == page)
+	test.AssertEquals(t, true, page.get(evicted).bounds.W() > 0)
+}
+
+func TestFontMaxResolutions(t *testing.T) {
+	f, err := newFont(gxfont.Default, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := f.glyphTable(resolution(1 << 16))
+	f.glyphTable(resolution(2 << 16))
+	f.glyphTable(resolution(1 << 16))
+	f.glyphTable(resolution(3 << 16))
+	test.AssertEquals(t, fontMaxResolutions, len(f.resolutions))
+	test.AssertEquals(t, true, a == f.resolutions[resolution(1<<16)])
+	_, found := f.resolutions[resolution(2<<16)]
+	test.AssertEquals(t, false, found)
+
+	test.AssertEquals(t, true, f.sdfTable() == f.sdfTable())
+	test.AssertEquals(t, sdfSpread, f.sdfTable().spread)
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Number of texels in the color ramp of a gradient.
+const gradientRampSize = 256
+
+// gradient holds a guix.Gradient and the texture of its colors, sampled from
+// offset 0 to 1. The repeat mode is applied by the gradient shader.
+type gradient struct {
+	gradient *guix.Gradient
+	ramp     *texture
+}
+
+// newGradient returns the gradient of the brush b, or nil if b is a solid
+// color.
+func newGradient(b guix.Brush) *gradient {
+	g := b.Gradient
+	if g == nil {
+		return nil
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, gradientRampSize, 1))
+	for i := 0; i < gradientRampSize; i++ {
+		// Sample at the texel centers, so that the last texel is not wrapped
+		// back to the start of a repeating gradient.
+		c := g.ColorAt((float32(i) + 0.5) / gradientRampSize).Saturate()
+		img.SetNRGBA(i, 0, color.NRGBA{
+			R: uint8(c.R*255 + 0.5),
+			G: uint8(c.G*255 + 0.5),
+			B: uint8(c.B*255 + 0.5),
+			A: uint8(c.A*255 + 0.5),
+		})
+	}
+	return &gradient{
+		gradient: g,
+		ramp:     newTexture(img, 1),
+	}
+}
+
+// uniforms returns the uniform bindings of the gradient shader, where mUnit
+// transforms the vertex positions to the unit square of the shape's bounds.
+func (g *gradient) uniforms(ctx *context, mPos, mUnit math.Mat3) uniformBindings {
+	var radial float32
+	if g.gradient.Type == guix.RadialGradient {
+		radial = 1
+	}
+	return uniformBindings{
+		"mPos":   mPos,
+		"mUnit":  mUnit,
+		"Ramp":   ctx.getOrCreateTextureContext(g.ramp),
+		"Start":  g.gradient.Start,
+		"End":    g.gradient.End,
+		"Radius": g.gradient.Radius,
+		"Radial": radial,
+		"Repeat": float32(g.gradient.Repeat),
+	}
+}
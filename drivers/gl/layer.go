@@ -0,0 +1,103 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"github.com/goxjs/gl"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// layer is the offscreen target of the draw calls between a PushOpacity or
+// PushBlendMode and its Pop. The layer is composited when it is popped.
+type layer struct {
+	target *layerTarget
+	alpha  float32
+	mode   guix.BlendMode
+}
+
+// layerTarget is a framebuffer, sized as the viewport, with a texture color
+// attachment. Targets are reused between frames, one for each level of
+// nested layers.
+type layerTarget struct {
+	framebuffer gl.Framebuffer
+	texture     *textureContext
+}
+
+func newLayerTarget(sizePixels math.Size) *layerTarget {
+	texture := gl.CreateTexture()
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, sizePixels.W, sizePixels.H, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, gl.Texture{})
+
+	framebuffer := gl.CreateFramebuffer()
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+	checkError()
+
+	globalStats.textureContextCount.inc()
+	return &layerTarget{
+		framebuffer: framebuffer,
+		texture: &textureContext{
+			texture:    texture,
+			sizePixels: sizePixels,
+			flipY:      true, // Framebuffers have their first row at the bottom
+			pma:        true,
+		},
+	}
+}
+
+func (t *layerTarget) destroy() {
+	gl.DeleteFramebuffer(t.framebuffer)
+	t.texture.destroy()
+}
+
+// bind makes l the target of draw calls, or the viewport if l is nil.
+func (l *layer) bind() {
+	if l == nil {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, gl.Framebuffer{})
+	} else {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, l.target.framebuffer)
+	}
+}
+
+// pushLayer redirects draw calls to a cleared offscreen target, recording the
+// layer in ds.
+func (c *context) pushLayer(ds *drawState, alpha float32, mode guix.BlendMode) {
+	c.blitter.commit(c)
+	depth := len(c.layers)
+	if depth == len(c.layerTargets) {
+		c.layerTargets = append(c.layerTargets, newLayerTarget(c.sizePixels))
+	}
+	l := &layer{target: c.layerTargets[depth], alpha: alpha, mode: mode}
+	c.layers = append(c.layers, l)
+	l.bind()
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.Enable(gl.SCISSOR_TEST)
+	ds.Layer = l
+}
+
+// popLayer composites the layer l onto the target it replaced, parent, which
+// is nil for the viewport.
+func (c *context) popLayer(l, parent *layer) {
+	c.blitter.commit(c)
+	c.layers = c.layers[:len(c.layers)-1]
+	parent.bind()
+	c.blitter.blitLayer(c, l)
+}
+
+// destroyLayerTargets releases the offscreen targets of layers.
+func (c *context) destroyLayerTargets() {
+	for _, t := range c.layerTargets {
+		t.destroy()
+	}
+	c.layerTargets = nil
+}
@@ -141,7 +141,7 @@ func segment(penWidth, r float32, a, b, c math.Vec2, aIsLast bool, vsEdgePos []f
 	return vsEdgePos, fillEdge
 }
 
-func closedPolyToShape(p guix.Polygon, penWidth float32) (fillShape, edgeShape *shape) {
+func closedPolyToShape(p guix.Polygon, pen guix.Pen) (fillShape, edgeShape *shape) {
 	p = pruneDuplicates(p)
 
 	fillEdge := []math.Vec2{}
@@ -152,7 +152,7 @@ func closedPolyToShape(p guix.Polygon, penWidth float32) (fillShape, edgeShape *
 		a := p[i].Position.Vec2()
 		b := p[(i+cnt-1)%cnt].Position.Vec2()
 		c := p[(i+1)%cnt].Position.Vec2()
-		vsEdgePos, fillEdge = segment(penWidth, r, a, b, c, i == len(p), vsEdgePos, fillEdge)
+		vsEdgePos, fillEdge = segment(pen.Width, r, a, b, c, i == len(p), vsEdgePos, fillEdge)
 	}
 
 	// Close the edge
@@ -172,7 +172,12 @@ func closedPolyToShape(p guix.Polygon, penWidth float32) (fillShape, edgeShape *
 		), nil, dmTriangles)
 	}
 
-	if len(vsEdgePos) > 0 {
+	if pen.Dash != nil && len(p) >= 3 {
+		// Dashes are stroked along a path inset by half the pen width, so
+		// that they lie inside the polygon like the solid edge.
+		inset := insetPoly(flattenPoly(p, true), pen.Width/2)
+		edgeShape = strokeToShape(pen.Dashes(inset, true), pen)
+	} else if len(vsEdgePos) > 0 {
 		edgeShape = newShape(newVertexBuffer(
 			newVertexStream("aPosition", stFloatVec2, vsEdgePos),
 		), nil, dmTriangleStrip)
@@ -181,12 +186,23 @@ func closedPolyToShape(p guix.Polygon, penWidth float32) (fillShape, edgeShape *
 	return fillShape, edgeShape
 }
 
-func openPolyToShape(p guix.Polygon, penWidth float32) *shape {
+// openPolyToShape returns the shape of the polyline p stroked with the pen.
+// Solid pens with butt caps and miter joins draw the stroke to one side of the
+// line, as they always have. Other pens draw strokes centered on the line.
+func openPolyToShape(p guix.Polygon, pen guix.Pen) *shape {
 	p = pruneDuplicates(p)
 	if len(p) < 2 {
 		return nil
 	}
+	if pen.Dash == nil && pen.Cap == guix.ButtCap && pen.Join == guix.MiterJoin {
+		return openPolyToEdgeShape(p, pen.Width)
+	}
+	return strokeToShape(pen.Dashes(flattenPoly(p, false), false), pen)
+}
 
+// openPolyToEdgeShape returns the shape of the polyline p stroked on one side
+// with a width of penWidth.
+func openPolyToEdgeShape(p guix.Polygon, penWidth float32) *shape {
 	vsEdgePos := []float32{}
 
 	{ // p[0] -> p[1]
@@ -215,3 +231,193 @@ func openPolyToShape(p guix.Polygon, penWidth float32) *shape {
 	}
 	return nil
 }
+
+// pathTolerance is the maximum distance in DIPs between a path's curves and
+// the lines they are flattened to.
+const pathTolerance = 0.1
+
+// pathToShape returns the shapes of the path's fill and of its outline
+// stroked with the pen, centered on the outline.
+func pathToShape(path *guix.Path2D, pen guix.Pen) (fillShape, edgeShape *shape) {
+	contours := path.Flatten(pathTolerance)
+	points := make([][]math.Vec2, len(contours))
+	dashes := [][]math.Vec2{}
+	for i, c := range contours {
+		points[i] = c.Points
+		dashes = append(dashes, pen.Dashes(c.Points, c.Closed)...)
+	}
+	if tris := triangulatePath(points, path.FillRule == guix.EvenOdd); len(tris) > 0 {
+		fillShape = newShape(newVertexBuffer(
+			newVertexStream("aPosition", stFloatVec2, appendVec2(nil, tris...)),
+		), nil, dmTriangles)
+	}
+	if pen.IsVisible() {
+		edgeShape = strokeToShape(dashes, pen)
+	}
+	return fillShape, edgeShape
+}
+
+// flattenPoly returns the points of the polygon p with its rounded vertices
+// approximated by line segments. If closed is false then p is treated as a
+// polyline and its end points are not rounded.
+func flattenPoly(p guix.Polygon, closed bool) []math.Vec2 {
+	points := make([]math.Vec2, 0, len(p))
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		if !closed && (i == 0 || i == cnt-1) {
+			points = append(points, a)
+			continue
+		}
+		ab, ac := p.Corner(i)
+		points = append(points, ab)
+		if ab != ac {
+			// Approximate the quadratic curve ab, a, ac.
+			steps := 2 + int(ab.Sub(a).Len()+ac.Sub(a).Len())/4
+			for j := 1; j <= steps; j++ {
+				t := float32(j) / float32(steps)
+				u := 1 - t
+				points = append(points, ab.MulS(u*u).Add(a.MulS(2*u*t)).Add(ac.MulS(t*t)))
+			}
+		}
+	}
+	return points
+}
+
+// insetPoly returns the closed polyline p with each edge moved inwards by d.
+func insetPoly(p []math.Vec2, d float32) []math.Vec2 {
+	cnt := len(p)
+	area := float32(0)
+	for i := range p {
+		area += p[i].Cross(p[(i+1)%cnt])
+	}
+	if area < 0 {
+		d = -d
+	}
+	inset := make([]math.Vec2, cnt)
+	for i := range p {
+		prev, next := p[(i+cnt-1)%cnt], p[(i+1)%cnt]
+		m0 := p[i].Sub(prev).Normalize().Tangent()
+		m1 := next.Sub(p[i]).Normalize().Tangent()
+		// Move along the bisector of the edge normals, far enough for both
+		// edges to move by d. Sharp vertices are limited to 4 • d.
+		m := m0.Add(m1)
+		l := math.Maxf(m.SqrLen(), 0.25)
+		inset[i] = p[i].Add(m.MulS(2 * d / l))
+	}
+	return inset
+}
+
+// strokeToShape returns a shape of the polylines stroked with the pen, centered
+// on the lines, or nil if there is nothing to draw.
+func strokeToShape(lines [][]math.Vec2, pen guix.Pen) *shape {
+	tris := []float32{}
+	for _, l := range lines {
+		tris = stroke(tris, l, pen)
+	}
+	if len(tris) == 0 {
+		return nil
+	}
+	return newShape(newVertexBuffer(
+		newVertexStream("aPosition", stFloatVec2, tris),
+	), nil, dmTriangles)
+}
+
+// stroke appends the triangles of the polyline points stroked with the pen to
+// tris.
+func stroke(tris []float32, points []math.Vec2, pen guix.Pen) []float32 {
+	hw := pen.Width / 2
+	last := len(points) - 1
+	var prevDir math.Vec2
+	for i := 0; i < last; i++ {
+		a, b := points[i], points[i+1]
+		if a.Sub(b).SqrLen() < 0.000001 {
+			continue
+		}
+		d := b.Sub(a).Normalize()
+		n := d.Tangent().MulS(hw)
+		if i == 0 {
+			tris, a = strokeCap(tris, pen, a, d.Neg(), n)
+		} else if prevDir != (math.Vec2{}) {
+			tris = strokeJoin(tris, pen, a, prevDir, d)
+		}
+		if i == last-1 {
+			tris, b = strokeCap(tris, pen, b, d, n)
+		}
+		tris = appendVec2(tris,
+			a.Add(n), a.Sub(n), b.Add(n),
+			b.Add(n), a.Sub(n), b.Sub(n),
+		)
+		prevDir = d
+	}
+	return tris
+}
+
+// strokeCap appends the triangles of the cap of the pen at the end point p of
+// a line to tris. e is the direction pointing out of the line and n is the
+// line's normal, with the length of half the pen width. strokeCap returns the
+// point that the line should be extended to.
+func strokeCap(tris []float32, pen guix.Pen, p, e, n math.Vec2) ([]float32, math.Vec2) {
+	switch pen.Cap {
+	case guix.RoundCap:
+		angle := float32(math.Pi)
+		if n.Cross(e) < 0 {
+			angle = -angle
+		}
+		return arc(tris, p, n, angle), p
+	case guix.SquareCap:
+		return tris, p.Add(e.MulS(pen.Width / 2))
+	default:
+		return tris, p
+	}
+}
+
+// strokeJoin appends the triangles of the join of the pen at the point p
+// between lines of the directions d0 and d1 to tris.
+func strokeJoin(tris []float32, pen guix.Pen, p, d0, d1 math.Vec2) []float32 {
+	cross := d0.Cross(d1)
+	if math.Absf(cross) < 0.0001 && d0.Dot(d1) > 0 {
+		return tris // Straight
+	}
+	hw := pen.Width / 2
+	// The outside of the join is away from the direction of the turn.
+	side := hw
+	if cross > 0 {
+		side = -hw
+	}
+	n0, n1 := d0.Tangent().MulS(side), d1.Tangent().MulS(side)
+	switch pen.Join {
+	case guix.RoundJoin:
+		angle := math.Acosf(math.Clampf(n0.Dot(n1)/(hw*hw), -1, 1))
+		if n0.Cross(n1) < 0 {
+			angle = -angle
+		}
+		return arc(tris, p, n0, angle)
+	case guix.MiterJoin:
+		// The miter extends from p along the sum of the normals, by the half
+		// width divided by the cosine of half the angle between them.
+		m := n0.Add(n1)
+		if l := m.Len(); l > 0 && 2*hw/l <= pen.EffectiveMiterLimit() {
+			m = p.Add(m.MulS(2 * hw * hw / (l * l)))
+			return appendVec2(tris,
+				p, p.Add(n0), m,
+				p, m, p.Add(n1),
+			)
+		}
+	}
+	return appendVec2(tris, p, p.Add(n0), p.Add(n1)) // Bevel
+}
+
+// arc appends the triangles of a fan around center, sweeping the vector from
+// by angle radians, to tris.
+func arc(tris []float32, center, from math.Vec2, angle float32) []float32 {
+	steps := 1 + int(math.Absf(angle)*math.Maxf(from.Len(), 1))
+	prev := center.Add(from)
+	for i := 1; i <= steps; i++ {
+		θ := angle * float32(i) / float32(steps)
+		s, c := math.Sinf(θ), math.Cosf(θ)
+		next := center.Add(math.Vec2{X: from.X*c - from.Y*s, Y: from.X*s + from.Y*c})
+		tris = appendVec2(tris, center, prev, next)
+		prev = next
+	}
+	return tris
+}
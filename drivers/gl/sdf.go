@@ -0,0 +1,80 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"image"
+
+	"github.com/vcaesar/guix/math"
+)
+
+const (
+	// sdfGlyphSize is the size of the em square, in pixels, that signed
+	// distance field glyphs are rasterized at.
+	sdfGlyphSize = 48
+
+	// sdfSpread is the distance, in pixels of the field, from the edge of a
+	// glyph at which the field saturates. Fields are padded by the spread on
+	// each side.
+	sdfSpread = 6
+)
+
+// signedDistanceField returns the signed distance field of the region r of
+// the coverage mask, padded by spread pixels on each side. Each pixel of the
+// field holds the distance from its center to the nearest edge of the mask,
+// mapped so that 0.5 lies on the edge, 1 at spread pixels inside and 0 at
+// spread pixels outside.
+func signedDistanceField(mask image.Image, r image.Rectangle, spread int) *image.Alpha {
+	w, h := r.Dx(), r.Dy()
+	coverage := make([]float32, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			_, _, _, a := mask.At(r.Min.X+x, r.Min.Y+y).RGBA()
+			coverage[y*w+x] = float32(a) / 0xffff
+		}
+	}
+	at := func(x, y int) float32 {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return 0
+		}
+		return coverage[y*w+x]
+	}
+
+	fw, fh := w+spread*2, h+spread*2
+	field := image.NewAlpha(image.Rect(0, 0, fw, fh))
+	for fy := 0; fy < fh; fy++ {
+		for fx := 0; fx < fw; fx++ {
+			x, y := fx-spread, fy-spread
+			inside := at(x, y) >= 0.5
+			// Search the neighbourhood for the nearest pixel on the other
+			// side of the edge. The coverage of that pixel estimates where
+			// the edge crosses it.
+			dist := float32(spread)
+			for dy := -spread; dy <= spread; dy++ {
+				for dx := -spread; dx <= spread; dx++ {
+					c := at(x+dx, y+dy)
+					if (c >= 0.5) == inside {
+						continue
+					}
+					d := math.Vec2{X: float32(dx), Y: float32(dy)}.Len()
+					if inside {
+						d += c - 0.5
+					} else {
+						d += 0.5 - c
+					}
+					if d < dist {
+						dist = d
+					}
+				}
+			}
+			if !inside {
+				dist = -dist
+			}
+			v := math.Clampf(0.5+dist/float32(spread*2), 0, 1)
+			field.Pix[fy*field.Stride+fx] = uint8(v*255 + 0.5)
+		}
+	}
+	return field
+}
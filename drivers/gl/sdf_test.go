@@ -0,0 +1,70 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"image"
+	"testing"
+
+	"github.com/vcaesar/guix/gxfont"
+	test "github.com/vcaesar/guix/testing"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestSignedDistanceField(t *testing.T) {
+	// A 4x4 mask, fully covered in the 2x2 square at its center.
+	mask := image.NewAlpha(image.Rect(0, 0, 4, 4))
+	for y := 1; y < 3; y++ {
+		for x := 1; x < 3; x++ {
+			mask.Pix[y*mask.Stride+x] = 0xff
+		}
+	}
+	field := signedDistanceField(mask, mask.Rect, 2)
+	test.AssertEquals(t, image.Rect(0, 0, 8, 8), field.Rect)
+
+	at := func(x, y int) uint8 { return field.AlphaAt(x, y).A }
+	// Pixels half a pixel inside and outside of the edge.
+	test.AssertEquals(t, uint8(159), at(3, 3))
+	test.AssertEquals(t, uint8(96), at(2, 3))
+	// Pixels at least the spread outside of the edge saturate.
+	test.AssertEquals(t, uint8(0), at(0, 3))
+	test.AssertEquals(t, uint8(0), at(0, 0))
+	// The field is symmetric.
+	test.AssertEquals(t, at(2, 3), at(5, 4))
+	test.AssertEquals(t, at(3, 3), at(4, 4))
+}
+
+func TestSignedDistanceFieldCoverage(t *testing.T) {
+	// Partially covered pixels move the edge.
+	mask := image.NewAlpha(image.Rect(0, 0, 3, 1))
+	mask.Pix[0], mask.Pix[1], mask.Pix[2] = 0xff, 0xff, 0x00
+	sharp := signedDistanceField(mask, mask.Rect, 4).AlphaAt(6, 4).A
+	mask.Pix[1] = 0xc0
+	soft := signedDistanceField(mask, mask.Rect, 4).AlphaAt(6, 4).A
+	if soft >= sharp {
+		t.Errorf("Expected the pixel to be further outside of the edge. Got %d, sharp %d", soft, sharp)
+	}
+}
+
+func TestSignedDistanceFieldGlyph(t *testing.T) {
+	f, err := newFont(gxfont.Default, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := f.sdfTable().get('I')
+	entry := page.get('I')
+	dr, _, _, _, _ := f.sdfTable().face.Glyph(fixed.Point26_6{}, 'I')
+	test.AssertEquals(t, dr.Dx()+sdfSpread*2, entry.bounds.W())
+	test.AssertEquals(t, dr.Dy()+sdfSpread*2, entry.bounds.H())
+
+	// The stem of the glyph is inside, and the padding outside.
+	at := func(p image.Point) uint8 {
+		o := entry.offset
+		return page.image.AlphaAt(o.X+p.X, o.Y+p.Y).A
+	}
+	mid := entry.bounds.Mid()
+	test.AssertEquals(t, true, at(image.Pt(mid.X, mid.Y)) > 128)
+	test.AssertEquals(t, uint8(0), at(image.Pt(entry.bounds.Min.X, entry.bounds.Min.Y)))
+}
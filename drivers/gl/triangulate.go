@@ -7,6 +7,8 @@ package gl
 import (
 	"container/list"
 	"fmt"
+	"sort"
+
 	"github.com/vcaesar/guix/math"
 )
 
@@ -124,3 +126,121 @@ func triangulate(edges []math.Vec2) []math.Vec2 {
 	// assert.True(l.Len() < 3, "Failed to prune an ear! edges: %#v, out: %v", edges, out)
 	return out
 }
+
+// pathEdge is a non-horizontal edge of a contour, with a above b, and dir
+// the direction of the edge: 1 if the contour runs downwards, -1 if upwards.
+type pathEdge struct {
+	a, b math.Vec2
+	dir  int
+}
+
+func (e pathEdge) xAt(y float32) float32 {
+	return e.a.X + (e.b.X-e.a.X)*(y-e.a.Y)/(e.b.Y-e.a.Y)
+}
+
+// intersectY returns the y coordinate of the point where the edges e and f
+// cross, if they do.
+func (e pathEdge) intersectY(f pathEdge) (float32, bool) {
+	if e.b.Y <= f.a.Y || f.b.Y <= e.a.Y ||
+		math.Maxf(e.a.X, e.b.X) < math.Minf(f.a.X, f.b.X) ||
+		math.Maxf(f.a.X, f.b.X) < math.Minf(e.a.X, e.b.X) {
+		return 0, false
+	}
+	r, s := e.b.Sub(e.a), f.b.Sub(f.a)
+	d := r.Cross(s)
+	if math.Absf(d) < epsilon {
+		return 0, false // Parallel
+	}
+	q := f.a.Sub(e.a)
+	t, u := q.Cross(s)/d, q.Cross(r)/d
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return 0, false
+	}
+	return e.a.Y + r.Y*t, true
+}
+
+// slabEdge is a pathEdge clipped to a horizontal slab.
+type slabEdge struct {
+	top, mid, bottom float32 // x at the top, middle and bottom of the slab
+	dir              int
+}
+
+type slabEdges []slabEdge
+
+func (s slabEdges) Len() int           { return len(s) }
+func (s slabEdges) Less(i, j int) bool { return s[i].mid < s[j].mid }
+func (s slabEdges) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type float32s []float32
+
+func (s float32s) Len() int           { return len(s) }
+func (s float32s) Less(i, j int) bool { return s[i] < s[j] }
+func (s float32s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// triangulatePath returns the triangles filling the closed contours, which
+// may be concave, self-intersecting or nested. Regions are filled using the
+// even-odd rule if evenOdd is true, otherwise the non-zero rule.
+//
+// The contours are cut into horizontal slabs at every vertex and crossing
+// of two edges, so that within a slab no edges cross. Each slab is then
+// filled with a trapezoid between each pair of neighbouring edges that
+// encloses an inside region.
+func triangulatePath(contours [][]math.Vec2, evenOdd bool) []math.Vec2 {
+	edges := []pathEdge{}
+	ys := float32s{}
+	for _, c := range contours {
+		for i := range c {
+			a, b := c[i], c[(i+1)%len(c)]
+			ys = append(ys, a.Y)
+			if a.Y == b.Y {
+				continue // Horizontal edges do not affect the fill
+			}
+			dir := 1
+			if a.Y > b.Y {
+				a, b, dir = b, a, -1
+			}
+			edges = append(edges, pathEdge{a, b, dir})
+		}
+	}
+	for i := range edges {
+		for j := i + 1; j < len(edges); j++ {
+			if y, ok := edges[i].intersectY(edges[j]); ok {
+				ys = append(ys, y)
+			}
+		}
+	}
+	sort.Sort(ys)
+
+	out := []math.Vec2{}
+	spans := slabEdges{}
+	for i := 1; i < len(ys); i++ {
+		top, bottom := ys[i-1], ys[i]
+		if bottom-top < epsilon {
+			ys[i] = top // Merge the slab with the next
+			continue
+		}
+		mid := (top + bottom) / 2
+		spans = spans[:0]
+		for _, e := range edges {
+			if e.a.Y < mid && e.b.Y > mid {
+				spans = append(spans, slabEdge{e.xAt(top), e.xAt(mid), e.xAt(bottom), e.dir})
+			}
+		}
+		sort.Sort(spans)
+		winding := 0
+		for j := 0; j+1 < len(spans); j++ {
+			winding += spans[j].dir
+			inside := winding != 0
+			if evenOdd {
+				inside = winding%2 != 0
+			}
+			if inside {
+				l, r := spans[j], spans[j+1]
+				tl, tr := math.Vec2{X: l.top, Y: top}, math.Vec2{X: r.top, Y: top}
+				bl, br := math.Vec2{X: l.bottom, Y: bottom}, math.Vec2{X: r.bottom, Y: bottom}
+				out = append(out, tl, tr, br, tl, br, bl)
+			}
+		}
+	}
+	return out
+}
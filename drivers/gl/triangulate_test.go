@@ -256,3 +256,37 @@ func TestTriangulateChevron(t *testing.T) {
 	}
 	test.AssertEquals(t, tris, triangulate(edges))
 }
+
+func triangleArea(tris []math.Vec2) float32 {
+	area := float32(0)
+	for i := 0; i+2 < len(tris); i += 3 {
+		area += math.Absf(tris[i+1].Sub(tris[i]).Cross(tris[i+2].Sub(tris[i]))) / 2
+	}
+	return area
+}
+
+func square(x, y, size float32, clockwise bool) []math.Vec2 {
+	s := []math.Vec2{v(x, y), v(x+size, y), v(x+size, y+size), v(x, y+size)}
+	if !clockwise {
+		s[1], s[3] = s[3], s[1]
+	}
+	return s
+}
+
+func TestTriangulatePathHole(t *testing.T) {
+	outer, inner := square(0, 0, 10, true), square(2, 2, 4, false)
+	contours := [][]math.Vec2{outer, inner}
+	test.AssertEquals(t, float32(84), triangleArea(triangulatePath(contours, false)))
+	test.AssertEquals(t, float32(84), triangleArea(triangulatePath(contours, true)))
+
+	// Nested contours in the same direction only make a hole with even-odd.
+	contours[1] = square(2, 2, 4, true)
+	test.AssertEquals(t, float32(100), triangleArea(triangulatePath(contours, false)))
+	test.AssertEquals(t, float32(84), triangleArea(triangulatePath(contours, true)))
+}
+
+func TestTriangulatePathSelfIntersecting(t *testing.T) {
+	// A bow tie, crossing at (5, 5).
+	bowTie := []math.Vec2{v(0, 0), v(10, 10), v(10, 0), v(0, 10)}
+	test.AssertEquals(t, float32(50), triangleArea(triangulatePath([][]math.Vec2{bowTie}, false)))
+}
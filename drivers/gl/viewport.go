@@ -42,20 +42,25 @@ type viewport struct {
 	scrollAccumY            float64
 	destroyed               bool
 	redrawCount             uint32
+	composing               bool
+	compositionRect         math.Rect
 
 	// Broadcasts to application thread
-	onClose       guix.Event // ()
-	onResize      guix.Event // ()
-	onMouseMove   guix.Event // (guix.MouseEvent)
-	onMouseEnter  guix.Event // (guix.MouseEvent)
-	onMouseExit   guix.Event // (guix.MouseEvent)
-	onMouseDown   guix.Event // (guix.MouseEvent)
-	onMouseUp     guix.Event // (guix.MouseEvent)
-	onMouseScroll guix.Event // (guix.MouseEvent)
-	onKeyDown     guix.Event // (guix.KeyboardEvent)
-	onKeyUp       guix.Event // (guix.KeyboardEvent)
-	onKeyRepeat   guix.Event // (guix.KeyboardEvent)
-	onKeyStroke   guix.Event // (guix.KeyStrokeEvent)
+	onClose             guix.Event // ()
+	onResize            guix.Event // ()
+	onMouseMove         guix.Event // (guix.MouseEvent)
+	onMouseEnter        guix.Event // (guix.MouseEvent)
+	onMouseExit         guix.Event // (guix.MouseEvent)
+	onMouseDown         guix.Event // (guix.MouseEvent)
+	onMouseUp           guix.Event // (guix.MouseEvent)
+	onMouseScroll       guix.Event // (guix.MouseEvent)
+	onKeyDown           guix.Event // (guix.KeyboardEvent)
+	onKeyUp             guix.Event // (guix.KeyboardEvent)
+	onKeyRepeat         guix.Event // (guix.KeyboardEvent)
+	onKeyStroke         guix.Event // (guix.KeyStrokeEvent)
+	onCompositionStart  guix.Event // (guix.CompositionEvent)
+	onCompositionUpdate guix.Event // (guix.CompositionEvent)
+	onCompositionEnd    guix.Event // (guix.CompositionEvent)
 	// Broadcasts to driver thread
 	onDestroy guix.Event
 }
@@ -251,11 +256,17 @@ func newViewport(driver *driver, width, height int, title string, fullscreen boo
 	v.onKeyUp = driver.createAppEvent(func(guix.KeyboardEvent) {})
 	v.onKeyRepeat = driver.createAppEvent(func(guix.KeyboardEvent) {})
 	v.onKeyStroke = driver.createAppEvent(func(guix.KeyStrokeEvent) {})
+	v.onCompositionStart = driver.createAppEvent(func(guix.CompositionEvent) {})
+	v.onCompositionUpdate = driver.createAppEvent(func(guix.CompositionEvent) {})
+	v.onCompositionEnd = driver.createAppEvent(func(guix.CompositionEvent) {})
 	v.onDestroy = driver.createDriverEvent(func() {})
 	v.sizeDipsUnscaled = math.Size{W: width, H: height}
 	v.sizeDips = v.sizeDipsUnscaled.ScaleS(1 / v.scaling)
 	v.sizePixels = math.Size{W: fw, H: fh}
 	v.position = math.Point{X: posX, Y: posY}
+
+	// Interface compliance test
+	_ = CompositionInput(v)
 	return v
 }
 
@@ -273,6 +284,7 @@ func (v *viewport) render() {
 
 	dss := drawStateStack{drawState{
 		ClipPixels: v.sizePixels.Rect(),
+		Transform:  math.Mat3Ident,
 	}}
 
 	v.canvas.draw(ctx, &dss)
@@ -295,7 +307,7 @@ func (v *viewport) render() {
 func (v *viewport) drawFrameUpdate(ctx *context) {
 	dx := (ctx.stats.frameCount * 10) & 0xFF
 	r := math.CreateRect(dx-5, 0, dx+5, 3)
-	ds := &drawState{}
+	ds := &drawState{Transform: math.Mat3Ident}
 	ctx.blitter.blitRect(ctx, r, guix.White, ds)
 }
 
@@ -447,6 +459,24 @@ func (v *viewport) OnKeyStroke(f func(guix.KeyStrokeEvent)) guix.EventSubscripti
 	return v.onKeyStroke.Listen(f)
 }
 
+func (v *viewport) OnCompositionStart(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionStart.Listen(f)
+}
+
+func (v *viewport) OnCompositionUpdate(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionUpdate.Listen(f)
+}
+
+func (v *viewport) OnCompositionEnd(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionEnd.Listen(f)
+}
+
+func (v *viewport) SetCompositionRect(r math.Rect) {
+	v.Lock()
+	v.compositionRect = r
+	v.Unlock()
+}
+
 func (v *viewport) Destroy() {
 	v.driver.asyncDriver(func() {
 		if !v.destroyed {
@@ -0,0 +1,262 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// canvasOp writes a draw command to a page's content stream.
+type canvasOp func(w *contentWriter)
+
+// Canvas is a guix.Canvas that records draw commands for output as PDF.
+type Canvas struct {
+	size              math.Size
+	ops               []canvasOp
+	built             bool
+	buildingPushCount int
+}
+
+// CreateCanvas returns a new, empty Canvas of the given size in DIPs.
+func CreateCanvas(size math.Size) *Canvas {
+	return &Canvas{size: size}
+}
+
+func (c *Canvas) appendOp(name string, op canvasOp) {
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", name))
+	}
+	c.ops = append(c.ops, op)
+}
+
+func (c *Canvas) write(w *contentWriter) {
+	size := w.size
+	w.size = c.size
+	for _, op := range c.ops {
+		op(w)
+	}
+	w.size = size
+}
+
+// guix.Canvas compliance
+func (c *Canvas) Size() math.Size {
+	return c.size
+}
+
+func (c *Canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *Canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+}
+
+func (c *Canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp("Push", func(w *contentWriter) {
+		w.push(1, guix.BlendNormal)
+	})
+}
+
+func (c *Canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp("Pop", func(w *contentWriter) {
+		w.pop()
+	})
+}
+
+func (c *Canvas) PushOpacity(alpha float32) {
+	c.buildingPushCount++
+	c.appendOp("PushOpacity", func(w *contentWriter) {
+		w.push(alpha, guix.BlendNormal)
+	})
+}
+
+func (c *Canvas) PushBlendMode(mode guix.BlendMode) {
+	c.buildingPushCount++
+	c.appendOp("PushBlendMode", func(w *contentWriter) {
+		w.push(1, mode)
+	})
+}
+
+func (c *Canvas) AddClip(r math.Rect) {
+	c.appendOp("AddClip", func(w *contentWriter) {
+		w.rect(r)
+		w.printf("W n\n")
+	})
+}
+
+func (c *Canvas) Transform(m math.Mat3) {
+	c.appendOp("Transform", func(w *contentWriter) {
+		w.printf("%s %s %s %s %s %s cm\n", num(m[0]), num(m[1]), num(m[3]), num(m[4]), num(m[6]), num(m[7]))
+	})
+}
+
+func (c *Canvas) Translate(x, y float32) {
+	c.Transform(math.CreateMat3Translate(math.Vec2{X: x, Y: y}))
+}
+
+func (c *Canvas) Rotate(radians float32) {
+	c.Transform(math.CreateMat3Rotate(radians))
+}
+
+func (c *Canvas) Scale(x, y float32) {
+	c.Transform(math.CreateMat3Scale(math.Vec2{X: x, Y: y}))
+}
+
+func (c *Canvas) Clear(color guix.Color) {
+	c.appendOp("Clear", func(w *contentWriter) {
+		if w.setFill(color) {
+			w.rect(w.size.Rect())
+			w.printf("f\n")
+		}
+	})
+}
+
+func (c *Canvas) DrawCanvas(cc guix.Canvas, offset math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	child, ok := cc.(*Canvas)
+	if !ok {
+		panic(fmt.Errorf("%T cannot be drawn to a PDF canvas", cc))
+	}
+	c.appendOp("DrawCanvas", func(w *contentWriter) {
+		w.printf("q 1 0 0 1 %d %d cm\n", offset.X, offset.Y)
+		child.write(w)
+		w.printf("Q\n")
+	})
+}
+
+func (c *Canvas) DrawRunes(f guix.Font, runes []rune, points []math.Point, color guix.Color) {
+	if f == nil {
+		panic("Font cannot be nil")
+	}
+	if len(runes) != len(points) {
+		panic(fmt.Errorf("There must be the same number of runes to offsets. Got %d runes and %d offsets",
+			len(runes), len(points)))
+	}
+	if family, ok := f.(*guix.FontFamily); ok {
+		for _, run := range family.Runs(runes, points) {
+			c.DrawRunes(run.Font, run.Runes, run.Offsets, color)
+		}
+		return
+	}
+	pf, ok := f.(*font)
+	if !ok {
+		panic(fmt.Errorf("%T cannot be drawn to a PDF canvas. Create fonts with a driver returned by WrapDriver", f))
+	}
+	runes = guix.ShapeGlyphs(runes, pf)
+	points = append([]math.Point{}, points...)
+	c.appendOp("DrawRunes", func(w *contentWriter) {
+		if !w.setFill(color) {
+			return
+		}
+		name := w.font(pf.file)
+		w.printf("BT /%s %d Tf\n", name, pf.Size())
+		for i, r := range runes {
+			if r == 0 || unicode.IsSpace(r) {
+				continue
+			}
+			// The page is flipped vertically, so flip the text back.
+			p := points[i]
+			w.printf("1 0 0 -1 %d %d Tm <%04x> Tj\n", p.X, p.Y, w.glyph(pf.file, r))
+		}
+		w.printf("ET\n")
+	})
+}
+
+func (c *Canvas) DrawLines(lines guix.Polygon, pen guix.Pen) {
+	lines = append(guix.Polygon{}, lines...)
+	c.appendOp("DrawLines", func(w *contentWriter) {
+		if len(lines) < 2 || !w.setStroke(pen, 1) {
+			return
+		}
+		w.path(lines, false)
+		w.printf("S\n")
+	})
+}
+
+func (c *Canvas) DrawPolygon(poly guix.Polygon, pen guix.Pen, brush guix.Brush) {
+	poly = append(guix.Polygon{}, poly...)
+	c.appendOp("DrawPolygon", func(w *contentWriter) {
+		w.polygon(poly, pen, brush)
+	})
+}
+
+func (c *Canvas) DrawPath(path *guix.Path2D, pen guix.Pen, brush guix.Brush) {
+	path = path.Clone()
+	c.appendOp("DrawPath", func(w *contentWriter) {
+		if len(path.Segments) == 0 {
+			return
+		}
+		w.fillRule(brush, path.Bounds(), path.FillRule, func() { w.segments(path) })
+		if w.setStroke(pen, 1) {
+			w.segments(path)
+			w.printf("S\n")
+		}
+	})
+}
+
+func (c *Canvas) DrawRect(r math.Rect, brush guix.Brush) {
+	c.appendOp("DrawRect", func(w *contentWriter) {
+		w.fill(brush, r, func() { w.rect(r) })
+	})
+}
+
+func (c *Canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen guix.Pen, brush guix.Brush) {
+	if tl == 0 && tr == 0 && bl == 0 && br == 0 && (pen.Width <= 0 || pen.Color.A <= 0) {
+		c.DrawRect(r, brush)
+		return
+	}
+	c.DrawPolygon(guix.Polygon{
+		guix.PolygonVertex{Position: r.TL(), RoundedRadius: tl},
+		guix.PolygonVertex{Position: r.TR(), RoundedRadius: tr},
+		guix.PolygonVertex{Position: r.BR(), RoundedRadius: br},
+		guix.PolygonVertex{Position: r.BL(), RoundedRadius: bl},
+	}, pen, brush)
+}
+
+func (c *Canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s guix.Shadow) {
+	if !s.IsVisible() {
+		return
+	}
+	if s.Blur <= 0 {
+		p := s.Path(r, tl, tr, bl, br)
+		c.appendOp("DrawShadow", func(w *contentWriter) {
+			if len(p.Segments) > 0 {
+				w.fill(guix.CreateBrush(s.Color), p.Bounds(), func() { w.segments(p) })
+			}
+		})
+		return
+	}
+	b := s.Bounds(r)
+	c.DrawTexture(&shadowTexture{s.Image(r, tl, tr, bl, br, shadowPixelsPerDip), b.Size()}, b)
+}
+
+func (c *Canvas) DrawTexture(t guix.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	c.appendOp("DrawTexture", func(w *contentWriter) {
+		name := w.image(t)
+		if t.FlipY() {
+			w.printf("q %d 0 0 %d %d %d cm /%s Do Q\n", r.W(), r.H(), r.Min.X, r.Min.Y, name)
+		} else {
+			// Image space has the first row at the top of the unit square.
+			w.printf("q %d 0 0 %d %d %d cm /%s Do Q\n", r.W(), -r.H(), r.Min.X, r.Max.Y, name)
+		}
+	})
+}
@@ -0,0 +1,354 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// resources holds the fonts, images and graphics states used by the pages of
+// a document, which share a single resource dictionary.
+type resources struct {
+	fonts     []*fontFile
+	fontNames map[*fontFile]string
+	glyphs    map[*fontFile]map[truetype.Index]rune
+	textures  []guix.Texture
+	imageIDs  map[guix.Texture]string
+	alphas    []float32
+	blends    []guix.BlendMode
+	gradients []*guix.Gradient
+	shadings  map[*guix.Gradient]int
+	groups    []*group
+}
+
+// group is a transparency group, written as a form XObject, holding the
+// content drawn between a PushOpacity or PushBlendMode and its Pop.
+type group struct {
+	buf   bytes.Buffer
+	alpha float32
+	mode  guix.BlendMode
+}
+
+func newResources() *resources {
+	return &resources{
+		fontNames: make(map[*fontFile]string),
+		glyphs:    make(map[*fontFile]map[truetype.Index]rune),
+		imageIDs:  make(map[guix.Texture]string),
+		shadings:  make(map[*guix.Gradient]int),
+	}
+}
+
+// contentWriter writes the content stream of a single page.
+type contentWriter struct {
+	buf    bytes.Buffer
+	res    *resources
+	size   math.Size // Size of the canvas being written
+	pushes []*group  // The group opened by each Push, or nil
+}
+
+// out returns the buffer of the innermost open group, or of the page.
+func (w *contentWriter) out() *bytes.Buffer {
+	for i := len(w.pushes) - 1; i >= 0; i-- {
+		if g := w.pushes[i]; g != nil {
+			return &g.buf
+		}
+	}
+	return &w.buf
+}
+
+func (w *contentWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.out(), format, args...)
+}
+
+// push saves the graphics state. If alpha is less than 1 or mode is not
+// BlendNormal then the content written until the matching pop is collected
+// into a transparency group.
+func (w *contentWriter) push(alpha float32, mode guix.BlendMode) {
+	if alpha >= 1 && mode == guix.BlendNormal {
+		w.printf("q\n")
+		w.pushes = append(w.pushes, nil)
+		return
+	}
+	g := &group{alpha: alpha, mode: mode}
+	w.pushes = append(w.pushes, g)
+}
+
+// pop restores the graphics state saved by the matching push, painting the
+// group if one was opened.
+func (w *contentWriter) pop() {
+	last := len(w.pushes) - 1
+	g := w.pushes[last]
+	w.pushes = w.pushes[:last]
+	if g == nil {
+		w.printf("Q\n")
+		return
+	}
+	w.res.groups = append(w.res.groups, g)
+	w.printf("q\n")
+	w.alpha(g.alpha)
+	if g.mode != guix.BlendNormal {
+		w.blend(g.mode)
+	}
+	w.printf("/Fm%d Do Q\n", len(w.res.groups))
+}
+
+func num(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+func rgb(c guix.Color) string {
+	return num(math.Clampf(c.R, 0, 1)) + " " + num(math.Clampf(c.G, 0, 1)) + " " + num(math.Clampf(c.B, 0, 1))
+}
+
+// alpha selects the graphics state with the constant opacity a.
+func (w *contentWriter) alpha(a float32) {
+	a = math.Clampf(a, 0, 1)
+	for i, v := range w.res.alphas {
+		if v == a {
+			w.printf("/GS%d gs\n", i+1)
+			return
+		}
+	}
+	w.res.alphas = append(w.res.alphas, a)
+	w.printf("/GS%d gs\n", len(w.res.alphas))
+}
+
+// blend selects the graphics state with the blend mode m.
+func (w *contentWriter) blend(m guix.BlendMode) {
+	for i, v := range w.res.blends {
+		if v == m {
+			w.printf("/BM%d gs\n", i+1)
+			return
+		}
+	}
+	w.res.blends = append(w.res.blends, m)
+	w.printf("/BM%d gs\n", len(w.res.blends))
+}
+
+// setFill sets the fill color, returning false if c is fully transparent.
+func (w *contentWriter) setFill(c guix.Color) bool {
+	if c.A <= 0 {
+		return false
+	}
+	w.alpha(c.A)
+	w.printf("%s rg\n", rgb(c))
+	return true
+}
+
+// setStroke sets the stroke color, width and style of the pen p, with the line
+// width scaled by widthScale. setStroke returns false if the pen is not
+// visible.
+func (w *contentWriter) setStroke(p guix.Pen, widthScale float32) bool {
+	if !p.IsVisible() {
+		return false
+	}
+	var cap, join int
+	switch p.Cap {
+	case guix.RoundCap:
+		cap = 1
+	case guix.SquareCap:
+		cap = 2
+	}
+	switch p.Join {
+	case guix.RoundJoin:
+		join = 1
+	case guix.BevelJoin:
+		join = 2
+	}
+	w.alpha(p.Color.A)
+	w.printf("%s RG %s w %d J %d j %s M ", rgb(p.Color), num(p.Width*widthScale), cap, join,
+		num(math.Maxf(p.EffectiveMiterLimit(), 1)))
+	if p.Dash != nil && len(p.Dash.Pattern) > 0 {
+		lengths := make([]string, len(p.Dash.Pattern))
+		for i, l := range p.Dash.Pattern {
+			lengths[i] = num(l)
+		}
+		w.printf("[%s] %s d\n", strings.Join(lengths, " "), num(p.Dash.Offset))
+	} else {
+		w.printf("[] 0 d\n")
+	}
+	return true
+}
+
+// fill fills the shape written by path with the brush b. bounds is the
+// bounding rectangle of the shape.
+func (w *contentWriter) fill(b guix.Brush, bounds math.Rect, path func()) {
+	w.fillRule(b, bounds, guix.NonZero, path)
+}
+
+// fillRule is like fill, but fills the shape using the fill rule r.
+func (w *contentWriter) fillRule(b guix.Brush, bounds math.Rect, r guix.FillRule, path func()) {
+	rule := ""
+	if r == guix.EvenOdd {
+		rule = "*"
+	}
+	g := b.Gradient
+	if g == nil {
+		if w.setFill(b.Color) {
+			path()
+			w.printf("f%s\n", rule)
+		}
+		return
+	}
+	if !b.IsVisible() || bounds.W() == 0 || bounds.H() == 0 {
+		return
+	}
+	if isDegenerate(g) {
+		// The gradient has a single color.
+		if w.setFill(g.ColorAt(g.Offset(math.Vec2{}))) {
+			path()
+			w.printf("f%s\n", rule)
+		}
+		return
+	}
+	i := w.shading(g)
+	w.printf("q\n")
+	path()
+	w.printf("W%s n\n", rule)
+	// Map the unit square to the bounds of the shape.
+	w.printf("%d 0 0 %d %d %d cm\n", bounds.W(), bounds.H(), bounds.Min.X, bounds.Min.Y)
+	w.alpha(1)
+	if !g.IsOpaque() {
+		w.printf("/SM%d gs\n", i)
+	}
+	w.printf("/Sh%d sh Q\n", i)
+}
+
+// isDegenerate returns true if the gradient g has the same color everywhere.
+func isDegenerate(g *guix.Gradient) bool {
+	if g.Type == guix.RadialGradient {
+		return g.Radius <= 0
+	}
+	return g.Start == g.End
+}
+
+// shading returns the index of the shading of the gradient g, recording its
+// use.
+func (w *contentWriter) shading(g *guix.Gradient) int {
+	i, ok := w.res.shadings[g]
+	if !ok {
+		w.res.gradients = append(w.res.gradients, g)
+		i = len(w.res.gradients)
+		w.res.shadings[g] = i
+	}
+	return i
+}
+
+func (w *contentWriter) rect(r math.Rect) {
+	w.printf("%d %d %d %d re\n", r.Min.X, r.Min.Y, r.W(), r.H())
+}
+
+// path writes the polygon p as a path, with rounded vertices. If closed is
+// false then p is treated as a polyline and its end points are not rounded.
+func (w *contentWriter) path(p guix.Polygon, closed bool) {
+	cnt := len(p)
+	for i := 0; i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		ab, ac := a, a
+		if closed || (i > 0 && i < cnt-1) {
+			ab, ac = p.Corner(i)
+		}
+		if i == 0 {
+			w.printf("%s %s m\n", num(ab.X), num(ab.Y))
+		} else {
+			w.printf("%s %s l\n", num(ab.X), num(ab.Y))
+		}
+		if ab != ac {
+			// Raise the quadratic curve ab, a, ac to a cubic.
+			c1 := ab.Add(a.Sub(ab).MulS(2.0 / 3))
+			c2 := ac.Add(a.Sub(ac).MulS(2.0 / 3))
+			w.printf("%s %s %s %s %s %s c\n", num(c1.X), num(c1.Y), num(c2.X), num(c2.Y), num(ac.X), num(ac.Y))
+		}
+	}
+	if closed {
+		w.printf("h\n")
+	}
+}
+
+func (w *contentWriter) polygon(p guix.Polygon, pen guix.Pen, brush guix.Brush) {
+	if len(p) < 3 {
+		return
+	}
+	w.fill(brush, p.Bounds(), func() { w.path(p, true) })
+	if pen.IsVisible() {
+		// Pens are drawn along the inside of the polygon's edge. Stroke the
+		// path at double width, clipped to the polygon.
+		w.printf("q\n")
+		w.path(p, true)
+		w.printf("W n\n")
+		w.setStroke(pen, 2)
+		w.path(p, true)
+		w.printf("S Q\n")
+	}
+}
+
+// segments writes the segments of the path p, raising quadratic curves to
+// cubics.
+func (w *contentWriter) segments(p *guix.Path2D) {
+	var start, current math.Vec2
+	for i, s := range p.Segments {
+		if i == 0 && s.Verb != guix.PathMoveTo {
+			w.printf("0 0 m\n")
+		}
+		switch s.Verb {
+		case guix.PathMoveTo:
+			start, current = s.Points[0], s.Points[0]
+			w.printf("%s %s m\n", num(current.X), num(current.Y))
+		case guix.PathLineTo:
+			current = s.Points[0]
+			w.printf("%s %s l\n", num(current.X), num(current.Y))
+		case guix.PathQuadTo:
+			c, e := s.Points[0], s.Points[1]
+			c1 := current.Add(c.Sub(current).MulS(2.0 / 3))
+			c2 := e.Add(c.Sub(e).MulS(2.0 / 3))
+			w.printf("%s %s %s %s %s %s c\n", num(c1.X), num(c1.Y), num(c2.X), num(c2.Y), num(e.X), num(e.Y))
+			current = e
+		case guix.PathCubicTo:
+			c1, c2, e := s.Points[0], s.Points[1], s.Points[2]
+			w.printf("%s %s %s %s %s %s c\n", num(c1.X), num(c1.Y), num(c2.X), num(c2.Y), num(e.X), num(e.Y))
+			current = e
+		case guix.PathClose:
+			w.printf("h\n")
+			current = start
+		}
+	}
+}
+
+// font returns the resource name of the font f.
+func (w *contentWriter) font(f *fontFile) string {
+	name, ok := w.res.fontNames[f]
+	if !ok {
+		w.res.fonts = append(w.res.fonts, f)
+		name = "F" + strconv.Itoa(len(w.res.fonts))
+		w.res.fontNames[f] = name
+		w.res.glyphs[f] = make(map[truetype.Index]rune)
+	}
+	return name
+}
+
+// glyph returns the glyph index of r in the font f, recording its use.
+func (w *contentWriter) glyph(f *fontFile, r rune) truetype.Index {
+	i := f.ttf.Index(r)
+	w.res.glyphs[f][i] = r
+	return i
+}
+
+// image returns the resource name of the image of the texture t.
+func (w *contentWriter) image(t guix.Texture) string {
+	name, ok := w.res.imageIDs[t]
+	if !ok {
+		w.res.textures = append(w.res.textures, t)
+		name = "Im" + strconv.Itoa(len(w.res.textures))
+		w.res.imageIDs[t] = name
+	}
+	return name
+}
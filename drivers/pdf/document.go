@@ -0,0 +1,380 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pdf contains a guix.Canvas implementation that writes multi-page
+// PDF documents, for printing controls.
+//
+// Controls are printed by creating them with a theme whose driver was
+// returned by WrapDriver, and adding them to a Document with AddControl. Each
+// control is laid out at the page width in DIPs, starting on a new page, and
+// continues on the following pages if it is taller than a page. A DIP is
+// 1/96th of an inch.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Page sizes in DIPs.
+var (
+	A4     = math.Size{W: 794, H: 1123}
+	Letter = math.Size{W: 816, H: 1056}
+)
+
+// Number of PDF points (1/72th of an inch) for each DIP.
+const pointsPerDip = 72.0 / 96.0
+
+// Document is a PDF document of one or more pages.
+type Document struct {
+	pageSize math.Size
+	pages    []*Canvas
+}
+
+// CreateDocument returns a new, empty Document with pages of the given size
+// in DIPs.
+func CreateDocument(pageSize math.Size) *Document {
+	return &Document{pageSize: pageSize}
+}
+
+// PageSize returns the size of the document's pages in DIPs.
+func (d *Document) PageSize() math.Size {
+	return d.pageSize
+}
+
+// PageCount returns the number of pages added to the document.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// AddPage appends a page to the document displaying the completed canvas c,
+// with the top-left of c at the top-left of the page.
+func (d *Document) AddPage(c *Canvas) {
+	if !c.IsComplete() {
+		panic("AddPage() called with a canvas that is not complete")
+	}
+	d.pages = append(d.pages, c)
+}
+
+// AddControl lays c out at the width of the page and appends the pages
+// displaying it. c fills at least one page, and is split across as many
+// pages as its desired height needs. c must not be attached, and must have
+// been created by a theme whose driver was returned by WrapDriver.
+// AddControl must be called on the UI go-routine.
+func (d *Document) AddControl(c guix.Control) {
+	if c.Attached() {
+		panic("AddControl() called with an attached control")
+	}
+	p := &page{}
+	p.child = &guix.Child{Control: c}
+	c.SetParent(p)
+	c.Attach()
+	size := c.DesiredSize(math.Size{W: d.pageSize.W}, math.Size{W: d.pageSize.W, H: math.MaxSize.H})
+	size.W = d.pageSize.W
+	if size.H < d.pageSize.H || size.H >= math.MaxSize.H {
+		size.H = d.pageSize.H // Fill the page, including if c fills any height
+	}
+	p.child.Layout(size.Rect())
+
+	cc := c.Draw()
+	for y := 0; y < size.H; y += d.pageSize.H {
+		canvas := CreateCanvas(d.pageSize)
+		if cc != nil {
+			// The page clips the parts of c above and below it.
+			canvas.DrawCanvas(cc, p.child.Offset.AddY(-y))
+		}
+		canvas.Complete()
+		d.AddPage(canvas)
+	}
+
+	c.Detach()
+	c.SetParent(nil)
+}
+
+// page is the parent of a control being printed.
+type page struct {
+	child *guix.Child
+}
+
+func (p *page) Children() guix.Children {
+	return guix.Children{p.child}
+}
+
+func (p *page) Relayout() {}
+func (p *page) Redraw()   {}
+
+// WriteTo writes the document to w in PDF format.
+func (d *Document) WriteTo(w io.Writer) (n int64, err error) {
+	res := newResources()
+	contents := make([][]byte, len(d.pages))
+	for i, c := range d.pages {
+		cw := &contentWriter{res: res}
+		// Flip the page so the origin is at the top-left, with units of DIPs.
+		cw.printf("%s 0 0 %s 0 %s cm\n",
+			num(pointsPerDip), num(-pointsPerDip), num(float32(d.pageSize.H)*pointsPerDip))
+		c.write(cw)
+		contents[i] = cw.buf.Bytes()
+	}
+
+	o := &objectWriter{}
+	o.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	catalog, pages, resourcesID := o.alloc(), o.alloc(), o.alloc()
+
+	o.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pages)
+
+	pageIDs := make([]string, len(d.pages))
+	width, height := float32(d.pageSize.W)*pointsPerDip, float32(d.pageSize.H)*pointsPerDip
+	for i, content := range contents {
+		page, stream := o.alloc(), o.alloc()
+		pageIDs[i] = fmt.Sprintf("%d 0 R", page)
+		o.object(page, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+			pages, num(width), num(height), resourcesID, stream)
+		o.stream(stream, "", content)
+	}
+	o.object(pages, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIDs, " "), len(pageIDs))
+
+	var fonts, images, states bytes.Buffer
+	for _, f := range res.fonts {
+		fmt.Fprintf(&fonts, "/%s %d 0 R ", res.fontNames[f], o.font(f, res.glyphs[f]))
+	}
+	for i, t := range res.textures {
+		fmt.Fprintf(&images, "/Im%d %d 0 R ", i+1, o.image(t.Image()))
+	}
+	for i, g := range res.groups {
+		// The content of a group may be transformed anywhere, so the group's
+		// bounding box is unbounded in practice. The page clips it.
+		form := o.alloc()
+		o.stream(form, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [-32767 -32767 32767 32767] "+
+			"/Group << /S /Transparency >> /Resources %d 0 R ", resourcesID), g.buf.Bytes())
+		fmt.Fprintf(&images, "/Fm%d %d 0 R ", i+1, form)
+	}
+	for i, a := range res.alphas {
+		fmt.Fprintf(&states, "/GS%d << /ca %s /CA %s >> ", i+1, num(a), num(a))
+	}
+	for i, m := range res.blends {
+		fmt.Fprintf(&states, "/BM%d << /BM /%s >> ", i+1, blendModeNames[m])
+	}
+	var shadings bytes.Buffer
+	for i, g := range res.gradients {
+		shading, mask := o.gradient(g)
+		fmt.Fprintf(&shadings, "/Sh%d %d 0 R ", i+1, shading)
+		if mask != 0 {
+			fmt.Fprintf(&states, "/SM%d << /SMask << /S /Luminosity /G %d 0 R >> >> ", i+1, mask)
+		}
+	}
+	o.object(resourcesID, "<< /ProcSet [/PDF /Text /ImageB /ImageC] /Font << %s>> /XObject << %s>> /ExtGState << %s>> /Shading << %s>> >>",
+		fonts.String(), images.String(), states.String(), shadings.String())
+
+	o.finish(catalog)
+	return o.buf.WriteTo(w)
+}
+
+// blendModeNames maps blend modes to the names of PDF blend modes.
+var blendModeNames = map[guix.BlendMode]string{
+	guix.BlendNormal:   "Normal",
+	guix.BlendMultiply: "Multiply",
+	guix.BlendScreen:   "Screen",
+	// PDF has no additive blend mode. Lighten is the closest.
+	guix.BlendAdditive: "Lighten",
+}
+
+// objectWriter writes the numbered objects of a PDF file, tracking their
+// offsets for the cross-reference table.
+type objectWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// alloc reserves and returns a new object number.
+func (o *objectWriter) alloc() int {
+	o.offsets = append(o.offsets, -1)
+	return len(o.offsets)
+}
+
+func (o *objectWriter) begin(id int) {
+	o.offsets[id-1] = o.buf.Len()
+	fmt.Fprintf(&o.buf, "%d 0 obj\n", id)
+}
+
+func (o *objectWriter) object(id int, format string, args ...interface{}) {
+	o.begin(id)
+	fmt.Fprintf(&o.buf, format, args...)
+	o.buf.WriteString("\nendobj\n")
+}
+
+// stream writes a Flate compressed stream object, with the additional
+// dictionary entries dict.
+func (o *objectWriter) stream(id int, dict string, data []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data)
+	zw.Close()
+	o.begin(id)
+	fmt.Fprintf(&o.buf, "<< %s/Length %d /Filter /FlateDecode >>\nstream\n", dict, z.Len())
+	o.buf.Write(z.Bytes())
+	o.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (o *objectWriter) finish(root int) {
+	xref := o.buf.Len()
+	fmt.Fprintf(&o.buf, "xref\n0 %d\n0000000000 65535 f \n", len(o.offsets)+1)
+	for _, offset := range o.offsets {
+		fmt.Fprintf(&o.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&o.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(o.offsets)+1, root, xref)
+}
+
+var fontNameInvalid = regexp.MustCompile(`[^A-Za-z0-9\-]`)
+
+// font writes the TrueType font f as a composite font addressed by glyph
+// index, returning the font's object number. glyphs maps the glyph indices
+// used by the document to the runes they display.
+func (o *objectWriter) font(f *fontFile, glyphs map[truetype.Index]rune) int {
+	name := fontNameInvalid.ReplaceAllString(f.ttf.Name(truetype.NameIDPostscriptName), "")
+	if name == "" {
+		name = "Font"
+	}
+	bounds := f.ttf.Bounds(1000)
+
+	indices := make([]int, 0, len(glyphs))
+	for i := range glyphs {
+		indices = append(indices, int(i))
+	}
+	sort.Ints(indices)
+
+	var widths, cmap bytes.Buffer
+	for _, i := range indices {
+		fmt.Fprintf(&widths, "%d [%d] ", i, f.ttf.HMetric(1000, truetype.Index(i)).AdvanceWidth)
+	}
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(indices); start += 100 {
+		chunk := indices[start:]
+		if len(chunk) > 100 {
+			chunk = chunk[:100]
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, i := range chunk {
+			fmt.Fprintf(&cmap, "<%04x> <", i)
+			for _, u := range utf16.Encode([]rune{glyphs[truetype.Index(i)]}) {
+				fmt.Fprintf(&cmap, "%04x", u)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	font, cid, descriptor, file, toUnicode := o.alloc(), o.alloc(), o.alloc(), o.alloc(), o.alloc()
+	o.object(font, "<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cid, toUnicode)
+	o.object(cid, "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		name, descriptor, widths.String())
+	o.object(descriptor, "<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Max.Y,
+		bounds.Max.Y, bounds.Min.Y, bounds.Max.Y, file)
+	o.stream(file, fmt.Sprintf("/Length1 %d ", len(f.data)), f.data)
+	o.stream(toUnicode, "", cmap.Bytes())
+	return font
+}
+
+// image writes img as an RGB image, with a soft mask if img has any
+// transparent pixels, returning the image's object number.
+func (o *objectWriter) image(img image.Image) int {
+	b := img.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			if a != 0 && a != 0xffff {
+				// Un-premultiply
+				r, g, bl = r*0xffff/a, g*0xffff/a, bl*0xffff/a
+			}
+			rgb = append(rgb, byte(r>>8), byte(g>>8), byte(bl>>8))
+			alpha = append(alpha, byte(a>>8))
+			opaque = opaque && a == 0xffff
+		}
+	}
+	id := o.alloc()
+	smask := ""
+	if !opaque {
+		mask := o.alloc()
+		smask = fmt.Sprintf("/SMask %d 0 R ", mask)
+		o.stream(mask, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 ",
+			b.Dx(), b.Dy()), alpha)
+	}
+	o.stream(id, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 %s",
+		b.Dx(), b.Dy(), smask), rgb)
+	return id
+}
+
+// gradient writes a shading of the gradient g over the unit square, returning
+// its object number. If g is not opaque then gradient also writes a soft mask
+// group of the gradient's opacity, returning its object number as mask.
+func (o *objectWriter) gradient(g *guix.Gradient) (shading, mask int) {
+	// Find the range of offsets covered by the unit square.
+	t0, t1 := g.Offset(math.Vec2{}), g.Offset(math.Vec2{})
+	for _, p := range []math.Vec2{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}} {
+		t := g.Offset(p)
+		t0, t1 = math.Minf(t0, t), math.Maxf(t1, t)
+	}
+	var geometry string
+	if g.Type == guix.RadialGradient {
+		t0 = 0
+		geometry = fmt.Sprintf("/ShadingType 3 /Coords [%s %s 0 %s %s %s]",
+			num(g.Start.X), num(g.Start.Y), num(g.Start.X), num(g.Start.Y), num(g.Radius*t1))
+	} else {
+		d := g.End.Sub(g.Start)
+		a, b := g.Start.Add(d.MulS(t0)), g.Start.Add(d.MulS(t1))
+		geometry = fmt.Sprintf("/ShadingType 2 /Coords [%s %s %s %s]", num(a.X), num(a.Y), num(b.X), num(b.Y))
+	}
+	geometry += fmt.Sprintf(" /Domain [%s %s] /Extend [true true]", num(t0), num(t1))
+
+	// Repeating gradients are sampled over the whole range, so that the
+	// sampled function does not need to repeat.
+	count := math.Clamp(int(256*math.Ceilf(t1-t0)), 256, 4096)
+	rgb := make([]byte, 0, count*3)
+	alpha := make([]byte, 0, count)
+	for i := 0; i < count; i++ {
+		c := g.ColorAt(math.Lerpf(t0, t1, float32(i)/float32(count-1))).Saturate()
+		rgb = append(rgb, byte(c.R*255+0.5), byte(c.G*255+0.5), byte(c.B*255+0.5))
+		alpha = append(alpha, byte(c.A*255+0.5))
+	}
+
+	shading, function := o.alloc(), o.alloc()
+	o.object(shading, "<< %s /ColorSpace /DeviceRGB /Function %d 0 R >>", geometry, function)
+	o.stream(function, fmt.Sprintf("/FunctionType 0 /Domain [%s %s] /Range [0 1 0 1 0 1] /Size [%d] /BitsPerSample 8 ",
+		num(t0), num(t1), count), rgb)
+	if g.IsOpaque() {
+		return shading, 0
+	}
+
+	mask, alphaShading, alphaFunction := o.alloc(), o.alloc(), o.alloc()
+	o.stream(mask, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 1 1] "+
+		"/Group << /S /Transparency /CS /DeviceGray >> /Resources << /Shading << /Sh %d 0 R >> >> ", alphaShading),
+		[]byte("/Sh sh\n"))
+	o.object(alphaShading, "<< %s /ColorSpace /DeviceGray /Function %d 0 R >>", geometry, alphaFunction)
+	o.stream(alphaFunction, fmt.Sprintf("/FunctionType 0 /Domain [%s %s] /Range [0 1] /Size [%d] /BitsPerSample 8 ",
+		num(t0), num(t1), count), alpha)
+	return shading, mask
+}
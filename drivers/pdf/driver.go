@@ -0,0 +1,86 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// fontFile is a parsed TrueType font, shared by all the sizes of the font.
+type fontFile struct {
+	data []byte
+	ttf  *truetype.Font
+}
+
+// font is a guix.Font that retains the TrueType data of the font, so that it
+// can be embedded in documents.
+type font struct {
+	guix.Font
+	file *fontFile
+}
+
+// GlyphMetrics compliance
+func (f *font) HasGlyph(r rune) bool {
+	return f.file.ttf.Index(r) != 0
+}
+
+func (f *font) Advance(r rune) int {
+	if m, ok := f.Font.(guix.GlyphMetrics); ok {
+		return m.Advance(r)
+	}
+	return f.Measure(&guix.TextBlock{Runes: []rune{r}}).W
+}
+
+func (f *font) Kern(a, b rune) int {
+	if m, ok := f.Font.(guix.GlyphMetrics); ok {
+		return m.Kern(a, b)
+	}
+	return 0
+}
+
+type driver struct {
+	guix.Driver
+	lock  sync.Mutex
+	files map[*byte]*fontFile
+}
+
+// WrapDriver returns a guix.Driver that behaves like d, except that
+// CreateCanvas returns PDF canvases and CreateFont returns fonts that can be
+// embedded in documents. Themes created with the returned driver create
+// controls that can be printed with Document.AddControl. Such controls cannot
+// be displayed in windows.
+func WrapDriver(d guix.Driver) guix.Driver {
+	return &driver{
+		Driver: d,
+		files:  make(map[*byte]*fontFile),
+	}
+}
+
+func (d *driver) CreateCanvas(s math.Size) guix.Canvas {
+	return CreateCanvas(s)
+}
+
+func (d *driver) CreateFont(data []byte, size int) (guix.Font, error) {
+	f, err := d.Driver.CreateFont(data, size)
+	if err != nil {
+		return nil, err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	file, found := d.files[&data[0]]
+	if !found {
+		ttf, err := truetype.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		file = &fontFile{data: data, ttf: ttf}
+		d.files[&data[0]] = file
+	}
+	return &font{Font: f, file: file}, nil
+}
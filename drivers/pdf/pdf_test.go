@@ -0,0 +1,178 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/drivers/pdf"
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+	"github.com/vcaesar/guix/testing/guixtest"
+	"github.com/vcaesar/guix/themes/dark"
+)
+
+type texture struct{}
+
+func (texture) Image() image.Image    { return image.NewNRGBA(image.Rect(0, 0, 2, 2)) }
+func (texture) Size() math.Size       { return math.Size{W: 2, H: 2} }
+func (texture) SizePixels() math.Size { return math.Size{W: 2, H: 2} }
+func (texture) FlipY() bool           { return false }
+func (texture) SetFlipY(bool)         {}
+
+var xrefEntry = regexp.MustCompile(`(\d{10}) 00000 n `)
+
+// checkXref checks that each entry of the cross-reference table of the PDF
+// file b is at the start of the corresponding object.
+func checkXref(t *testing.T, b []byte) {
+	for i, m := range xrefEntry.FindAllSubmatch(b, -1) {
+		offset, _ := strconv.Atoi(string(m[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(b[offset:], []byte(want)) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	child := pdf.CreateCanvas(math.Size{W: 20, H: 10})
+	child.DrawRect(math.CreateRect(0, 0, 20, 10), guix.CreateBrush(guix.Red))
+	child.Complete()
+
+	c := pdf.CreateCanvas(pdf.A4)
+	c.Push()
+	c.AddClip(math.CreateRect(5, 5, 95, 45))
+	c.Rotate(0.1)
+	c.DrawRoundedRect(math.CreateRect(10, 10, 60, 30), 3, 3, 3, 3, guix.CreatePen(1, guix.White), guix.CreateBrush(guix.Gray50))
+	c.DrawLines(guix.Polygon{
+		guix.PolygonVertex{Position: math.Point{X: 0, Y: 0}},
+		guix.PolygonVertex{Position: math.Point{X: 50, Y: 0}},
+	}, guix.CreatePen(2, guix.Blue))
+	c.Pop()
+	c.DrawCanvas(child, math.Point{X: 70, Y: 35})
+	c.PushOpacity(0.5)
+	c.PushBlendMode(guix.BlendMultiply)
+	c.DrawRect(math.CreateRect(0, 0, 10, 10), guix.CreateBrush(guix.Red))
+	c.Pop()
+	c.Pop()
+	c.DrawTexture(texture{}, math.CreateRect(0, 40, 10, 50))
+	p := &guix.Path2D{FillRule: guix.EvenOdd}
+	p.AddEllipse(math.Vec2{X: 20, Y: 20}, math.Vec2{X: 10, Y: 5})
+	p.QuadTo(math.Vec2{X: 30, Y: 30}, math.Vec2{X: 20, Y: 30})
+	c.DrawPath(p, guix.CreatePen(1, guix.White), guix.CreateBrush(guix.Red))
+	c.DrawShadow(math.CreateRect(10, 10, 30, 20), 2, 2, 2, 2, guix.CreateShadow(math.Vec2{Y: 2}, 4, 0, guix.Black))
+	c.DrawShadow(math.CreateRect(10, 10, 30, 20), 0, 0, 0, 0, guix.CreateShadow(math.Vec2{Y: 2}, 0, 1, guix.Black))
+	c.DrawRect(math.CreateRect(0, 0, 10, 10), guix.CreateLinearGradientBrush(math.Vec2{}, math.Vec2{X: 1},
+		guix.GradientStop{Offset: 0, Color: guix.White},
+		guix.GradientStop{Offset: 1, Color: guix.Transparent},
+	))
+	c.Complete()
+
+	d := pdf.CreateDocument(pdf.A4)
+	d.AddPage(c)
+	var b bytes.Buffer
+	if _, err := d.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	s := b.String()
+	for _, want := range []string{
+		"%PDF-1.4",
+		"/MediaBox [0 0 595.5 842.25]",
+		"/Count 1",
+		"/Subtype /Image /Width 2 /Height 2",
+		"/SMask",
+		"/ShadingType 2 /Coords [0 0 1 0] /Domain [0 1]",
+		"/SM1 << /SMask << /S /Luminosity",
+		"/Subtype /Form /BBox [-32767 -32767 32767 32767] /Group << /S /Transparency >>",
+		"/Fm2 ",
+		"/BM1 << /BM /Multiply >>",
+		"%%EOF",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+	checkXref(t, b.Bytes())
+}
+
+func TestAddControl(t *testing.T) {
+	createTheme := func(d guix.Driver) guix.Theme { return dark.CreateTheme(pdf.WrapDriver(d)) }
+	guixtest.Run(guixtest.Options{CreateTheme: createTheme}, func(r *guixtest.Robot) {
+		d := pdf.CreateDocument(pdf.Letter)
+		r.Do(func() {
+			l := r.Theme.CreateLabel()
+			l.SetText("Hello")
+			d.AddControl(l)
+
+			b := r.Theme.CreateButton()
+			b.SetText("World")
+			d.AddControl(b)
+			test.AssertEquals(t, false, b.Attached())
+		})
+		test.AssertEquals(t, 2, d.PageCount())
+
+		var b bytes.Buffer
+		if _, err := d.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		s := b.String()
+		for _, want := range []string{
+			"/Count 2",
+			"/Subtype /Type0",
+			"/Encoding /Identity-H",
+			"/CIDToGIDMap /Identity",
+			"/FontFile2",
+			"/ToUnicode",
+		} {
+			if !strings.Contains(s, want) {
+				t.Errorf("PDF does not contain %q", want)
+			}
+		}
+		checkXref(t, b.Bytes())
+	})
+}
+
+func TestAddControlPages(t *testing.T) {
+	createTheme := func(d guix.Driver) guix.Theme { return dark.CreateTheme(pdf.WrapDriver(d)) }
+	guixtest.Run(guixtest.Options{CreateTheme: createTheme}, func(r *guixtest.Robot) {
+		d := pdf.CreateDocument(pdf.Letter)
+		var l guix.LinearLayout
+		r.Do(func() {
+			// A label fills the page.
+			label := r.Theme.CreateLabel()
+			label.SetText("Hello")
+			d.AddControl(label)
+			test.AssertEquals(t, pdf.Letter, label.Size())
+
+			// A layout taller than a page continues on the next pages.
+			l = r.Theme.CreateLinearLayout()
+			for i := 0; i < 100; i++ {
+				b := r.Theme.CreateButton()
+				b.SetText("Button")
+				l.AddChild(b)
+			}
+			d.AddControl(l)
+		})
+		pages := (l.Size().H + pdf.Letter.H - 1) / pdf.Letter.H
+		if pages < 2 {
+			t.Fatalf("expected the layout to be taller than a page, got %v", l.Size())
+		}
+		test.AssertEquals(t, pdf.Letter.W, l.Size().W)
+		test.AssertEquals(t, 1+pages, d.PageCount())
+
+		var b bytes.Buffer
+		if _, err := d.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		checkXref(t, b.Bytes())
+	})
+}
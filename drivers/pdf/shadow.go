@@ -0,0 +1,32 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"image"
+
+	"github.com/vcaesar/guix/math"
+)
+
+// shadowPixelsPerDip is the resolution of the images of blurred shadows.
+const shadowPixelsPerDip = 2
+
+// shadowTexture is a guix.Texture of the image of a blurred shadow. PDF has
+// no blur operator, so blurred shadows are drawn as images.
+type shadowTexture struct {
+	img  image.Image
+	size math.Size
+}
+
+// guix.Texture compliance
+func (t *shadowTexture) Image() image.Image { return t.img }
+func (t *shadowTexture) Size() math.Size    { return t.size }
+func (t *shadowTexture) FlipY() bool        { return false }
+func (t *shadowTexture) SetFlipY(bool)      {}
+
+func (t *shadowTexture) SizePixels() math.Size {
+	s := t.img.Bounds().Size()
+	return math.Size{W: s.X, H: s.Y}
+}
@@ -0,0 +1,329 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/golang/freetype/raster"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+type drawStateStack []drawState
+
+func (s *drawStateStack) head() *drawState {
+	return &(*s)[len(*s)-1]
+}
+func (s *drawStateStack) push(ds drawState) {
+	*s = append(*s, ds)
+}
+func (s *drawStateStack) pop() {
+	*s = (*s)[:len(*s)-1]
+}
+
+type canvasOp func(ctx *context, dss *drawStateStack)
+
+type drawState struct {
+	// The below are all in window coordinates
+	ClipPixels   image.Rectangle
+	OriginPixels math.Point
+
+	// Transform maps local DIPs to DIPs relative to OriginPixels.
+	Transform math.Mat3
+
+	// Layer is the layer being drawn to, or nil if drawing to the viewport.
+	Layer *layer
+}
+
+type canvas struct {
+	sizeDips          math.Size
+	ops               []canvasOp
+	built             bool
+	buildingPushCount int
+}
+
+func newCanvas(sizeDips math.Size) *canvas {
+	if sizeDips.W <= 0 || sizeDips.H < 0 {
+		panic(fmt.Errorf("Canvas width and height must be positive. Size: %d", sizeDips))
+	}
+	c := &canvas{
+		sizeDips: sizeDips,
+	}
+	return c
+}
+
+func (c *canvas) draw(ctx *context, dss *drawStateStack) {
+	for _, op := range c.ops {
+		op(ctx, dss)
+	}
+}
+
+func (c *canvas) appendOp(name string, op canvasOp) {
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", name))
+	}
+	c.ops = append(c.ops, op)
+}
+
+// guix.Canvas compliance
+func (c *canvas) Size() math.Size {
+	return c.sizeDips
+}
+
+func (c *canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+}
+
+func (c *canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp("Push", func(ctx *context, dss *drawStateStack) {
+		dss.push(*dss.head())
+	})
+}
+
+func (c *canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp("Pop", func(ctx *context, dss *drawStateStack) {
+		l := dss.head().Layer
+		dss.pop()
+		if l != dss.head().Layer {
+			ctx.popLayer(l)
+		}
+	})
+}
+
+func (c *canvas) PushOpacity(alpha float32) {
+	c.pushLayer("PushOpacity", alpha, guix.BlendNormal)
+}
+
+func (c *canvas) PushBlendMode(mode guix.BlendMode) {
+	c.pushLayer("PushBlendMode", 1, mode)
+}
+
+func (c *canvas) pushLayer(name string, alpha float32, mode guix.BlendMode) {
+	c.buildingPushCount++
+	c.appendOp(name, func(ctx *context, dss *drawStateStack) {
+		dss.push(*dss.head())
+		if alpha < 1 || mode != guix.BlendNormal {
+			ctx.pushLayer(dss.head(), alpha, mode)
+		}
+	})
+}
+
+func (c *canvas) AddClip(r math.Rect) {
+	c.appendOp("AddClip", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		ds.ClipPixels = ds.ClipPixels.Intersect(ctx.rectToPixels(r, ds))
+	})
+}
+
+func (c *canvas) Transform(m math.Mat3) {
+	c.appendOp("Transform", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		ds.Transform = m.Mul(ds.Transform)
+	})
+}
+
+func (c *canvas) Translate(x, y float32) {
+	c.Transform(math.CreateMat3Translate(math.Vec2{X: x, Y: y}))
+}
+
+func (c *canvas) Rotate(radians float32) {
+	c.Transform(math.CreateMat3Rotate(radians))
+}
+
+func (c *canvas) Scale(x, y float32) {
+	c.Transform(math.CreateMat3Scale(math.Vec2{X: x, Y: y}))
+}
+
+func (c *canvas) Clear(color guix.Color) {
+	c.appendOp("Clear", func(ctx *context, dss *drawStateStack) {
+		ctx.fillRectSrc(color, dss.head())
+	})
+}
+
+func (c *canvas) DrawCanvas(cc guix.Canvas, offsetDips math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvas", func(ctx *context, dss *drawStateStack) {
+		dss.push(*dss.head())
+		ds := dss.head()
+		if ds.Transform == math.Mat3Ident {
+			offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
+			ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
+		} else {
+			offset := math.CreateMat3Translate(offsetDips.Vec2())
+			ds.Transform = offset.Mul(ds.Transform)
+		}
+		childCanvas.draw(ctx, dss)
+		dss.pop()
+	})
+}
+
+func (c *canvas) DrawRunes(f guix.Font, r []rune, p []math.Point, col guix.Color) {
+	if f == nil {
+		panic("Font cannot be nil")
+	}
+	if family, ok := f.(*guix.FontFamily); ok {
+		for _, run := range family.Runs(r, p) {
+			c.DrawRunes(run.Font, run.Runes, run.Offsets, col)
+		}
+		return
+	}
+	runes := append([]rune{}, r...)
+	points := append([]math.Point{}, p...)
+	c.appendOp("DrawRunes", func(ctx *context, dss *drawStateStack) {
+		f.(*font).DrawRunes(ctx, runes, points, col, dss.head())
+	})
+}
+
+func (c *canvas) DrawLines(lines guix.Polygon, pen guix.Pen) {
+	lines = pruneDuplicates(lines)
+	c.appendOp("DrawLines", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if len(lines) < 2 || !pen.IsVisible() {
+			return
+		}
+		var path raster.Path
+		if pen.Dash != nil {
+			path = dashesToPath(ctx, pen.Dashes(flattenPoly(lines, false), false), ds)
+		} else {
+			path = openPolyToPath(ctx, lines, ds)
+		}
+		width := ctx.toFixedLength(pen.Width, ds)
+		mask := ctx.rasterize(0, ds, true, func(r *raster.Rasterizer) {
+			raster.Stroke(r, path, width, capper(pen), joiner(pen))
+		})
+		ctx.composite(mask, solid(pen.Color))
+	})
+}
+
+func (c *canvas) DrawPolygon(poly guix.Polygon, pen guix.Pen, brush guix.Brush) {
+	poly = pruneDuplicates(poly)
+	c.appendOp("DrawPolygon", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if len(poly) < 3 {
+			return
+		}
+		path := closedPolyToPath(ctx, poly, ds)
+		fill := ctx.rasterize(0, ds, true, func(r *raster.Rasterizer) {
+			r.AddPath(path)
+		})
+		ctx.composite(fill, ctx.source(brush, poly.Bounds(), ds))
+		if pen.IsVisible() {
+			// The edge is drawn inside the polygon, matching the gl driver.
+			// Stroke at twice the pen width and keep the inner half.
+			edgePath, cap := path, raster.RoundCapper
+			if pen.Dash != nil {
+				edgePath = dashesToPath(ctx, pen.Dashes(flattenPoly(poly, true), true), ds)
+				cap = capper(pen)
+			}
+			width := ctx.toFixedLength(pen.Width*2, ds)
+			edge := ctx.rasterize(1, ds, true, func(r *raster.Rasterizer) {
+				raster.Stroke(r, edgePath, width, cap, joiner(pen))
+			})
+			intersectMasks(edge, fill)
+			ctx.composite(edge, solid(pen.Color))
+		}
+	})
+}
+
+func (c *canvas) DrawPath(path *guix.Path2D, pen guix.Pen, brush guix.Brush) {
+	path = path.Clone()
+	c.appendOp("DrawPath", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		contours := flattenPath(ctx, path, ds)
+		if len(contours) == 0 {
+			return
+		}
+		if brush.IsVisible() {
+			fillPath := contoursToPath(ctx, contours, ds)
+			fill := ctx.rasterize(0, ds, path.FillRule == guix.NonZero, func(r *raster.Rasterizer) {
+				r.AddPath(fillPath)
+			})
+			ctx.composite(fill, ctx.source(brush, path.Bounds(), ds))
+		}
+		if pen.IsVisible() {
+			var dashes [][]math.Vec2
+			for _, c := range contours {
+				dashes = append(dashes, pen.Dashes(c.Points, c.Closed)...)
+			}
+			edgePath := dashesToPath(ctx, dashes, ds)
+			width := ctx.toFixedLength(pen.Width, ds)
+			edge := ctx.rasterize(0, ds, true, func(r *raster.Rasterizer) {
+				raster.Stroke(r, edgePath, width, capper(pen), joiner(pen))
+			})
+			ctx.composite(edge, solid(pen.Color))
+		}
+	})
+}
+
+func (c *canvas) DrawRect(r math.Rect, brush guix.Brush) {
+	c.appendOp("DrawRect", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if ds.Transform != math.Mat3Ident {
+			path := rectToPath(ctx, r, ds)
+			mask := ctx.rasterize(0, ds, true, func(rz *raster.Rasterizer) {
+				rz.AddPath(path)
+			})
+			ctx.composite(mask, ctx.source(brush, r, ds))
+			return
+		}
+		rect := ctx.resolution.rectDipsToPixels(r).Offset(ds.OriginPixels)
+		ctx.fillRect(rect, ctx.source(brush, r, ds), ds)
+	})
+}
+
+func (c *canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen guix.Pen, brush guix.Brush) {
+	if tl == 0 && tr == 0 && bl == 0 && br == 0 && pen.Color.A == 0 {
+		c.DrawRect(r, brush)
+		return
+	}
+	p := guix.Polygon{
+		guix.PolygonVertex{Position: r.TL(), RoundedRadius: tl},
+		guix.PolygonVertex{Position: r.TR(), RoundedRadius: tr},
+		guix.PolygonVertex{Position: r.BR(), RoundedRadius: br},
+		guix.PolygonVertex{Position: r.BL(), RoundedRadius: bl},
+	}
+	c.DrawPolygon(p, pen, brush)
+}
+
+func (c *canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s guix.Shadow) {
+	c.appendOp("DrawShadow", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if !s.IsVisible() {
+			return
+		}
+		path := rectToPath(ctx, s.Bounds(r), ds)
+		mask := ctx.rasterize(0, ds, true, func(rz *raster.Rasterizer) {
+			rz.AddPath(path)
+		})
+		ctx.composite(mask, &shadow{s, r, tl, tr, bl, br, ctx.toLocal(ds)})
+	})
+}
+
+func (c *canvas) DrawTexture(t guix.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+
+	c.appendOp("DrawTexture", func(ctx *context, dss *drawStateStack) {
+		t.(*texture).draw(ctx, r, dss.head())
+	})
+}
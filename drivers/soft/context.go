@@ -0,0 +1,235 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/golang/freetype/raster"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"golang.org/x/image/math/fixed"
+)
+
+// context holds the render target and scratch buffers of a viewport.
+type context struct {
+	target               *image.RGBA
+	coverage             [2]*image.Alpha // scratch coverage masks, sized as target
+	rasterizer           *raster.Rasterizer
+	resolution           resolution
+	sizeDips, sizePixels math.Size
+}
+
+func newContext() *context {
+	return &context{
+		target: image.NewRGBA(image.Rectangle{}),
+		coverage: [2]*image.Alpha{
+			image.NewAlpha(image.Rectangle{}),
+			image.NewAlpha(image.Rectangle{}),
+		},
+		rasterizer: raster.NewRasterizer(0, 0),
+	}
+}
+
+// resize reallocates the render target to the given size in pixels.
+func (c *context) resize(sizePixels math.Size) {
+	if c.target.Rect.Dx() == sizePixels.W && c.target.Rect.Dy() == sizePixels.H {
+		return
+	}
+	r := image.Rect(0, 0, sizePixels.W, sizePixels.H)
+	c.target = image.NewRGBA(r)
+	c.coverage[0] = image.NewAlpha(r)
+	c.coverage[1] = image.NewAlpha(r)
+	c.rasterizer.SetBounds(sizePixels.W, sizePixels.H)
+}
+
+func (c *context) beginDraw(sizeDips, sizePixels math.Size) {
+	c.resize(sizePixels)
+	dipsToPixels := float32(1)
+	if sizeDips.W > 0 {
+		dipsToPixels = float32(sizePixels.W) / float32(sizeDips.W)
+	}
+	c.sizeDips = sizeDips
+	c.sizePixels = sizePixels
+	c.resolution = resolution(dipsToPixels*65536 + 0.5)
+}
+
+// clear fills the entire render target with col, ignoring any clip.
+func (c *context) clear(col guix.Color) {
+	draw.Draw(c.target, c.target.Rect, image.NewUniform(rgba(col)), image.Point{}, draw.Src)
+}
+
+// fillRectSrc replaces the pixels within the clip of ds with col.
+func (c *context) fillRectSrc(col guix.Color, ds *drawState) {
+	draw.Draw(c.target, c.clipRect(ds), image.NewUniform(rgba(col)), image.Point{}, draw.Src)
+}
+
+// clipRect returns the clip rectangle of ds constrained to the render target.
+func (c *context) clipRect(ds *drawState) image.Rectangle {
+	return ds.ClipPixels.Intersect(c.target.Rect)
+}
+
+// Limit in pixels of window-space coordinates passed to the rasterizer.
+// Coordinates far outside of the render target are clamped to this limit so
+// that the rasterizer does not have to walk enormous or invalid edges.
+const maxCoordinate = 1 << 16
+
+// toPixels transforms the local-space point p, in DIPs, to window-space
+// pixels.
+func (c *context) toPixels(p math.Vec2, ds *drawState) math.Vec2 {
+	p = ds.Transform.Transform(p)
+	s := c.resolution.dipsToPixels()
+	o := ds.OriginPixels
+	return math.Vec2{X: p.X*s + float32(o.X), Y: p.Y*s + float32(o.Y)}
+}
+
+// toFixed transforms the local-space point p, in DIPs, to a window-space
+// point in 26.6 fixed-point pixels.
+func (c *context) toFixed(p math.Vec2, ds *drawState) fixed.Point26_6 {
+	p = c.toPixels(p, ds)
+	return fixed.Point26_6{
+		X: toFixedCoordinate(p.X),
+		Y: toFixedCoordinate(p.Y),
+	}
+}
+
+// toLocal returns the matrix that transforms window-space pixels to the
+// local space of ds, in DIPs.
+func (c *context) toLocal(ds *drawState) math.Mat3 {
+	o := ds.OriginPixels
+	toWindow := ds.Transform.
+		Mul(math.CreateMat3Scale(math.Vec2{X: c.resolution.dipsToPixels(), Y: c.resolution.dipsToPixels()})).
+		Mul(math.CreateMat3Translate(math.Vec2{X: float32(o.X), Y: float32(o.Y)}))
+	return toWindow.Invert()
+}
+
+// rectToPixels returns the window-space pixel rectangle covering the
+// local-space rectangle r, in DIPs.
+func (c *context) rectToPixels(r math.Rect, ds *drawState) image.Rectangle {
+	if ds.Transform == math.Mat3Ident {
+		return imageRect(c.resolution.rectDipsToPixels(r).Offset(ds.OriginPixels))
+	}
+	min := math.Vec2{X: maxCoordinate, Y: maxCoordinate}
+	max := min.Neg()
+	for _, p := range []math.Point{r.TL(), r.TR(), r.BR(), r.BL()} {
+		p := c.toPixels(p.Vec2(), ds)
+		min = math.Vec2{X: math.Minf(min.X, p.X), Y: math.Minf(min.Y, p.Y)}
+		max = math.Vec2{X: math.Maxf(max.X, p.X), Y: math.Maxf(max.Y, p.Y)}
+	}
+	return image.Rect(
+		int(math.Floorf(math.Clampf(min.X, -maxCoordinate, maxCoordinate))),
+		int(math.Floorf(math.Clampf(min.Y, -maxCoordinate, maxCoordinate))),
+		int(math.Ceilf(math.Clampf(max.X, -maxCoordinate, maxCoordinate))),
+		int(math.Ceilf(math.Clampf(max.Y, -maxCoordinate, maxCoordinate))),
+	)
+}
+
+func toFixedCoordinate(v float32) fixed.Int26_6 {
+	if v != v { // NaN
+		v = 0
+	}
+	return fixed.Int26_6(math.Floorf(math.Clampf(v, -maxCoordinate, maxCoordinate)*64 + 0.5))
+}
+
+// toFixedLength converts the local-space length l, in DIPs, to 26.6
+// fixed-point pixels. Transforms that do not scale uniformly are
+// approximated by their average scale.
+func (c *context) toFixedLength(l float32, ds *drawState) fixed.Int26_6 {
+	return fixed.Int26_6(l * ds.Transform.Scale() * c.resolution.dipsToPixels() * 64)
+}
+
+// rasterize clears coverage mask i within the clip of ds, calls add to build
+// a path with the rasterizer and then rasterizes the path into the mask.
+// The returned mask is bounded by the clip.
+func (c *context) rasterize(i int, ds *drawState, nonZero bool, add func(r *raster.Rasterizer)) *image.Alpha {
+	clip := c.clipRect(ds)
+	mask := c.coverage[i].SubImage(clip).(*image.Alpha)
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		row := mask.Pix[mask.PixOffset(clip.Min.X, y):mask.PixOffset(clip.Max.X, y)]
+		for x := range row {
+			row[x] = 0
+		}
+	}
+	if clip.Empty() {
+		return mask
+	}
+	r := c.rasterizer
+	r.Clear()
+	r.UseNonZeroWinding = nonZero
+	add(r)
+	r.Rasterize(raster.NewAlphaSrcPainter(mask))
+	return mask
+}
+
+// intersectMasks multiplies the coverage of a by b, storing the result in a.
+func intersectMasks(a, b *image.Alpha) {
+	r := a.Rect.Intersect(b.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			i, j := a.PixOffset(x, y), b.PixOffset(x, y)
+			a.Pix[i] = uint8((uint32(a.Pix[i])*uint32(b.Pix[j]) + 127) / 255)
+		}
+	}
+}
+
+// solid returns the source image for painting col, or nil if col is fully
+// transparent.
+func solid(col guix.Color) image.Image {
+	if col.A <= 0 {
+		return nil
+	}
+	return image.NewUniform(rgba(col))
+}
+
+// source returns the source image for painting the brush b over a shape with
+// the local-space bounds r, in DIPs, or nil if b paints nothing.
+func (c *context) source(b guix.Brush, r math.Rect, ds *drawState) image.Image {
+	if b.Gradient == nil {
+		return solid(b.Color)
+	}
+	if !b.IsVisible() {
+		return nil
+	}
+	w, h := float32(math.Max(r.W(), 1)), float32(math.Max(r.H(), 1))
+	toUnit := c.toLocal(ds).
+		Mul(math.CreateMat3Translate(r.Min.Vec2().Neg())).
+		Mul(math.CreateMat3Scale(math.Vec2{X: 1 / w, Y: 1 / h}))
+	return &gradient{gradient: b.Gradient, toUnit: toUnit}
+}
+
+// composite blends src over the render target through mask.
+func (c *context) composite(mask *image.Alpha, src image.Image) {
+	if src == nil {
+		return
+	}
+	r := mask.Rect
+	draw.DrawMask(c.target, r, src, r.Min, mask, r.Min, draw.Over)
+}
+
+// fillRect blends src over the window-space pixel rectangle r, clipped by ds.
+func (c *context) fillRect(r math.Rect, src image.Image, ds *drawState) {
+	if src == nil {
+		return
+	}
+	dst := imageRect(r).Intersect(c.clipRect(ds))
+	draw.Draw(c.target, dst, src, dst.Min, draw.Over)
+}
+
+// rgba converts the non-premultiplied color c to a premultiplied color.RGBA.
+func rgba(c guix.Color) color.RGBA {
+	c = c.Saturate()
+	return color.RGBA{
+		R: uint8(c.R*c.A*255 + 0.5),
+		G: uint8(c.G*c.A*255 + 0.5),
+		B: uint8(c.B*c.A*255 + 0.5),
+		A: uint8(c.A*255 + 0.5),
+	}
+}
+
+func imageRect(r math.Rect) image.Rectangle {
+	return image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+}
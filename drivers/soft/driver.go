@@ -0,0 +1,216 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package soft contains a pure-Go, software rasterizing implementation of the
+// guix.Driver interface. It requires no display or graphics hardware, which
+// makes it suitable for tests, continuous integration and off-screen
+// rendering. Viewports rasterize into an image.RGBA that can be retrieved with
+// Viewport.Frame.
+package soft
+
+import (
+	"container/list"
+	"image"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Maximum time allowed for application to process events on termination.
+const maxFlushTime = time.Second * 3
+
+// Size in pixels of the virtual screen used for fullscreen viewports created
+// with a zero width or height.
+var ScreenSize = math.Size{W: 1920, H: 1080}
+
+type driver struct {
+	pendingDriver chan func()
+	pendingApp    chan func()
+	terminated    int32 // non-zero represents driver terminations
+	viewports     *list.List
+
+	clipboardLock sync.Mutex
+	clipboard     string
+
+	pcs  []uintptr // reusable scratch-buffer for use by runtime.Callers.
+	uiPC uintptr   // the program-counter of the applicationLoop function.
+}
+
+// StartDriver starts the soft driver with the given appRoutine.
+// StartDriver blocks until Driver.Terminate is called.
+func StartDriver(appRoutine func(driver guix.Driver)) {
+	if runtime.GOMAXPROCS(-1) < 2 {
+		runtime.GOMAXPROCS(2)
+	}
+
+	driver := &driver{
+		pendingDriver: make(chan func(), 256),
+		pendingApp:    make(chan func(), 256),
+		viewports:     list.New(),
+		pcs:           make([]uintptr, 256),
+	}
+
+	driver.pendingApp <- driver.discoverUIGoRoutine
+	driver.pendingApp <- func() { appRoutine(driver) }
+	go driver.applicationLoop()
+	driver.driverLoop()
+}
+
+func (d *driver) asyncDriver(f func()) {
+	d.pendingDriver <- f
+}
+
+func (d *driver) syncDriver(f func()) {
+	c := make(chan bool, 1)
+	d.asyncDriver(func() { f(); c <- true })
+	<-c
+}
+
+func (d *driver) createDriverEvent(signature interface{}) guix.Event {
+	return guix.CreateChanneledEvent(signature, d.pendingDriver)
+}
+
+func (d *driver) createAppEvent(signature interface{}) guix.Event {
+	return guix.CreateChanneledEvent(signature, d.pendingApp)
+}
+
+// driverLoop pulls and executes funcs from the pendingDriver chan until the
+// chan is closed. Unlike the gl driver, there are no platform events to wait
+// on, so the driver routine simply blocks on the chan.
+func (d *driver) driverLoop() {
+	for ev := range d.pendingDriver {
+		ev()
+	}
+}
+
+// applicationLoop pulls and executes funcs from the pendingApp chan until
+// the chan is closed.
+func (d *driver) applicationLoop() {
+	for ev := range d.pendingApp {
+		ev()
+	}
+}
+
+// guix.Driver compliance
+func (d *driver) Call(f func()) bool {
+	if f == nil {
+		panic("Function must not be nil")
+	}
+	if atomic.LoadInt32(&d.terminated) != 0 {
+		return false // Driver.Terminate has been called
+	}
+	d.pendingApp <- f
+	return true
+}
+
+func (d *driver) CallSync(f func()) bool {
+	c := make(chan struct{})
+	if d.Call(func() { f(); close(c) }) {
+		<-c
+		return true
+	}
+	return false
+}
+
+func (d *driver) Terminate() {
+	d.asyncDriver(func() {
+		// Close all viewports. This will notify the application.
+		for v := d.viewports.Front(); v != nil; v = v.Next() {
+			v.Value.(*viewport).Destroy()
+		}
+
+		// Flush all remaining events from the application and driver.
+		// This gives the application an opportunity to handle shutdown.
+		flushStart := time.Now()
+		for time.Since(flushStart) < maxFlushTime {
+			done := true
+
+			// Process any application events
+			sync := make(chan struct{})
+			d.Call(func() {
+				select {
+				case ev := <-d.pendingApp:
+					ev()
+					done = false
+				default:
+				}
+				close(sync)
+			})
+			<-sync
+
+			// Process any driver events
+			select {
+			case ev := <-d.pendingDriver:
+				ev()
+				done = false
+			default:
+			}
+
+			if done {
+				break
+			}
+		}
+
+		// All done.
+		atomic.StoreInt32(&d.terminated, 1)
+		close(d.pendingApp)
+		close(d.pendingDriver)
+
+		d.viewports = nil
+	})
+}
+
+// SetClipboard stores str in a process-local clipboard. The soft driver has no
+// access to the system clipboard.
+func (d *driver) SetClipboard(str string) {
+	d.clipboardLock.Lock()
+	d.clipboard = str
+	d.clipboardLock.Unlock()
+}
+
+func (d *driver) GetClipboard() (string, error) {
+	d.clipboardLock.Lock()
+	defer d.clipboardLock.Unlock()
+	return d.clipboard, nil
+}
+
+func (d *driver) CreateFont(data []byte, size int) (guix.Font, error) {
+	return newFont(data, size)
+}
+
+func (d *driver) CreateWindowedViewport(width, height int, name string) guix.Viewport {
+	var v *viewport
+	d.syncDriver(func() {
+		v = newViewport(d, width, height, name, false)
+		e := d.viewports.PushBack(v)
+		v.onDestroy.Listen(func() {
+			d.viewports.Remove(e)
+		})
+	})
+	return v
+}
+
+func (d *driver) CreateFullscreenViewport(width, height int, name string) guix.Viewport {
+	var v *viewport
+	d.syncDriver(func() {
+		v = newViewport(d, width, height, name, true)
+		e := d.viewports.PushBack(v)
+		v.onDestroy.Listen(func() {
+			d.viewports.Remove(e)
+		})
+	})
+	return v
+}
+
+func (d *driver) CreateCanvas(s math.Size) guix.Canvas {
+	return newCanvas(s)
+}
+
+func (d *driver) CreateTexture(img image.Image, pixelsPerDip float32) guix.Texture {
+	return newTexture(img, pixelsPerDip)
+}
@@ -0,0 +1,341 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/gxfont"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/themes/dark"
+)
+
+// run starts the driver and calls f on a new go-routine, terminating the
+// driver once f returns.
+func run(t *testing.T, f func(driver guix.Driver)) {
+	StartDriver(func(driver guix.Driver) {
+		go func() {
+			defer driver.Terminate()
+			f(driver)
+		}()
+	})
+}
+
+func viewportOf(w guix.Window) Viewport {
+	return w.(interface {
+		Viewport() guix.Viewport
+	}).Viewport().(Viewport)
+}
+
+func TestCanvasDrawRect(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(4, 4, "test")
+		c := driver.CreateCanvas(math.Size{W: 4, H: 4})
+		c.Clear(guix.Black)
+		c.DrawRect(math.CreateRect(1, 1, 3, 3), guix.CreateBrush(guix.Red))
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		if got := img.RGBAAt(0, 0); got != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("Pixel (0, 0): expected black, got %v", got)
+		}
+		if got := img.RGBAAt(1, 1); got != (color.RGBA{255, 0, 0, 255}) {
+			t.Errorf("Pixel (1, 1): expected red, got %v", got)
+		}
+		if got := img.RGBAAt(3, 3); got != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("Pixel (3, 3): expected black, got %v", got)
+		}
+	})
+}
+
+func TestCanvasClip(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(8, 8, "test")
+		c := driver.CreateCanvas(math.Size{W: 8, H: 8})
+		c.Clear(guix.Black)
+		c.Push()
+		c.AddClip(math.CreateRect(0, 0, 4, 8))
+		c.AddClip(math.CreateRect(6, 0, 8, 8)) // Disjoint, nothing visible
+		c.DrawRect(math.CreateRect(0, 0, 8, 8), guix.WhiteBrush)
+		c.Pop()
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for x := 0; x < 8; x++ {
+			if got := img.RGBAAt(x, 4); got != (color.RGBA{0, 0, 0, 255}) {
+				t.Errorf("Pixel (%d, 4): expected black, got %v", x, got)
+			}
+		}
+	})
+}
+
+func TestCanvasGradient(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(8, 4, "test")
+		c := driver.CreateCanvas(math.Size{W: 8, H: 4})
+		c.Clear(guix.Black)
+		c.DrawRect(math.CreateRect(0, 0, 8, 2), guix.CreateLinearGradientBrush(
+			math.Vec2{X: 0, Y: 0}, math.Vec2{X: 1, Y: 0},
+			guix.GradientStop{Offset: 0, Color: guix.Black},
+			guix.GradientStop{Offset: 1, Color: guix.White},
+		))
+		reflect := guix.CreateLinearGradientBrush(
+			math.Vec2{X: 0, Y: 0}, math.Vec2{X: 0.5, Y: 0},
+			guix.GradientStop{Offset: 0, Color: guix.Red},
+			guix.GradientStop{Offset: 1, Color: guix.Blue},
+		)
+		reflect.Gradient.Repeat = guix.RepeatReflect
+		c.DrawRoundedRect(math.CreateRect(0, 2, 8, 4), 0, 0, 0, 0, guix.CreatePen(0.1, guix.Transparent), reflect)
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for x := 1; x < 8; x++ {
+			if a, b := img.RGBAAt(x-1, 0), img.RGBAAt(x, 0); a.R >= b.R {
+				t.Errorf("Pixel (%d, 0): expected brighter than %v, got %v", x, a, b)
+			}
+		}
+		// The reflected gradient is symmetrical about the center.
+		for x := 0; x < 4; x++ {
+			if a, b := img.RGBAAt(x, 3), img.RGBAAt(7-x, 3); a != b {
+				t.Errorf("Pixels (%d, 3) and (%d, 3) differ: %v, %v", x, 7-x, a, b)
+			}
+		}
+		if got := img.RGBAAt(0, 3); got.R < 200 || got.B > 55 {
+			t.Errorf("Pixel (0, 3): expected red, got %v", got)
+		}
+		if got := img.RGBAAt(3, 3); got.R > 55 || got.B < 200 {
+			t.Errorf("Pixel (3, 3): expected blue, got %v", got)
+		}
+	})
+}
+
+func TestCanvasDashedLine(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(16, 4, "test")
+		c := driver.CreateCanvas(math.Size{W: 16, H: 4})
+		c.Clear(guix.Black)
+		c.DrawLines(guix.Polygon{
+			guix.PolygonVertex{Position: math.Point{X: 0, Y: 2}},
+			guix.PolygonVertex{Position: math.Point{X: 16, Y: 2}},
+		}, guix.CreateDashedPen(2, guix.White, 4, 4))
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for x := 0; x < 16; x++ {
+			got := img.RGBAAt(x, 1)
+			if dash := (x/4)%2 == 0; dash && got.R < 200 {
+				t.Errorf("Pixel (%d, 1): expected white, got %v", x, got)
+			} else if !dash && got.R > 55 {
+				t.Errorf("Pixel (%d, 1): expected black, got %v", x, got)
+			}
+		}
+	})
+}
+
+func TestCanvasDrawPath(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(24, 12, "test")
+		c := driver.CreateCanvas(math.Size{W: 24, H: 12})
+		c.Clear(guix.Black)
+		// Two squares, each with a nested square in the same direction.
+		p := &guix.Path2D{}
+		p.AddRect(math.CreateRect(0, 0, 12, 12))
+		p.AddRect(math.CreateRect(4, 4, 8, 8))
+		c.DrawPath(p, guix.TransparentPen, guix.CreateBrush(guix.White))
+		p = &guix.Path2D{FillRule: guix.EvenOdd}
+		p.AddRect(math.CreateRect(12, 0, 24, 12))
+		p.AddRect(math.CreateRect(16, 4, 20, 8))
+		c.DrawPath(p, guix.TransparentPen, guix.CreateBrush(guix.White))
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for _, e := range []struct {
+			x, y int
+			r    uint8
+		}{
+			{2, 2, 255}, {6, 6, 255}, // Non-zero fills the inner square
+			{14, 2, 255}, {18, 6, 0}, // Even-odd makes a hole
+		} {
+			if got := img.RGBAAt(e.x, e.y).R; got != e.r {
+				t.Errorf("Pixel (%d, %d): expected red %d, got %d", e.x, e.y, e.r, got)
+			}
+		}
+	})
+}
+
+func TestCanvasDrawShadow(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(32, 16, "test")
+		c := driver.CreateCanvas(math.Size{W: 32, H: 16})
+		c.Clear(guix.White)
+		c.DrawShadow(math.CreateRect(0, 0, 16, 16), 0, 0, 0, 0,
+			guix.CreateShadow(math.Vec2{X: 8}, 4, 0, guix.Black))
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for _, e := range []struct {
+			x  int
+			lo uint8
+			hi uint8
+		}{
+			{12, 0, 8},     // Beneath the shadow
+			{24, 140, 166}, // Just beyond the edge of the shadow
+			{31, 240, 255}, // Beyond the shadow
+		} {
+			if got := img.RGBAAt(e.x, 8).R; got < e.lo || got > e.hi {
+				t.Errorf("Pixel (%d, 8): expected red between %d and %d, got %d", e.x, e.lo, e.hi, got)
+			}
+		}
+	})
+}
+
+func TestCanvasTransform(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(8, 8, "test")
+		child := driver.CreateCanvas(math.Size{W: 2, H: 2})
+		child.DrawRect(math.CreateRect(0, 0, 2, 2), guix.CreateBrush(guix.Green))
+		child.Complete()
+
+		c := driver.CreateCanvas(math.Size{W: 8, H: 8})
+		c.Clear(guix.Black)
+		c.Push()
+		c.Translate(4, 4)
+		c.Rotate(math.Pi / 2)
+		c.DrawRect(math.CreateRect(0, 0, 4, 2), guix.CreateBrush(guix.Red))
+		c.Pop()
+		c.Push()
+		c.Scale(2, 2)
+		c.DrawCanvas(child, math.Point{X: 2, Y: 0})
+		c.Pop()
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for _, p := range []struct {
+			x, y     int
+			expected color.RGBA
+		}{
+			{3, 6, color.RGBA{255, 0, 0, 255}}, // Rotated clockwise about (4, 4)
+			{2, 5, color.RGBA{255, 0, 0, 255}},
+			{5, 5, color.RGBA{0, 0, 0, 255}},
+			{1, 6, color.RGBA{0, 0, 0, 255}},
+			{5, 1, color.RGBA{0, 255, 0, 255}}, // Scaled child canvas
+			{7, 3, color.RGBA{0, 255, 0, 255}},
+			{3, 1, color.RGBA{0, 0, 0, 255}},
+		} {
+			if got := img.RGBAAt(p.x, p.y); got != p.expected {
+				t.Errorf("Pixel (%d, %d): expected %v, got %v", p.x, p.y, p.expected, got)
+			}
+		}
+	})
+}
+
+func TestFontCoverageGamma(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		font, err := driver.CreateFont(gxfont.Default, 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// coverage returns the sum of the red channel of white text drawn on
+		// black.
+		coverage := func() int {
+			v := driver.CreateWindowedViewport(40, 20, "test")
+			c := driver.CreateCanvas(math.Size{W: 40, H: 20})
+			c.Clear(guix.Black)
+			runes := []rune("Wave")
+			c.DrawRunes(font, runes, font.Layout(&guix.TextBlock{Runes: runes}), guix.White)
+			c.Complete()
+			v.SetCanvas(c)
+			img := v.(Viewport).Frame()
+			sum := 0
+			for i := 0; i < len(img.Pix); i += 4 {
+				sum += int(img.Pix[i])
+			}
+			return sum
+		}
+		r := font.(guix.FontRenderer)
+		if r.CoverageGamma() != guix.DefaultCoverageGamma {
+			t.Errorf("Expected the default gamma, got %v", r.CoverageGamma())
+		}
+		linear := coverage()
+		r.SetCoverageGamma(2.2)
+		if corrected := coverage(); corrected <= linear {
+			t.Errorf("Expected gamma correction to increase coverage. Got %d, linear %d", corrected, linear)
+		}
+		r.SetCoverageGamma(1)
+		if got := coverage(); got != linear {
+			t.Errorf("Expected a gamma of 1 to be linear. Got %d, linear %d", got, linear)
+		}
+	})
+}
+
+func TestCanvasLayers(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		v := driver.CreateWindowedViewport(4, 1, "test")
+		c := driver.CreateCanvas(math.Size{W: 4, H: 1})
+		c.Clear(guix.Gray50)
+		// Overlapping rectangles in a faded layer are faded as one.
+		c.PushOpacity(0.5)
+		c.DrawRect(math.CreateRect(0, 0, 2, 1), guix.CreateBrush(guix.White))
+		c.DrawRect(math.CreateRect(1, 0, 2, 1), guix.CreateBrush(guix.White))
+		c.Pop()
+		c.PushBlendMode(guix.BlendMultiply)
+		c.DrawRect(math.CreateRect(2, 0, 3, 1), guix.CreateBrush(guix.Gray50))
+		c.Pop()
+		c.PushBlendMode(guix.BlendAdditive)
+		c.DrawRect(math.CreateRect(3, 0, 4, 1), guix.CreateBrush(guix.Gray50))
+		c.Pop()
+		c.Complete()
+		v.SetCanvas(c)
+
+		img := v.(Viewport).Frame()
+		for x, expected := range []uint8{192, 192, 64, 255} {
+			if got := img.RGBAAt(x, 0); got.R != expected || got.A != 255 {
+				t.Errorf("Pixel (%d, 0): expected red %d, got %v", x, expected, got)
+			}
+		}
+	})
+}
+
+func TestWindowRender(t *testing.T) {
+	run(t, func(driver guix.Driver) {
+		var window guix.Window
+		driver.CallSync(func() {
+			theme := dark.CreateTheme(driver)
+			window = theme.CreateWindow(80, 40, "test")
+			label := theme.CreateLabel()
+			label.SetText("Hello")
+			window.AddChild(label)
+		})
+		driver.CallSync(func() {}) // Flush the pending layout and draw
+
+		v := viewportOf(window)
+		if v.FrameCount() == 0 {
+			t.Errorf("Expected the window to be rendered")
+			return
+		}
+		img := v.Frame()
+		if s := img.Bounds().Size(); s.X != 80 || s.Y != 40 {
+			t.Errorf("Expected frame of size 80x40, got %v", s)
+		}
+		lit := 0
+		for i := 0; i < len(img.Pix); i += 4 {
+			if img.Pix[i] > 0x80 {
+				lit++
+			}
+		}
+		if lit == 0 {
+			t.Errorf("Expected the label text to be rendered")
+		}
+	})
+}
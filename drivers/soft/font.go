@@ -0,0 +1,341 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+	"unicode"
+
+	"github.com/golang/freetype/raster"
+	"github.com/golang/freetype/truetype"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	fnt "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+type font struct {
+	sync.Mutex // guards faces, glyphAdvanceDips and the rendering settings
+
+	size             int
+	scale            fixed.Int26_6
+	glyphMaxSizeDips math.Size
+	ascentDips       int
+	ttf              *truetype.Font
+	faces            map[resolution]fnt.Face
+	glyphAdvanceDips map[rune]int
+	glyphMode        guix.GlyphMode
+	gamma            float32
+	gammaTable       *[256]uint8 // Maps coverage to gamma corrected coverage, or nil
+}
+
+func point26_6toPoint(p fixed.Point26_6) math.Point {
+	return math.Point{X: int(p.X) >> 6, Y: int(p.Y) >> 6}
+}
+
+func rectangle26_6toRect(p fixed.Rectangle26_6) math.Rect {
+	return math.Rect{Min: point26_6toPoint(p.Min), Max: point26_6toPoint(p.Max)}
+}
+
+func newFont(data []byte, size int) (*font, error) {
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := fixed.Int26_6(size << 6)
+	bounds := rectangle26_6toRect(ttf.Bounds(scale))
+	ascentDips := bounds.Max.Y
+
+	return &font{
+		size:             size,
+		scale:            scale,
+		glyphMaxSizeDips: bounds.Size(),
+		ascentDips:       ascentDips,
+		ttf:              ttf,
+		faces:            make(map[resolution]fnt.Face),
+		glyphAdvanceDips: make(map[rune]int),
+		gamma:            guix.DefaultCoverageGamma,
+	}, nil
+}
+
+func (f *font) advanceDips(r rune) int {
+	f.Lock()
+	defer f.Unlock()
+	if g, found := f.glyphAdvanceDips[r]; found {
+		return g
+	}
+	idx := f.ttf.Index(r)
+	gb := &truetype.GlyphBuf{}
+	err := gb.Load(f.ttf, f.scale, idx, fnt.HintingFull)
+	if err != nil {
+		panic(err)
+	}
+
+	advance := int((gb.AdvanceWidth + 0x3f) >> 6)
+	f.glyphAdvanceDips[r] = advance
+	return advance
+}
+
+func (f *font) face(resolution resolution) fnt.Face {
+	f.Lock()
+	defer f.Unlock()
+	face, found := f.faces[resolution]
+	if !found {
+		opt := truetype.Options{
+			Size:    float64(f.size),
+			DPI:     float64(resolution.intDipsToPixels(72)),
+			Hinting: fnt.HintingFull,
+		}
+		face = truetype.NewFace(f.ttf, &opt)
+		f.faces[resolution] = face
+	}
+	return face
+}
+
+func (f *font) align(rect math.Rect, size math.Size, ascent int, h guix.HorizontalAlignment, v guix.VerticalAlignment) math.Point {
+	var origin math.Point
+	switch h {
+	case guix.AlignLeft:
+		origin.X = rect.Min.X
+	case guix.AlignCenter:
+		origin.X = rect.Mid().X - (size.W / 2)
+	case guix.AlignRight:
+		origin.X = rect.Max.X - size.W
+	}
+	switch v {
+	case guix.AlignTop:
+		origin.Y = rect.Min.Y + ascent
+	case guix.AlignMiddle:
+		origin.Y = rect.Mid().Y - (size.H / 2) + ascent
+	case guix.AlignBottom:
+		origin.Y = rect.Max.Y - size.H + ascent
+	}
+	return origin
+}
+
+func (f *font) DrawRunes(ctx *context, runes []rune, offsets []math.Point, col guix.Color, ds *drawState) {
+	if len(runes) != len(offsets) {
+		panic(fmt.Errorf("There must be the same number of runes to offsets. Got %d runes and %d offsets",
+			len(runes), len(offsets)))
+	}
+	if col.A <= 0 {
+		return
+	}
+	if ds.Transform != math.Mat3Ident {
+		f.drawTransformedRunes(ctx, guix.ShapeGlyphs(runes, f), offsets, col, ds)
+		return
+	}
+	runes = guix.ShapeGlyphs(runes, f)
+	resolution := ctx.resolution
+	face := f.face(resolution)
+	src := image.NewUniform(rgba(col))
+	clip := ctx.clipRect(ds)
+
+	f.Lock()
+	defer f.Unlock()
+	for i, r := range runes {
+		if r == 0 || unicode.IsSpace(r) {
+			continue
+		}
+		p := resolution.pointDipsToPixels(offsets[i]).Add(ds.OriginPixels)
+		dot := fixed.P(p.X, p.Y)
+		dr, mask, maskp, _, ok := face.Glyph(dot, r)
+		if !ok {
+			continue
+		}
+		dst := dr.Intersect(clip)
+		if dst.Empty() {
+			continue
+		}
+		maskp = maskp.Add(dst.Min.Sub(dr.Min))
+		if f.gammaTable != nil {
+			// The mask belongs to the face, so correct a copy.
+			m := image.NewAlpha(dst)
+			draw.Draw(m, dst, mask, maskp, draw.Src)
+			correctGamma(m, f.gammaTable)
+			mask, maskp = m, dst.Min
+		}
+		draw.DrawMask(ctx.target, dst, src, image.Point{}, mask, maskp, draw.Over)
+	}
+}
+
+// drawTransformedRunes draws the runes when the transform of ds is not the
+// identity. The glyph outlines are transformed and rasterized, as the cached
+// glyph masks of the face can only be drawn axis-aligned.
+func (f *font) drawTransformedRunes(ctx *context, runes []rune, offsets []math.Point, col guix.Color, ds *drawState) {
+	f.Lock()
+	defer f.Unlock()
+	gb := &truetype.GlyphBuf{}
+	mask := ctx.rasterize(0, ds, true, func(r *raster.Rasterizer) {
+		for i, ru := range runes {
+			if ru == 0 || unicode.IsSpace(ru) {
+				continue
+			}
+			if err := gb.Load(f.ttf, f.scale, f.ttf.Index(ru), fnt.HintingNone); err != nil {
+				continue
+			}
+			origin := offsets[i].Vec2()
+			start := 0
+			for _, end := range gb.Ends {
+				addContour(r, ctx, gb.Points[start:end], origin, ds)
+				start = end
+			}
+		}
+	})
+	if f.gammaTable != nil {
+		correctGamma(mask, f.gammaTable)
+	}
+	ctx.composite(mask, solid(col))
+}
+
+// correctGamma maps the coverage of each pixel of mask through table.
+func correctGamma(mask *image.Alpha, table *[256]uint8) {
+	r := mask.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := mask.Pix[mask.PixOffset(r.Min.X, y):mask.PixOffset(r.Max.X, y)]
+		for x, a := range row {
+			row[x] = table[a]
+		}
+	}
+}
+
+// addContour adds the closed glyph contour ps, positioned with its baseline
+// origin at the local-space point origin in DIPs, to the rasterizer. Glyph
+// points are in 26.6 fixed-point DIPs with the Y axis pointing up.
+func addContour(r *raster.Rasterizer, ctx *context, ps []truetype.Point, origin math.Vec2, ds *drawState) {
+	if len(ps) == 0 {
+		return
+	}
+	pt := func(p truetype.Point) math.Vec2 {
+		return math.Vec2{X: origin.X + float32(p.X)/64, Y: origin.Y - float32(p.Y)/64}
+	}
+	// The low bit of each point's flags is set if the point is on the curve.
+	// Two consecutive off-curve points imply an on-curve point midway between
+	// them.
+	onCurve := func(p truetype.Point) bool { return p.Flags&0x01 != 0 }
+	first, last := ps[0], ps[len(ps)-1]
+	start, others := pt(first), ps[1:]
+	if !onCurve(first) {
+		if onCurve(last) {
+			start, others = pt(last), ps[:len(ps)-1]
+		} else {
+			start, others = start.Add(pt(last)).MulS(0.5), ps
+		}
+	}
+	r.Start(ctx.toFixed(start, ds))
+	q0, on0 := start, true
+	for _, p := range others {
+		q, on := pt(p), onCurve(p)
+		if on {
+			if on0 {
+				r.Add1(ctx.toFixed(q, ds))
+			} else {
+				r.Add2(ctx.toFixed(q0, ds), ctx.toFixed(q, ds))
+			}
+		} else if !on0 {
+			mid := q0.Add(q).MulS(0.5)
+			r.Add2(ctx.toFixed(q0, ds), ctx.toFixed(mid, ds))
+		}
+		q0, on0 = q, on
+	}
+	// Close the contour
+	if on0 {
+		r.Add1(ctx.toFixed(start, ds))
+	} else {
+		r.Add2(ctx.toFixed(q0, ds), ctx.toFixed(start, ds))
+	}
+}
+
+// guix.FontRenderer compliance. Glyphs are rasterized from their outlines
+// at every resolution, so GlyphSDF fonts are drawn like GlyphBitmap fonts.
+func (f *font) GlyphMode() guix.GlyphMode {
+	f.Lock()
+	defer f.Unlock()
+	return f.glyphMode
+}
+
+func (f *font) SetGlyphMode(mode guix.GlyphMode) {
+	f.Lock()
+	defer f.Unlock()
+	f.glyphMode = mode
+}
+
+func (f *font) CoverageGamma() float32 {
+	f.Lock()
+	defer f.Unlock()
+	return f.gamma
+}
+
+func (f *font) SetCoverageGamma(gamma float32) {
+	if gamma <= 0 {
+		panic("Coverage gamma must be greater than 0")
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.gamma = gamma
+	f.gammaTable = nil
+	if gamma != 1 {
+		f.gammaTable = &[256]uint8{}
+		for i := range f.gammaTable {
+			f.gammaTable[i] = uint8(math.Powf(float32(i)/255, 1/gamma)*255 + 0.5)
+		}
+	}
+}
+
+func (f *font) Size() int {
+	return f.size
+}
+
+func (f *font) HasGlyph(r rune) bool {
+	return f.ttf.Index(r) != 0
+}
+
+func (f *font) Advance(r rune) int {
+	return f.advanceDips(r)
+}
+
+func (f *font) Kern(a, b rune) int {
+	k := f.ttf.Kern(f.scale, f.ttf.Index(a), f.ttf.Index(b))
+	return int((k + 0x20) >> 6)
+}
+
+func (f *font) Measure(fl *guix.TextBlock) math.Size {
+	return guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f).Size
+}
+
+func (f *font) Layout(fl *guix.TextBlock) (offsets []math.Point) {
+	t := guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f)
+	origin := f.align(fl.AlignRect, t.Size, f.ascentDips, fl.H, fl.V)
+	for i, p := range t.Offsets {
+		t.Offsets[i] = p.Add(origin)
+	}
+	return t.Offsets
+}
+
+func (f *font) Carets(fl *guix.TextBlock) []math.Point {
+	t := guix.ShapeTextBlock(fl, f.glyphMaxSizeDips.H, f)
+	origin := f.align(fl.AlignRect, t.Size, f.ascentDips, fl.H, fl.V)
+	for i, p := range t.Carets {
+		t.Carets[i] = p.Add(origin)
+	}
+	return t.Carets
+}
+
+func (f *font) LoadGlyphs(first, last rune) {
+	if first > last {
+		first, last = last, first
+	}
+	for r := first; r < last; r++ {
+		f.advanceDips(r)
+	}
+}
+
+func (f *font) GlyphMaxSize() math.Size {
+	return f.glyphMaxSizeDips
+}
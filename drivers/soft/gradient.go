@@ -0,0 +1,34 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// gradient is an unbounded image of a guix.Gradient, stretched over the
+// bounds of the shape being filled.
+type gradient struct {
+	gradient *guix.Gradient
+	toUnit   math.Mat3 // Window-space pixels to the unit space of the bounds
+}
+
+// image.Image compliance
+func (g *gradient) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (g *gradient) Bounds() image.Rectangle {
+	return image.Rectangle{Min: image.Point{X: -1e9, Y: -1e9}, Max: image.Point{X: 1e9, Y: 1e9}}
+}
+
+func (g *gradient) At(x, y int) color.Color {
+	p := g.toUnit.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+	return rgba(g.gradient.ColorAt(g.gradient.Offset(p)))
+}
@@ -0,0 +1,182 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Input is implemented by viewports of the soft driver, and simulates the
+// input a platform window would receive. Events are raised on the viewport
+// exactly as the gl driver raises them for real input, so they are delivered
+// to the window's mouse, keyboard and focus controllers.
+//
+// Input methods may be called from any go-routine. The events are processed
+// asynchronously on the UI go-routine, in the order they were injected.
+type Input interface {
+	// InjectMouseMove moves the cursor to p, in DIPs relative to the top-left
+	// of the viewport. If the cursor was outside of the viewport then a mouse
+	// enter event is raised before the move.
+	InjectMouseMove(p math.Point)
+
+	// InjectMouseExit moves the cursor outside of the viewport, just beyond
+	// its top-left corner.
+	InjectMouseExit()
+
+	// InjectMouseDown presses the mouse button b at the current cursor
+	// position.
+	InjectMouseDown(b guix.MouseButton, m guix.KeyboardModifier)
+
+	// InjectMouseUp releases the mouse button b at the current cursor
+	// position.
+	InjectMouseUp(b guix.MouseButton, m guix.KeyboardModifier)
+
+	// InjectMouseScroll scrolls the mouse wheel by x and y lines at the
+	// current cursor position.
+	InjectMouseScroll(x, y int)
+
+	// InjectKeyDown presses the key k.
+	InjectKeyDown(k guix.KeyboardKey, m guix.KeyboardModifier)
+
+	// InjectKeyUp releases the key k.
+	InjectKeyUp(k guix.KeyboardKey, m guix.KeyboardModifier)
+
+	// InjectKeyRepeat raises a key repeat for the held key k.
+	InjectKeyRepeat(k guix.KeyboardKey, m guix.KeyboardModifier)
+
+	// InjectKeyStroke types the character r.
+	InjectKeyStroke(r rune, m guix.KeyboardModifier)
+
+	// InjectCompositionStart starts an input method composition with the
+	// pre-edit text and the cursor at the rune index cursor.
+	InjectCompositionStart(text string, cursor int)
+
+	// InjectCompositionUpdate changes the pre-edit text and cursor of the
+	// composition.
+	InjectCompositionUpdate(text string, cursor int)
+
+	// InjectCompositionEnd ends the composition, committing text. An empty
+	// text cancels the composition.
+	InjectCompositionEnd(text string)
+
+	// CompositionRect returns the bounds last set with SetCompositionRect,
+	// where a platform would place the input method's candidate window.
+	CompositionRect() math.Rect
+
+	// Cursor returns the current cursor position in DIPs and whether the
+	// cursor is within the viewport.
+	Cursor() (p math.Point, inside bool)
+
+	// MouseState returns the mouse buttons currently held down.
+	MouseState() guix.MouseState
+}
+
+func (v *viewport) mouseEvent() guix.MouseEvent {
+	return guix.MouseEvent{
+		Point: v.cursor,
+		State: v.mouseState,
+	}
+}
+
+func (v *viewport) InjectMouseMove(p math.Point) {
+	v.Lock()
+	entered := !v.cursorInside
+	v.cursor, v.cursorInside = p, true
+	ev := v.mouseEvent()
+	v.Unlock()
+	if entered {
+		v.onMouseEnter.Fire(ev)
+	}
+	v.onMouseMove.Fire(ev)
+}
+
+func (v *viewport) InjectMouseExit() {
+	v.Lock()
+	exited := v.cursorInside
+	v.cursor, v.cursorInside = math.Point{X: -1, Y: -1}, false
+	ev := v.mouseEvent()
+	v.Unlock()
+	if exited {
+		v.onMouseExit.Fire(ev)
+	}
+}
+
+func (v *viewport) InjectMouseDown(b guix.MouseButton, m guix.KeyboardModifier) {
+	v.Lock()
+	v.mouseState |= 1 << uint(b)
+	ev := v.mouseEvent()
+	v.Unlock()
+	ev.Button = b
+	ev.Modifier = m
+	v.onMouseDown.Fire(ev)
+}
+
+func (v *viewport) InjectMouseUp(b guix.MouseButton, m guix.KeyboardModifier) {
+	v.Lock()
+	v.mouseState &^= 1 << uint(b)
+	ev := v.mouseEvent()
+	v.Unlock()
+	ev.Button = b
+	ev.Modifier = m
+	v.onMouseUp.Fire(ev)
+}
+
+func (v *viewport) InjectMouseScroll(x, y int) {
+	if x == 0 && y == 0 {
+		return // The gl driver never raises empty scroll events
+	}
+	v.Lock()
+	ev := v.mouseEvent()
+	v.Unlock()
+	ev.ScrollX, ev.ScrollY = x, y
+	v.onMouseScroll.Fire(ev)
+}
+
+func (v *viewport) InjectKeyDown(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	v.onKeyDown.Fire(guix.KeyboardEvent{Key: k, Modifier: m})
+}
+
+func (v *viewport) InjectKeyUp(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	v.onKeyUp.Fire(guix.KeyboardEvent{Key: k, Modifier: m})
+}
+
+func (v *viewport) InjectKeyRepeat(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	v.onKeyRepeat.Fire(guix.KeyboardEvent{Key: k, Modifier: m})
+}
+
+func (v *viewport) InjectKeyStroke(r rune, m guix.KeyboardModifier) {
+	v.onKeyStroke.Fire(guix.KeyStrokeEvent{Character: r, Modifier: m})
+}
+
+func (v *viewport) InjectCompositionStart(text string, cursor int) {
+	v.onCompositionStart.Fire(guix.CompositionEvent{Text: text, Cursor: cursor})
+}
+
+func (v *viewport) InjectCompositionUpdate(text string, cursor int) {
+	v.onCompositionUpdate.Fire(guix.CompositionEvent{Text: text, Cursor: cursor})
+}
+
+func (v *viewport) InjectCompositionEnd(text string) {
+	v.onCompositionEnd.Fire(guix.CompositionEvent{Text: text, Cursor: len([]rune(text))})
+}
+
+func (v *viewport) CompositionRect() math.Rect {
+	v.Lock()
+	defer v.Unlock()
+	return v.compositionRect
+}
+
+func (v *viewport) Cursor() (p math.Point, inside bool) {
+	v.Lock()
+	defer v.Unlock()
+	return v.cursor, v.cursorInside
+}
+
+func (v *viewport) MouseState() guix.MouseState {
+	v.Lock()
+	defer v.Unlock()
+	return v.mouseState
+}
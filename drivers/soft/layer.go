@@ -0,0 +1,77 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// layer is the render target replaced by a PushOpacity or PushBlendMode
+// layer. The layer's image is composited onto parent when it is popped.
+type layer struct {
+	parent *image.RGBA
+	alpha  float32
+	mode   guix.BlendMode
+}
+
+// pushLayer redirects drawing to a new transparent image covering the clip of
+// ds, recording the layer in ds.
+func (c *context) pushLayer(ds *drawState, alpha float32, mode guix.BlendMode) {
+	ds.Layer = &layer{parent: c.target, alpha: alpha, mode: mode}
+	c.target = image.NewRGBA(c.clipRect(ds))
+}
+
+// popLayer composites the image of the layer l onto the render target that
+// it replaced, and restores that target.
+func (c *context) popLayer(l *layer) {
+	src := c.target
+	c.target = l.parent
+	composeLayer(c.target, src, l.alpha, l.mode)
+}
+
+func mul255(a, b uint32) uint32 {
+	return (a*b + 127) / 255
+}
+
+// composeLayer blends the premultiplied image src, faded by alpha, onto dst
+// with the blend mode.
+func composeLayer(dst, src *image.RGBA, alpha float32, mode guix.BlendMode) {
+	r := src.Rect.Intersect(dst.Rect)
+	a := uint32(math.Saturate(alpha)*255 + 0.5)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			s := src.Pix[src.PixOffset(x, y):]
+			d := dst.Pix[dst.PixOffset(x, y):]
+			sa := mul255(uint32(s[3]), a)
+			if sa == 0 {
+				continue
+			}
+			da := uint32(d[3])
+			for i := 0; i < 3; i++ {
+				sc, dc := mul255(uint32(s[i]), a), uint32(d[i])
+				var c uint32
+				switch mode {
+				case guix.BlendMultiply:
+					c = mul255(sc, dc) + mul255(sc, 255-da) + mul255(dc, 255-sa)
+				case guix.BlendScreen:
+					c = sc + dc - mul255(sc, dc)
+				case guix.BlendAdditive:
+					c = sc + dc
+				default:
+					c = sc + mul255(dc, 255-sa)
+				}
+				d[i] = uint8(math.Min(int(c), 255))
+			}
+			if mode == guix.BlendAdditive {
+				d[3] = uint8(math.Min(int(sa+da), 255))
+			} else {
+				d[3] = uint8(sa + da - mul255(sa, da))
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"github.com/golang/freetype/raster"
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"golang.org/x/image/math/fixed"
+)
+
+func pruneDuplicates(p guix.Polygon) guix.Polygon {
+	pruned := make(guix.Polygon, 0, len(p))
+	last := guix.PolygonVertex{}
+	for i, v := range p {
+		if i == 0 || last.Position.Sub(v.Position).Vec2().Len() > 0.001 {
+			pruned = append(pruned, v)
+		}
+		last = v
+	}
+	return pruned
+}
+
+// closedPolyToPath returns the outline of the closed polygon p, with rounded
+// vertices, transformed to window-space fixed-point pixels.
+func closedPolyToPath(ctx *context, p guix.Polygon, ds *drawState) raster.Path {
+	path := raster.Path{}
+	var first fixed.Point26_6
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		ab, ac := p.Corner(i)
+		if i == 0 {
+			first = ctx.toFixed(ab, ds)
+			path.Start(first)
+		} else {
+			path.Add1(ctx.toFixed(ab, ds))
+		}
+		if ab != ac {
+			path.Add2(ctx.toFixed(a, ds), ctx.toFixed(ac, ds))
+		}
+	}
+	// Close the path
+	path.Add1(first)
+	return path
+}
+
+// openPolyToPath returns the open polyline p, with rounded inner vertices,
+// transformed to window-space fixed-point pixels.
+func openPolyToPath(ctx *context, p guix.Polygon, ds *drawState) raster.Path {
+	path := raster.Path{}
+	path.Start(ctx.toFixed(p[0].Position.Vec2(), ds))
+	for i := 1; i < len(p)-1; i++ {
+		a := p[i].Position.Vec2()
+		ab, ac := p.Corner(i)
+		path.Add1(ctx.toFixed(ab, ds))
+		if ab != ac {
+			path.Add2(ctx.toFixed(a, ds), ctx.toFixed(ac, ds))
+		}
+	}
+	path.Add1(ctx.toFixed(p[len(p)-1].Position.Vec2(), ds))
+	return path
+}
+
+// rectToPath returns the outline of the rectangle r, transformed to
+// window-space fixed-point pixels.
+func rectToPath(ctx *context, r math.Rect, ds *drawState) raster.Path {
+	path := raster.Path{}
+	path.Start(ctx.toFixed(r.TL().Vec2(), ds))
+	path.Add1(ctx.toFixed(r.TR().Vec2(), ds))
+	path.Add1(ctx.toFixed(r.BR().Vec2(), ds))
+	path.Add1(ctx.toFixed(r.BL().Vec2(), ds))
+	path.Add1(ctx.toFixed(r.TL().Vec2(), ds))
+	return path
+}
+
+// flattenPoly returns the points of the polygon p with its rounded vertices
+// approximated by line segments. If closed is false then p is treated as a
+// polyline and its end points are not rounded.
+func flattenPoly(p guix.Polygon, closed bool) []math.Vec2 {
+	points := make([]math.Vec2, 0, len(p))
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		if !closed && (i == 0 || i == cnt-1) {
+			points = append(points, a)
+			continue
+		}
+		ab, ac := p.Corner(i)
+		points = append(points, ab)
+		if ab != ac {
+			// Approximate the quadratic curve ab, a, ac.
+			steps := 2 + int(ab.Sub(a).Len()+ac.Sub(a).Len())/4
+			for j := 1; j <= steps; j++ {
+				t := float32(j) / float32(steps)
+				u := 1 - t
+				points = append(points, ab.MulS(u*u).Add(a.MulS(2*u*t)).Add(ac.MulS(t*t)))
+			}
+		}
+	}
+	return points
+}
+
+// dashesToPath returns a path of the polylines, transformed to window-space
+// fixed-point pixels.
+func dashesToPath(ctx *context, dashes [][]math.Vec2, ds *drawState) raster.Path {
+	path := raster.Path{}
+	for _, d := range dashes {
+		path.Start(ctx.toFixed(d[0], ds))
+		for _, p := range d[1:] {
+			path.Add1(ctx.toFixed(p, ds))
+		}
+	}
+	return path
+}
+
+// flattenPath returns the subpaths of path flattened to polylines, to within a
+// quarter of a window-space pixel.
+func flattenPath(ctx *context, path *guix.Path2D, ds *drawState) []guix.Contour {
+	tolerance := 0.25 / (ds.Transform.Scale() * ctx.resolution.dipsToPixels())
+	return path.Flatten(tolerance)
+}
+
+// contoursToPath returns a path of the contours, each closed, transformed to
+// window-space fixed-point pixels.
+func contoursToPath(ctx *context, contours []guix.Contour, ds *drawState) raster.Path {
+	path := raster.Path{}
+	for _, c := range contours {
+		path.Start(ctx.toFixed(c.Points[0], ds))
+		for _, p := range c.Points[1:] {
+			path.Add1(ctx.toFixed(p, ds))
+		}
+		path.Add1(ctx.toFixed(c.Points[0], ds))
+	}
+	return path
+}
+
+// capper returns the raster.Capper of the pen's cap style.
+func capper(p guix.Pen) raster.Capper {
+	switch p.Cap {
+	case guix.RoundCap:
+		return raster.RoundCapper
+	case guix.SquareCap:
+		return raster.SquareCapper
+	default:
+		return raster.ButtCapper
+	}
+}
+
+// joiner returns the raster.Joiner of the pen's join style.
+func joiner(p guix.Pen) raster.Joiner {
+	switch p.Join {
+	case guix.RoundJoin:
+		return raster.RoundJoiner
+	case guix.BevelJoin:
+		return raster.BevelJoiner
+	default:
+		return miterJoiner(p.EffectiveMiterLimit())
+	}
+}
+
+// miterJoiner is a raster.Joiner of miter joins with the given miter limit.
+// Joins that exceed the limit are beveled.
+type miterJoiner float32
+
+func (l miterJoiner) Join(lhs, rhs raster.Adder, halfWidth fixed.Int26_6, pivot, n0, n1 fixed.Point26_6) {
+	// The miter extends from the pivot along the sum of the normals, by the
+	// half width divided by the cosine of half the angle between them.
+	sx, sy := float32(n0.X+n1.X), float32(n0.Y+n1.Y)
+	hw := float32(halfWidth)
+	sLen := math.Sqrtf(sx*sx + sy*sy)
+	var m fixed.Point26_6
+	miter := sLen > 0 && 2*hw/sLen <= float32(l)
+	if miter {
+		k := 2 * hw * hw / (sLen * sLen)
+		m = fixed.Point26_6{X: fixed.Int26_6(sx * k), Y: fixed.Int26_6(sy * k)}
+	}
+	// Find the outside of the join, as raster.RoundJoiner does.
+	if int64(n0.X)*int64(n1.Y)-int64(n0.Y)*int64(n1.X) >= 0 {
+		if miter {
+			lhs.Add1(pivot.Add(m))
+		}
+		lhs.Add1(pivot.Add(n1))
+		rhs.Add1(pivot.Sub(n1))
+	} else {
+		lhs.Add1(pivot.Add(n1))
+		if miter {
+			rhs.Add1(pivot.Sub(m))
+		}
+		rhs.Add1(pivot.Sub(n1))
+	}
+}
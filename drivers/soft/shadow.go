@@ -0,0 +1,37 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// shadow is an unbounded image of the guix.Shadow of a rounded rectangle.
+type shadow struct {
+	shadow         guix.Shadow
+	rect           math.Rect
+	tl, tr, bl, br float32
+	toLocal        math.Mat3 // Window-space pixels to the local space of the rectangle
+}
+
+// image.Image compliance
+func (s *shadow) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (s *shadow) Bounds() image.Rectangle {
+	return image.Rectangle{Min: image.Point{X: -1e9, Y: -1e9}, Max: image.Point{X: 1e9, Y: 1e9}}
+}
+
+func (s *shadow) At(x, y int) color.Color {
+	p := s.toLocal.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+	c := s.shadow.Color
+	c.A *= s.shadow.Alpha(s.rect, s.tl, s.tr, s.bl, s.br, p)
+	return rgba(c)
+}
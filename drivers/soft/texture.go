@@ -0,0 +1,146 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/golang/freetype/raster"
+	"github.com/vcaesar/guix/math"
+)
+
+type texture struct {
+	image        image.Image
+	pixelsPerDip float32
+	flipY        bool
+}
+
+func newTexture(img image.Image, pixelsPerDip float32) *texture {
+	t := &texture{
+		image:        img,
+		pixelsPerDip: pixelsPerDip,
+	}
+	return t
+}
+
+// guix.Texture compliance
+func (t *texture) Image() image.Image {
+	return t.image
+}
+
+func (t *texture) Size() math.Size {
+	return t.SizePixels().ScaleS(1.0 / t.pixelsPerDip)
+}
+
+func (t *texture) SizePixels() math.Size {
+	s := t.image.Bounds().Size()
+	return math.Size{W: s.X, H: s.Y}
+}
+
+func (t *texture) FlipY() bool {
+	return t.flipY
+}
+
+func (t *texture) SetFlipY(flipY bool) {
+	t.flipY = flipY
+}
+
+// draw bilinearly samples the texture into the local-space rectangle r, in
+// DIPs, blending over the render target within the clip of ds.
+func (t *texture) draw(ctx *context, r math.Rect, ds *drawState) {
+	if ds.Transform != math.Mat3Ident {
+		t.drawTransformed(ctx, r, ds)
+		return
+	}
+	dst := ctx.rectToPixels(r, ds)
+	clipped := dst.Intersect(ctx.clipRect(ds))
+	if clipped.Empty() {
+		return
+	}
+	src := t.image
+	sb := src.Bounds()
+	sw, sh := float32(sb.Dx()), float32(sb.Dy())
+	dw, dh := float32(dst.Dx()), float32(dst.Dy())
+
+	tmp := image.NewRGBA(clipped)
+	for y := clipped.Min.Y; y < clipped.Max.Y; y++ {
+		v := (float32(y-dst.Min.Y)+0.5)*sh/dh - 0.5
+		if t.flipY {
+			v = sh - 1 - v
+		}
+		for x := clipped.Min.X; x < clipped.Max.X; x++ {
+			u := (float32(x-dst.Min.X)+0.5)*sw/dw - 0.5
+			tmp.SetRGBA(x, y, sampleBilinear(src, sb, u, v))
+		}
+	}
+	draw.Draw(ctx.target, clipped, tmp, clipped.Min, draw.Over)
+}
+
+// drawTransformed draws the texture into the local-space rectangle r when the
+// transform of ds is not the identity. Each pixel covered by the transformed
+// rectangle is mapped back into the texture to be sampled.
+func (t *texture) drawTransformed(ctx *context, r math.Rect, ds *drawState) {
+	path := rectToPath(ctx, r, ds)
+	mask := ctx.rasterize(0, ds, true, func(rz *raster.Rasterizer) {
+		rz.AddPath(path)
+	})
+	clipped := mask.Rect.Intersect(ctx.rectToPixels(r, ds))
+	if clipped.Empty() {
+		return
+	}
+	src := t.image
+	sb := src.Bounds()
+	sw, sh := float32(sb.Dx()), float32(sb.Dy())
+	rw, rh := float32(math.Max(r.W(), 1)), float32(math.Max(r.H(), 1))
+	toLocal := ctx.toLocal(ds)
+
+	tmp := image.NewRGBA(clipped)
+	for y := clipped.Min.Y; y < clipped.Max.Y; y++ {
+		for x := clipped.Min.X; x < clipped.Max.X; x++ {
+			if mask.AlphaAt(x, y).A == 0 {
+				continue
+			}
+			p := toLocal.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+			u := (p.X-float32(r.Min.X))*sw/rw - 0.5
+			v := (p.Y-float32(r.Min.Y))*sh/rh - 0.5
+			if t.flipY {
+				v = sh - 1 - v
+			}
+			tmp.SetRGBA(x, y, sampleBilinear(src, sb, u, v))
+		}
+	}
+	draw.DrawMask(ctx.target, clipped, tmp, clipped.Min, mask, clipped.Min, draw.Over)
+}
+
+// sampleBilinear returns the premultiplied color of img at the texel-space
+// position (u, v), relative to the bounds b.
+func sampleBilinear(img image.Image, b image.Rectangle, u, v float32) color.RGBA {
+	u = math.Clampf(u, 0, float32(b.Dx()-1))
+	v = math.Clampf(v, 0, float32(b.Dy()-1))
+	x0, y0 := int(u), int(v)
+	x1, y1 := math.Min(x0+1, b.Dx()-1), math.Min(y0+1, b.Dy()-1)
+	fx, fy := u-float32(x0), v-float32(y0)
+
+	var acc [4]float32
+	sample := func(x, y int, w float32) {
+		r, g, b_, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+		acc[0] += float32(r) * w
+		acc[1] += float32(g) * w
+		acc[2] += float32(b_) * w
+		acc[3] += float32(a) * w
+	}
+	sample(x0, y0, (1-fx)*(1-fy))
+	sample(x1, y0, fx*(1-fy))
+	sample(x0, y1, (1-fx)*fy)
+	sample(x1, y1, fx*fy)
+	return color.RGBA{
+		R: uint8(acc[0]/257 + 0.5),
+		G: uint8(acc[1]/257 + 0.5),
+		B: uint8(acc[2]/257 + 0.5),
+		A: uint8(acc[3]/257 + 0.5),
+	}
+}
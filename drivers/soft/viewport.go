@@ -0,0 +1,341 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+const clearColorR = 0.5
+const clearColorG = 0.5
+const clearColorB = 0.5
+
+// Viewport is the guix.Viewport implementation returned by the soft driver.
+type Viewport interface {
+	guix.Viewport
+	Input
+
+	// Frame returns a copy of the most recently rendered frame, sized in pixels.
+	Frame() *image.RGBA
+
+	// FrameCount returns the number of frames rendered by the viewport.
+	FrameCount() int
+
+	// Visible returns true if the viewport is currently shown.
+	Visible() bool
+}
+
+type viewport struct {
+	sync.Mutex
+	frameLock sync.Mutex // guards context, canvas and frameCount
+
+	driver           *driver
+	context          *context
+	canvas           *canvas
+	fullscreen       bool
+	visible          bool
+	scaling          float32
+	sizeDipsUnscaled math.Size
+	sizeDips         math.Size
+	sizePixels       math.Size
+	position         math.Point
+	title            string
+	destroyed        bool
+	redrawCount      uint32
+	frameCount       int
+	cursor           math.Point
+	cursorInside     bool
+	mouseState       guix.MouseState
+	compositionRect  math.Rect
+
+	// Broadcasts to application thread
+	onClose             guix.Event // ()
+	onResize            guix.Event // ()
+	onMouseMove         guix.Event // (guix.MouseEvent)
+	onMouseEnter        guix.Event // (guix.MouseEvent)
+	onMouseExit         guix.Event // (guix.MouseEvent)
+	onMouseDown         guix.Event // (guix.MouseEvent)
+	onMouseUp           guix.Event // (guix.MouseEvent)
+	onMouseScroll       guix.Event // (guix.MouseEvent)
+	onKeyDown           guix.Event // (guix.KeyboardEvent)
+	onKeyUp             guix.Event // (guix.KeyboardEvent)
+	onKeyRepeat         guix.Event // (guix.KeyboardEvent)
+	onKeyStroke         guix.Event // (guix.KeyStrokeEvent)
+	onCompositionStart  guix.Event // (guix.CompositionEvent)
+	onCompositionUpdate guix.Event // (guix.CompositionEvent)
+	onCompositionEnd    guix.Event // (guix.CompositionEvent)
+	// Broadcasts to driver thread
+	onDestroy guix.Event
+}
+
+func newViewport(driver *driver, width, height int, title string, fullscreen bool) *viewport {
+	if fullscreen && (width == 0 || height == 0) {
+		width, height = ScreenSize.WH()
+	}
+
+	v := &viewport{
+		driver:     driver,
+		fullscreen: fullscreen,
+		visible:    true,
+		scaling:    1,
+		title:      title,
+	}
+
+	v.context = newContext()
+	v.onClose = driver.createAppEvent(func() {})
+	v.onResize = driver.createAppEvent(func() {})
+	v.onMouseMove = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onMouseEnter = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onMouseExit = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onMouseDown = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onMouseUp = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onMouseScroll = driver.createAppEvent(func(guix.MouseEvent) {})
+	v.onKeyDown = driver.createAppEvent(func(guix.KeyboardEvent) {})
+	v.onKeyUp = driver.createAppEvent(func(guix.KeyboardEvent) {})
+	v.onKeyRepeat = driver.createAppEvent(func(guix.KeyboardEvent) {})
+	v.onKeyStroke = driver.createAppEvent(func(guix.KeyStrokeEvent) {})
+	v.onCompositionStart = driver.createAppEvent(func(guix.CompositionEvent) {})
+	v.onCompositionUpdate = driver.createAppEvent(func(guix.CompositionEvent) {})
+	v.onCompositionEnd = driver.createAppEvent(func(guix.CompositionEvent) {})
+	v.onDestroy = driver.createDriverEvent(func() {})
+	v.sizeDipsUnscaled = math.Size{W: width, H: height}
+	v.sizeDips = v.sizeDipsUnscaled.ScaleS(1 / v.scaling)
+	v.sizePixels = v.sizeDipsUnscaled
+	v.context.resize(v.sizePixels)
+	v.context.clear(guix.Color{R: clearColorR, G: clearColorG, B: clearColorB, A: 1})
+	return v
+}
+
+// render rasterizes the current canvas into the context's target.
+// frameLock must be held when calling render.
+func (v *viewport) render() {
+	if v.destroyed {
+		return
+	}
+
+	ctx := v.context
+	ctx.beginDraw(v.SizeDips(), v.SizePixels())
+
+	dss := drawStateStack{drawState{
+		ClipPixels: imageRect(v.SizePixels().Rect()),
+		Transform:  math.Mat3Ident,
+	}}
+
+	v.canvas.draw(ctx, &dss)
+	if len(dss) != 1 {
+		panic("DrawStateStack count was not 1 after calling Canvas.Draw")
+	}
+
+	v.frameCount++
+}
+
+// guix.Viewport compliance
+// These methods are all called on the application routine
+func (v *viewport) SetCanvas(cc guix.Canvas) {
+	cnt := atomic.AddUint32(&v.redrawCount, 1)
+	c, _ := cc.(*canvas)
+	// Unlike the gl driver, rendering happens synchronously on the calling
+	// routine so that the frame is available as soon as SetCanvas returns.
+	v.frameLock.Lock()
+	defer v.frameLock.Unlock()
+	// Only use the canvas of the most recent SetCanvas call.
+	if atomic.LoadUint32(&v.redrawCount) == cnt {
+		v.canvas = c
+		if v.canvas != nil {
+			v.render()
+		}
+	}
+}
+
+func (v *viewport) Scale() float32 {
+	v.Lock()
+	defer v.Unlock()
+	return v.scaling
+}
+
+func (v *viewport) SetScale(s float32) {
+	v.Lock()
+	defer v.Unlock()
+	if s != v.scaling {
+		v.scaling = s
+		v.sizeDips = v.sizeDipsUnscaled.ScaleS(1 / s)
+		v.onResize.Fire()
+	}
+}
+
+func (v *viewport) SizeDips() math.Size {
+	v.Lock()
+	defer v.Unlock()
+	return v.sizeDips
+}
+
+func (v *viewport) SetSizeDips(size math.Size) {
+	v.Lock()
+	v.sizeDips = size
+	v.sizeDipsUnscaled = size.ScaleS(v.scaling)
+	v.sizePixels = v.sizeDipsUnscaled
+	v.Unlock()
+	v.frameLock.Lock()
+	v.context.resize(v.sizePixels)
+	v.frameLock.Unlock()
+	v.onResize.Fire()
+}
+
+func (v *viewport) SizePixels() math.Size {
+	v.Lock()
+	defer v.Unlock()
+	return v.sizePixels
+}
+
+func (v *viewport) Title() string {
+	v.Lock()
+	defer v.Unlock()
+	return v.title
+}
+
+func (v *viewport) SetTitle(title string) {
+	v.Lock()
+	v.title = title
+	v.Unlock()
+}
+
+func (v *viewport) Position() math.Point {
+	v.Lock()
+	defer v.Unlock()
+	return v.position
+}
+
+func (v *viewport) SetPosition(pos math.Point) {
+	v.Lock()
+	v.position = pos
+	v.Unlock()
+}
+
+func (v *viewport) Fullscreen() bool {
+	return v.fullscreen
+}
+
+func (v *viewport) Show() {
+	v.Lock()
+	v.visible = true
+	v.Unlock()
+}
+
+func (v *viewport) Hide() {
+	v.Lock()
+	v.visible = false
+	v.Unlock()
+}
+
+func (v *viewport) Close() {
+	v.onClose.Fire()
+	v.Destroy()
+}
+
+func (v *viewport) OnResize(f func()) guix.EventSubscription {
+	return v.onResize.Listen(f)
+}
+
+func (v *viewport) OnClose(f func()) guix.EventSubscription {
+	return v.onClose.Listen(f)
+}
+
+func (v *viewport) OnMouseMove(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseMove.Listen(f)
+}
+
+func (v *viewport) OnMouseEnter(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseEnter.Listen(f)
+}
+
+func (v *viewport) OnMouseExit(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseExit.Listen(f)
+}
+
+func (v *viewport) OnMouseDown(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseDown.Listen(f)
+}
+
+func (v *viewport) OnMouseUp(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseUp.Listen(f)
+}
+
+func (v *viewport) OnMouseScroll(f func(guix.MouseEvent)) guix.EventSubscription {
+	return v.onMouseScroll.Listen(f)
+}
+
+func (v *viewport) OnKeyDown(f func(guix.KeyboardEvent)) guix.EventSubscription {
+	return v.onKeyDown.Listen(f)
+}
+
+func (v *viewport) OnKeyUp(f func(guix.KeyboardEvent)) guix.EventSubscription {
+	return v.onKeyUp.Listen(f)
+}
+
+func (v *viewport) OnKeyRepeat(f func(guix.KeyboardEvent)) guix.EventSubscription {
+	return v.onKeyRepeat.Listen(f)
+}
+
+func (v *viewport) OnKeyStroke(f func(guix.KeyStrokeEvent)) guix.EventSubscription {
+	return v.onKeyStroke.Listen(f)
+}
+
+func (v *viewport) OnCompositionStart(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionStart.Listen(f)
+}
+
+func (v *viewport) OnCompositionUpdate(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionUpdate.Listen(f)
+}
+
+func (v *viewport) OnCompositionEnd(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return v.onCompositionEnd.Listen(f)
+}
+
+func (v *viewport) SetCompositionRect(r math.Rect) {
+	v.Lock()
+	v.compositionRect = r
+	v.Unlock()
+}
+
+// soft.Viewport compliance
+func (v *viewport) Frame() *image.RGBA {
+	v.frameLock.Lock()
+	defer v.frameLock.Unlock()
+	src := v.context.target
+	img := image.NewRGBA(src.Rect)
+	copy(img.Pix, src.Pix)
+	return img
+}
+
+func (v *viewport) FrameCount() int {
+	v.frameLock.Lock()
+	defer v.frameLock.Unlock()
+	return v.frameCount
+}
+
+func (v *viewport) Visible() bool {
+	v.Lock()
+	defer v.Unlock()
+	return v.visible
+}
+
+func (v *viewport) Destroy() {
+	v.driver.asyncDriver(func() {
+		v.frameLock.Lock()
+		destroyed := v.destroyed
+		v.canvas = nil
+		v.destroyed = true
+		v.frameLock.Unlock()
+		if !destroyed {
+			v.onDestroy.Fire()
+		}
+	})
+}
@@ -0,0 +1,310 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package svg contains a guix.Canvas implementation that records draw
+// commands so they can be exported as SVG documents.
+//
+// Controls draw with canvases created by their theme's driver. To export a
+// control, create the theme with a driver returned by WrapDriver, which
+// creates recording canvases and replays them into the wrapped driver's
+// canvases when they are displayed. The Canvas returned by the control's
+// Draw method can then be written with WriteTo.
+package svg
+
+import (
+	"fmt"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Canvas is a guix.Canvas that records draw commands.
+type Canvas struct {
+	size              math.Size
+	ops               []op
+	built             bool
+	buildingPushCount int
+}
+
+// CreateCanvas returns a new, empty Canvas of the given size in DIPs.
+func CreateCanvas(size math.Size) *Canvas {
+	return &Canvas{size: size}
+}
+
+// op is a single recorded draw command.
+type op interface {
+	// replay performs the command on dst. Nested recording canvases are
+	// replayed into canvases created by driver.
+	replay(dst guix.Canvas, driver guix.Driver)
+
+	// encode writes the command as SVG.
+	encode(e *encoder)
+}
+
+type pushOp struct{}
+type popOp struct{}
+
+type layerOp struct {
+	alpha float32
+	mode  guix.BlendMode
+}
+
+type clipOp struct {
+	rect math.Rect
+}
+
+type transformOp struct {
+	m math.Mat3
+}
+
+type clearOp struct {
+	color guix.Color
+}
+
+type canvasOp struct {
+	canvas   guix.Canvas
+	position math.Point
+}
+
+type textureOp struct {
+	texture guix.Texture
+	bounds  math.Rect
+}
+
+type runesOp struct {
+	font   guix.Font
+	runes  []rune
+	points []math.Point
+	color  guix.Color
+}
+
+type linesOp struct {
+	polygon guix.Polygon
+	pen     guix.Pen
+}
+
+type polygonOp struct {
+	polygon guix.Polygon
+	pen     guix.Pen
+	brush   guix.Brush
+}
+
+type pathOp struct {
+	path  *guix.Path2D
+	pen   guix.Pen
+	brush guix.Brush
+}
+
+type rectOp struct {
+	rect  math.Rect
+	brush guix.Brush
+}
+
+type roundedRectOp struct {
+	rect           math.Rect
+	tl, tr, bl, br float32
+	pen            guix.Pen
+	brush          guix.Brush
+}
+
+type shadowOp struct {
+	rect           math.Rect
+	tl, tr, bl, br float32
+	shadow         guix.Shadow
+}
+
+func (c *Canvas) appendOp(name string, o op) {
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", name))
+	}
+	c.ops = append(c.ops, o)
+}
+
+// Replay performs the recorded draw commands on a new canvas created by
+// driver, returning the completed canvas. Nested recording canvases are
+// replayed recursively.
+func (c *Canvas) Replay(driver guix.Driver) guix.Canvas {
+	dst := driver.CreateCanvas(c.size)
+	for _, o := range c.ops {
+		o.replay(dst, driver)
+	}
+	dst.Complete()
+	return dst
+}
+
+// guix.Canvas compliance
+func (c *Canvas) Size() math.Size {
+	return c.size
+}
+
+func (c *Canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *Canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+}
+
+func (c *Canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp("Push", pushOp{})
+}
+
+func (c *Canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp("Pop", popOp{})
+}
+
+func (c *Canvas) PushOpacity(alpha float32) {
+	c.buildingPushCount++
+	c.appendOp("PushOpacity", layerOp{alpha, guix.BlendNormal})
+}
+
+func (c *Canvas) PushBlendMode(mode guix.BlendMode) {
+	c.buildingPushCount++
+	c.appendOp("PushBlendMode", layerOp{1, mode})
+}
+
+func (c *Canvas) AddClip(r math.Rect) {
+	c.appendOp("AddClip", clipOp{r})
+}
+
+func (c *Canvas) Transform(m math.Mat3) {
+	c.appendOp("Transform", transformOp{m})
+}
+
+func (c *Canvas) Translate(x, y float32) {
+	c.Transform(math.CreateMat3Translate(math.Vec2{X: x, Y: y}))
+}
+
+func (c *Canvas) Rotate(radians float32) {
+	c.Transform(math.CreateMat3Rotate(radians))
+}
+
+func (c *Canvas) Scale(x, y float32) {
+	c.Transform(math.CreateMat3Scale(math.Vec2{X: x, Y: y}))
+}
+
+func (c *Canvas) Clear(color guix.Color) {
+	c.appendOp("Clear", clearOp{color})
+}
+
+func (c *Canvas) DrawCanvas(cc guix.Canvas, offset math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	c.appendOp("DrawCanvas", canvasOp{cc, offset})
+}
+
+func (c *Canvas) DrawRunes(font guix.Font, runes []rune, points []math.Point, color guix.Color) {
+	if font == nil {
+		panic("Font cannot be nil")
+	}
+	runes = append([]rune{}, runes...)
+	points = append([]math.Point{}, points...)
+	c.appendOp("DrawRunes", runesOp{font, runes, points, color})
+}
+
+func (c *Canvas) DrawLines(lines guix.Polygon, pen guix.Pen) {
+	lines = append(guix.Polygon{}, lines...)
+	c.appendOp("DrawLines", linesOp{lines, pen})
+}
+
+func (c *Canvas) DrawPolygon(poly guix.Polygon, pen guix.Pen, brush guix.Brush) {
+	poly = append(guix.Polygon{}, poly...)
+	c.appendOp("DrawPolygon", polygonOp{poly, pen, brush})
+}
+
+func (c *Canvas) DrawPath(path *guix.Path2D, pen guix.Pen, brush guix.Brush) {
+	c.appendOp("DrawPath", pathOp{path.Clone(), pen, brush})
+}
+
+func (c *Canvas) DrawRect(r math.Rect, brush guix.Brush) {
+	c.appendOp("DrawRect", rectOp{r, brush})
+}
+
+func (c *Canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen guix.Pen, brush guix.Brush) {
+	c.appendOp("DrawRoundedRect", roundedRectOp{r, tl, tr, bl, br, pen, brush})
+}
+
+func (c *Canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s guix.Shadow) {
+	c.appendOp("DrawShadow", shadowOp{r, tl, tr, bl, br, s})
+}
+
+func (c *Canvas) DrawTexture(t guix.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	c.appendOp("DrawTexture", textureOp{t, r})
+}
+
+// op compliance
+func (pushOp) replay(dst guix.Canvas, driver guix.Driver) { dst.Push() }
+func (popOp) replay(dst guix.Canvas, driver guix.Driver)  { dst.Pop() }
+
+func (o layerOp) replay(dst guix.Canvas, driver guix.Driver) {
+	if o.mode != guix.BlendNormal {
+		dst.PushBlendMode(o.mode)
+	} else {
+		dst.PushOpacity(o.alpha)
+	}
+}
+
+func (o clipOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.AddClip(o.rect)
+}
+
+func (o transformOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.Transform(o.m)
+}
+
+func (o clearOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.Clear(o.color)
+}
+
+func (o canvasOp) replay(dst guix.Canvas, driver guix.Driver) {
+	c := o.canvas
+	if r, ok := c.(*Canvas); ok {
+		c = r.Replay(driver)
+	}
+	dst.DrawCanvas(c, o.position)
+}
+
+func (o textureOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawTexture(o.texture, o.bounds)
+}
+
+func (o runesOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawRunes(o.font, o.runes, o.points, o.color)
+}
+
+func (o linesOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawLines(o.polygon, o.pen)
+}
+
+func (o polygonOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawPolygon(o.polygon, o.pen, o.brush)
+}
+
+func (o pathOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawPath(o.path, o.pen, o.brush)
+}
+
+func (o shadowOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawShadow(o.rect, o.tl, o.tr, o.bl, o.br, o.shadow)
+}
+
+func (o rectOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawRect(o.rect, o.brush)
+}
+
+func (o roundedRectOp) replay(dst guix.Canvas, driver guix.Driver) {
+	dst.DrawRoundedRect(o.rect, o.tl, o.tr, o.bl, o.br, o.pen, o.brush)
+}
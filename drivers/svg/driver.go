@@ -0,0 +1,51 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+type driver struct {
+	guix.Driver
+}
+
+type viewport struct {
+	guix.Viewport
+	driver guix.Driver
+}
+
+// WrapDriver returns a guix.Driver that behaves like d, except that
+// CreateCanvas returns recording canvases. Recording canvases set on the
+// driver's viewports are replayed into canvases created by d, so windows
+// are displayed as normal.
+func WrapDriver(d guix.Driver) guix.Driver {
+	return driver{d}
+}
+
+func (d driver) CreateCanvas(s math.Size) guix.Canvas {
+	return CreateCanvas(s)
+}
+
+func (d driver) CreateWindowedViewport(width, height int, name string) guix.Viewport {
+	return viewport{d.Driver.CreateWindowedViewport(width, height, name), d.Driver}
+}
+
+func (d driver) CreateFullscreenViewport(width, height int, name string) guix.Viewport {
+	return viewport{d.Driver.CreateFullscreenViewport(width, height, name), d.Driver}
+}
+
+func (v viewport) SetCanvas(c guix.Canvas) {
+	if r, ok := c.(*Canvas); ok {
+		c = r.Replay(v.driver)
+	}
+	v.Viewport.SetCanvas(c)
+}
+
+// Unwrap returns the viewport created by the wrapped driver.
+func (v viewport) Unwrap() guix.Viewport {
+	return v.Viewport
+}
@@ -0,0 +1,335 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// FontFamily is the font-family used for text in exported SVG documents.
+var FontFamily = "Roboto, sans-serif"
+
+// WriteTo writes the recorded draw commands to w as an SVG document, sized in
+// DIPs. Nested recording canvases are written inline. Canvases of other
+// implementations cannot be exported and are omitted.
+func (c *Canvas) WriteTo(w io.Writer) (n int64, err error) {
+	if !c.built {
+		panic("WriteTo() called before Complete()")
+	}
+	e := &encoder{}
+	fmt.Fprintf(&e.buf, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		c.size.W, c.size.H, c.size.W, c.size.H)
+	e.canvas(c)
+	e.buf.WriteString("</svg>\n")
+	return e.buf.WriteTo(w)
+}
+
+type encoder struct {
+	buf    bytes.Buffer
+	size   math.Size // Size of the canvas being encoded
+	groups []int     // Number of open groups for each Push
+	nextID int
+}
+
+func (e *encoder) id(prefix string) string {
+	e.nextID++
+	return prefix + strconv.Itoa(e.nextID)
+}
+
+func (e *encoder) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&e.buf, format, args...)
+}
+
+func (e *encoder) closeGroups(n int) {
+	for i := 0; i < n; i++ {
+		e.buf.WriteString("</g>\n")
+	}
+}
+
+func (e *encoder) canvas(c *Canvas) {
+	size, groups := e.size, e.groups
+	e.size, e.groups = c.size, []int{0}
+	for _, o := range c.ops {
+		o.encode(e)
+	}
+	for _, n := range e.groups {
+		e.closeGroups(n)
+	}
+	e.size, e.groups = size, groups
+}
+
+func num(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+func rgb(c guix.Color) string {
+	b := func(f float32) int { return int(math.Clampf(f, 0, 1)*255 + 0.5) }
+	return fmt.Sprintf("rgb(%d,%d,%d)", b(c.R), b(c.G), b(c.B))
+}
+
+// fill returns the fill attributes for the brush b. Gradients are written as
+// definitions before the element that uses them.
+func (e *encoder) fill(b guix.Brush) string {
+	g := b.Gradient
+	if g == nil {
+		if b.Color.A <= 0 {
+			return `fill="none"`
+		}
+		if b.Color.A >= 1 {
+			return fmt.Sprintf(`fill="%s"`, rgb(b.Color))
+		}
+		return fmt.Sprintf(`fill="%s" fill-opacity="%s"`, rgb(b.Color), num(b.Color.A))
+	}
+	if !b.IsVisible() {
+		return `fill="none"`
+	}
+	spread := "pad"
+	switch g.Repeat {
+	case guix.RepeatTile:
+		spread = "repeat"
+	case guix.RepeatReflect:
+		spread = "reflect"
+	}
+	id := e.id("gradient")
+	if g.Type == guix.RadialGradient {
+		e.printf(`<defs><radialGradient id="%s" cx="%s" cy="%s" r="%s" spreadMethod="%s">`,
+			id, num(g.Start.X), num(g.Start.Y), num(g.Radius), spread)
+	} else {
+		e.printf(`<defs><linearGradient id="%s" x1="%s" y1="%s" x2="%s" y2="%s" spreadMethod="%s">`,
+			id, num(g.Start.X), num(g.Start.Y), num(g.End.X), num(g.End.Y), spread)
+	}
+	for _, s := range g.Stops {
+		e.printf(`<stop offset="%s" stop-color="%s" stop-opacity="%s"/>`,
+			num(s.Offset), rgb(s.Color), num(math.Saturate(s.Color.A)))
+	}
+	if g.Type == guix.RadialGradient {
+		e.printf("</radialGradient></defs>\n")
+	} else {
+		e.printf("</linearGradient></defs>\n")
+	}
+	return fmt.Sprintf(`fill="url(#%s)"`, id)
+}
+
+// stroke returns the stroke attributes for the pen p, with the line width
+// scaled by widthScale.
+func stroke(p guix.Pen, widthScale float32) string {
+	s := fmt.Sprintf(`stroke="%s" stroke-width="%s"`, rgb(p.Color), num(p.Width*widthScale))
+	if p.Color.A < 1 {
+		s += fmt.Sprintf(` stroke-opacity="%s"`, num(p.Color.A))
+	}
+	switch p.Cap {
+	case guix.RoundCap:
+		s += ` stroke-linecap="round"`
+	case guix.SquareCap:
+		s += ` stroke-linecap="square"`
+	}
+	switch p.Join {
+	case guix.RoundJoin:
+		s += ` stroke-linejoin="round"`
+	case guix.BevelJoin:
+		s += ` stroke-linejoin="bevel"`
+	default:
+		if l := p.EffectiveMiterLimit(); l != guix.DefaultMiterLimit {
+			s += fmt.Sprintf(` stroke-miterlimit="%s"`, num(math.Maxf(l, 1)))
+		}
+	}
+	if p.Dash != nil && len(p.Dash.Pattern) > 0 {
+		lengths := make([]string, len(p.Dash.Pattern))
+		for i, l := range p.Dash.Pattern {
+			lengths[i] = num(l)
+		}
+		s += fmt.Sprintf(` stroke-dasharray="%s"`, strings.Join(lengths, " "))
+		if p.Dash.Offset != 0 {
+			s += fmt.Sprintf(` stroke-dashoffset="%s"`, num(p.Dash.Offset))
+		}
+	}
+	return s
+}
+
+func visible(p guix.Pen) bool {
+	return p.Width > 0 && p.Color.A > 0
+}
+
+// op compliance
+func (pushOp) encode(e *encoder) {
+	e.groups = append(e.groups, 0)
+}
+
+func (popOp) encode(e *encoder) {
+	last := len(e.groups) - 1
+	e.closeGroups(e.groups[last])
+	e.groups = e.groups[:last]
+}
+
+func (o layerOp) encode(e *encoder) {
+	style := ""
+	switch o.mode {
+	case guix.BlendMultiply:
+		style = ` style="isolation:isolate;mix-blend-mode:multiply"`
+	case guix.BlendScreen:
+		style = ` style="isolation:isolate;mix-blend-mode:screen"`
+	case guix.BlendAdditive:
+		style = ` style="isolation:isolate;mix-blend-mode:plus-lighter"`
+	}
+	e.printf(`<g opacity="%s"%s>`+"\n", num(math.Saturate(o.alpha)), style)
+	e.groups = append(e.groups, 1)
+}
+
+func (o clipOp) encode(e *encoder) {
+	id := e.id("clip")
+	r := o.rect
+	e.printf(`<clipPath id="%s"><rect x="%d" y="%d" width="%d" height="%d"/></clipPath>`+"\n",
+		id, r.Min.X, r.Min.Y, r.W(), r.H())
+	e.printf(`<g clip-path="url(#%s)">`+"\n", id)
+	e.groups[len(e.groups)-1]++
+}
+
+func (o transformOp) encode(e *encoder) {
+	m := o.m
+	e.printf(`<g transform="matrix(%s %s %s %s %s %s)">`+"\n",
+		num(m[0]), num(m[1]), num(m[3]), num(m[4]), num(m[6]), num(m[7]))
+	e.groups[len(e.groups)-1]++
+}
+
+func (o clearOp) encode(e *encoder) {
+	e.printf(`<rect width="%d" height="%d" %s/>`+"\n", e.size.W, e.size.H, e.fill(guix.Brush{Color: o.color}))
+}
+
+func (o canvasOp) encode(e *encoder) {
+	c, ok := o.canvas.(*Canvas)
+	if !ok {
+		e.printf("<!-- %T cannot be exported -->\n", o.canvas)
+		return
+	}
+	e.printf(`<g transform="translate(%d %d)">`+"\n", o.position.X, o.position.Y)
+	e.canvas(c)
+	e.buf.WriteString("</g>\n")
+}
+
+func (o textureOp) encode(e *encoder) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, o.texture.Image()); err != nil {
+		e.printf("<!-- texture could not be encoded: %v -->\n", err)
+		return
+	}
+	r := o.bounds
+	transform := ""
+	if o.texture.FlipY() {
+		transform = fmt.Sprintf(` transform="translate(0 %d) scale(1 -1)"`, r.Min.Y+r.Max.Y)
+	}
+	e.printf(`<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="none"%s xlink:href="data:image/png;base64,%s"/>`+"\n",
+		r.Min.X, r.Min.Y, r.W(), r.H(), transform, base64.StdEncoding.EncodeToString(b.Bytes()))
+}
+
+func (o runesOp) encode(e *encoder) {
+	if len(o.runes) == 0 || o.color.A <= 0 {
+		return
+	}
+	xs := make([]string, len(o.points))
+	ys := make([]string, len(o.points))
+	for i, p := range o.points {
+		xs[i], ys[i] = strconv.Itoa(p.X), strconv.Itoa(p.Y)
+	}
+	var text bytes.Buffer
+	xml.EscapeText(&text, []byte(string(o.runes)))
+	e.printf(`<text xml:space="preserve" font-family="%s" font-size="%d" %s x="%s" y="%s">%s</text>`+"\n",
+		FontFamily, o.font.Size(), e.fill(guix.Brush{Color: o.color}),
+		strings.Join(xs, " "), strings.Join(ys, " "), text.String())
+}
+
+func (o linesOp) encode(e *encoder) {
+	if len(o.polygon) < 2 || !visible(o.pen) {
+		return
+	}
+	e.printf(`<path d="%s" fill="none" %s/>`+"\n",
+		pathData(o.polygon, false), stroke(o.pen, 1))
+}
+
+func (o polygonOp) encode(e *encoder) {
+	if len(o.polygon) < 3 {
+		return
+	}
+	d := pathData(o.polygon, true)
+	e.printf(`<path d="%s" %s/>`+"\n", d, e.fill(o.brush))
+	if visible(o.pen) {
+		// Pens are drawn along the inside of the polygon's edge. Stroke the
+		// path at double width, clipped to the polygon.
+		id := e.id("edge")
+		e.printf(`<clipPath id="%s"><path d="%s"/></clipPath>`+"\n", id, d)
+		e.printf(`<path d="%s" fill="none" %s clip-path="url(#%s)"/>`+"\n",
+			d, stroke(o.pen, 2), id)
+	}
+}
+
+func (o pathOp) encode(e *encoder) {
+	if len(o.path.Segments) == 0 {
+		return
+	}
+	fill := e.fill(o.brush)
+	if o.path.FillRule == guix.EvenOdd {
+		fill += ` fill-rule="evenodd"`
+	}
+	if visible(o.pen) {
+		fill += " " + stroke(o.pen, 1)
+	}
+	e.printf(`<path d="%s" %s/>`+"\n", pathSegmentsData(o.path), fill)
+}
+
+func (o shadowOp) encode(e *encoder) {
+	s := o.shadow
+	p := s.Path(o.rect, o.tl, o.tr, o.bl, o.br)
+	if !s.IsVisible() || len(p.Segments) == 0 {
+		return
+	}
+	filter := ""
+	if s.Blur > 0 {
+		id := e.id("shadow")
+		b := s.Bounds(o.rect)
+		e.printf(`<filter id="%s" filterUnits="userSpaceOnUse" x="%d" y="%d" width="%d" height="%d">`+
+			`<feGaussianBlur stdDeviation="%s"/></filter>`+"\n",
+			id, b.Min.X, b.Min.Y, b.W(), b.H(), num(s.Blur/2))
+		filter = fmt.Sprintf(` filter="url(#%s)"`, id)
+	}
+	e.printf(`<path d="%s" %s%s/>`+"\n", pathSegmentsData(p), e.fill(guix.CreateBrush(s.Color)), filter)
+}
+
+func (o rectOp) encode(e *encoder) {
+	if !o.brush.IsVisible() {
+		return
+	}
+	r := o.rect
+	e.printf(`<rect x="%d" y="%d" width="%d" height="%d" %s/>`+"\n",
+		r.Min.X, r.Min.Y, r.W(), r.H(), e.fill(o.brush))
+}
+
+func (o roundedRectOp) encode(e *encoder) {
+	if o.tl == 0 && o.tr == 0 && o.bl == 0 && o.br == 0 && !visible(o.pen) {
+		rectOp{o.rect, o.brush}.encode(e)
+		return
+	}
+	r := o.rect
+	polygonOp{
+		polygon: guix.Polygon{
+			guix.PolygonVertex{Position: r.TL(), RoundedRadius: o.tl},
+			guix.PolygonVertex{Position: r.TR(), RoundedRadius: o.tr},
+			guix.PolygonVertex{Position: r.BR(), RoundedRadius: o.br},
+			guix.PolygonVertex{Position: r.BL(), RoundedRadius: o.bl},
+		},
+		pen:   o.pen,
+		brush: o.brush,
+	}.encode(e)
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// pathData returns the SVG path data for the polygon p, with rounded vertices.
+// If closed is false then p is treated as a polyline and its end points are
+// not rounded.
+func pathData(p guix.Polygon, closed bool) string {
+	var b bytes.Buffer
+	point := func(cmd string, v math.Vec2) {
+		b.WriteString(cmd)
+		b.WriteString(num(v.X))
+		b.WriteByte(' ')
+		b.WriteString(num(v.Y))
+	}
+	cnt := len(p)
+	for i := 0; i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		if !closed && (i == 0 || i == cnt-1) {
+			if i == 0 {
+				point("M", a)
+			} else {
+				point("L", a)
+			}
+			continue
+		}
+		ab, ac := p.Corner(i)
+		if i == 0 {
+			point("M", ab)
+		} else {
+			point("L", ab)
+		}
+		if ab != ac {
+			point("Q", a)
+			point(" ", ac)
+		}
+	}
+	if closed {
+		b.WriteByte('Z')
+	}
+	return b.String()
+}
+
+// pathSegmentsData returns the SVG path data of the path p.
+func pathSegmentsData(p *guix.Path2D) string {
+	var b bytes.Buffer
+	point := func(cmd string, v math.Vec2) {
+		b.WriteString(cmd)
+		b.WriteString(num(v.X))
+		b.WriteByte(' ')
+		b.WriteString(num(v.Y))
+	}
+	for i, s := range p.Segments {
+		if i == 0 && s.Verb != guix.PathMoveTo {
+			point("M", math.Vec2{})
+		}
+		switch s.Verb {
+		case guix.PathMoveTo:
+			point("M", s.Points[0])
+		case guix.PathLineTo:
+			point("L", s.Points[0])
+		case guix.PathQuadTo:
+			point("Q", s.Points[0])
+			point(" ", s.Points[1])
+		case guix.PathCubicTo:
+			point("C", s.Points[0])
+			point(" ", s.Points[1])
+			point(" ", s.Points[2])
+		case guix.PathClose:
+			b.WriteByte('Z')
+		}
+	}
+	return b.String()
+}
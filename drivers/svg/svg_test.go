@@ -0,0 +1,161 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/drivers/svg"
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+	"github.com/vcaesar/guix/testing/guixtest"
+	"github.com/vcaesar/guix/themes/dark"
+)
+
+type font struct{}
+
+func (font) LoadGlyphs(first, last rune)         {}
+func (font) Size() int                           { return 12 }
+func (font) GlyphMaxSize() math.Size             { return math.Size{W: 8, H: 12} }
+func (font) Measure(*guix.TextBlock) math.Size   { return math.ZeroSize }
+func (font) Layout(*guix.TextBlock) []math.Point { return nil }
+func (font) Carets(*guix.TextBlock) []math.Point { return nil }
+
+type texture struct{}
+
+func (texture) Image() image.Image    { return image.NewRGBA(image.Rect(0, 0, 2, 2)) }
+func (texture) Size() math.Size       { return math.Size{W: 2, H: 2} }
+func (texture) SizePixels() math.Size { return math.Size{W: 2, H: 2} }
+func (texture) FlipY() bool           { return false }
+func (texture) SetFlipY(bool)         {}
+
+func encode(t *testing.T, c *svg.Canvas) string {
+	var b bytes.Buffer
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	// Check the document is well-formed.
+	d := xml.NewDecoder(bytes.NewReader(b.Bytes()))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Invalid SVG: %v\n%s", err, b.String())
+		}
+	}
+	return b.String()
+}
+
+func TestWriteTo(t *testing.T) {
+	child := svg.CreateCanvas(math.Size{W: 20, H: 10})
+	child.DrawRect(math.CreateRect(0, 0, 20, 10), guix.CreateBrush(guix.Red))
+	child.Complete()
+
+	c := svg.CreateCanvas(math.Size{W: 100, H: 50})
+	c.Clear(guix.Black)
+	c.Push()
+	c.AddClip(math.CreateRect(5, 5, 95, 45))
+	c.DrawRoundedRect(math.CreateRect(10, 10, 60, 30), 3, 3, 3, 3, guix.CreatePen(1, guix.White), guix.CreateBrush(guix.Gray50))
+	c.DrawLines(guix.Polygon{
+		guix.PolygonVertex{Position: math.Point{X: 0, Y: 0}},
+		guix.PolygonVertex{Position: math.Point{X: 50, Y: 0}, RoundedRadius: 4},
+		guix.PolygonVertex{Position: math.Point{X: 50, Y: 50}},
+	}, guix.CreatePen(2, guix.Blue))
+	dashed := guix.CreateDashedPen(1, guix.Green, 4, 2)
+	dashed.Cap = guix.RoundCap
+	c.DrawLines(guix.Polygon{
+		guix.PolygonVertex{Position: math.Point{X: 0, Y: 5}},
+		guix.PolygonVertex{Position: math.Point{X: 50, Y: 5}},
+	}, dashed)
+	c.DrawRunes(font{}, []rune("a<b"), []math.Point{{X: 1, Y: 2}, {X: 9, Y: 2}, {X: 17, Y: 2}}, guix.White)
+	c.Pop()
+	c.DrawCanvas(child, math.Point{X: 70, Y: 35})
+	c.Push()
+	c.Translate(10, 20)
+	c.Scale(2, 0.5)
+	c.DrawRect(math.CreateRect(0, 0, 5, 5), guix.CreateBrush(guix.Red))
+	c.Pop()
+	c.PushOpacity(0.25)
+	c.PushBlendMode(guix.BlendScreen)
+	c.DrawRect(math.CreateRect(0, 0, 5, 5), guix.CreateBrush(guix.Red))
+	c.Pop()
+	c.Pop()
+	c.DrawTexture(texture{}, math.CreateRect(0, 40, 10, 50))
+	p := &guix.Path2D{FillRule: guix.EvenOdd}
+	p.MoveTo(math.Vec2{X: 0, Y: 0})
+	p.CubicTo(math.Vec2{X: 5, Y: 0}, math.Vec2{X: 10, Y: 5}, math.Vec2{X: 10, Y: 10})
+	p.QuadTo(math.Vec2{X: 0, Y: 10}, math.Vec2{X: 0, Y: 0})
+	p.Close()
+	c.DrawPath(p, guix.CreatePen(1, guix.White), guix.CreateBrush(guix.Red))
+	c.DrawRect(math.CreateRect(0, 0, 10, 10), guix.CreateRadialGradientBrush(math.Vec2{X: 0.5, Y: 0.5}, 0.5,
+		guix.GradientStop{Offset: 0, Color: guix.White},
+		guix.GradientStop{Offset: 1, Color: guix.Transparent},
+	))
+	c.DrawShadow(math.CreateRect(20, 20, 40, 30), 0, 0, 0, 0, guix.CreateShadow(math.Vec2{X: 2, Y: 2}, 4, 0, guix.Black))
+	c.Complete()
+
+	s := encode(t, c)
+	for _, want := range []string{
+		`width="100" height="50" viewBox="0 0 100 50"`,
+		`<rect width="100" height="50" fill="rgb(0,0,0)"/>`,
+		`<clipPath id="clip1"><rect x="5" y="5" width="90" height="40"/></clipPath>`,
+		`<g clip-path="url(#clip1)">`,
+		`stroke="rgb(255,255,255)" stroke-width="2"`,
+		`d="M0 0L46 0Q50 0 50 4L50 50"`,
+		`stroke-width="1" stroke-linecap="round" stroke-dasharray="4 2"`,
+		`x="1 9 17" y="2 2 2">a&lt;b</text>`,
+		`<g transform="translate(70 35)">`,
+		`<rect x="0" y="0" width="20" height="10" fill="rgb(255,0,0)"/>`,
+		`<g transform="matrix(1 0 0 1 10 20)">`,
+		`<g transform="matrix(2 0 0 0.5 0 0)">`,
+		`<g opacity="0.25">`,
+		`<g opacity="1" style="isolation:isolate;mix-blend-mode:screen">`,
+		`xlink:href="data:image/png;base64,`,
+		`<radialGradient id="gradient3" cx="0.5" cy="0.5" r="0.5" spreadMethod="pad">`,
+		`<stop offset="1" stop-color="rgb(0,0,0)" stop-opacity="0"/>`,
+		`<rect x="0" y="0" width="10" height="10" fill="url(#gradient3)"/>`,
+		`<filter id="shadow4" filterUnits="userSpaceOnUse" x="16" y="16" width="32" height="22"><feGaussianBlur stdDeviation="2"/></filter>`,
+		`<path d="M22 22L42 22L42 32L22 32L22 22Z" fill="rgb(0,0,0)" filter="url(#shadow4)"/>`,
+		`<path d="M0 0C5 0 10 5 10 10Q0 10 0 0Z" fill="rgb(255,0,0)" fill-rule="evenodd" stroke="rgb(255,255,255)" stroke-width="1"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("SVG does not contain %q:\n%s", want, s)
+		}
+	}
+}
+
+func TestExportControl(t *testing.T) {
+	createTheme := func(d guix.Driver) guix.Theme { return dark.CreateTheme(svg.WrapDriver(d)) }
+	create := func(theme guix.Theme) guix.Control {
+		b := theme.CreateButton()
+		b.SetText("OK")
+		return b
+	}
+
+	// Windows of wrapped drivers are displayed as normal.
+	expected := guixtest.Render(guixtest.Options{}, create)
+	actual := guixtest.Render(guixtest.Options{CreateTheme: createTheme}, create)
+	_, count := guixtest.Compare(expected, actual, 0)
+	test.AssertEquals(t, 0, count)
+
+	guixtest.Run(guixtest.Options{CreateTheme: createTheme}, func(r *guixtest.Robot) {
+		var c guix.Canvas
+		r.Do(func() {
+			b := create(r.Theme)
+			r.Window.AddChild(b)
+		})
+		r.Do(func() { c = r.Window.Children()[0].Control.Draw() })
+		s := encode(t, c.(*svg.Canvas))
+		if !strings.Contains(s, ">OK</text>") {
+			t.Errorf("SVG does not contain button text:\n%s", s)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"unicode"
+
+	"github.com/vcaesar/guix/math"
+)
+
+// Ellipsis is the rune used to mark text removed by Ellipsize.
+const Ellipsis = '…'
+
+// EllipsisMode is where text that does not fit is replaced with an ellipsis.
+type EllipsisMode int
+
+const (
+	EllipsisNone EllipsisMode = iota
+	EllipsisStart
+	EllipsisMiddle
+	EllipsisEnd
+)
+
+// Ellipsize returns the runes of tb, shortened so that they fit within size
+// when laid out with f. Lines that are too wide have runes removed from the
+// start, middle or end as described by mode, which are replaced with an
+// Ellipsis. If there are too many lines to fit, the lines that do not fit
+// are removed and the last line that fits is ended with an Ellipsis,
+// whatever the mode. The first line is always kept. The runes of tb are
+// returned unchanged if they fit or mode is EllipsisNone.
+func Ellipsize(f Font, tb *TextBlock, size math.Size, mode EllipsisMode) []rune {
+	measure := func(runes []rune) math.Size {
+		b := *tb
+		b.Runes = runes
+		return f.Measure(&b)
+	}
+	runes := tb.Runes
+	if mode == EllipsisNone {
+		return runes
+	}
+	if s := measure(runes); s.W <= size.W && s.H <= size.H {
+		return runes
+	}
+
+	// Shorten the lines that are too wide. Wrapped lines can only be too
+	// wide if a single grapheme cluster does not fit.
+	if tb.Wrap == WrapNone || tb.WrapWidth <= 0 {
+		out := make([]rune, 0, len(runes)+1)
+		for s := 0; s <= len(runes); {
+			e := s
+			for e < len(runes) && runes[e] != '\n' {
+				e++
+			}
+			line := ellipsizeLine(runes[s:e], mode, func(r []rune) bool {
+				return measure(r).W <= size.W
+			})
+			out = append(out, line...)
+			if e < len(runes) {
+				out = append(out, '\n')
+			}
+			s = e + 1
+		}
+		runes = out
+	}
+
+	maxH := math.Max(size.H, measure(nil).H)
+	if measure(runes).H <= maxH {
+		return runes
+	}
+
+	// Find the longest prefix that fits, ending with an ellipsis.
+	bounds := graphemeBounds(runes)
+	truncate := func(k int) []rune {
+		r := trimSpaceRight(runes[:bounds[k]])
+		return append(append([]rune{}, r...), Ellipsis)
+	}
+	lo, hi := 0, len(bounds)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if measure(truncate(mid)).H <= maxH {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	for lo > 0 && measure(truncate(lo)).W > size.W {
+		lo--
+	}
+	return truncate(lo)
+}
+
+// ellipsizeLine returns line, with runes replaced by an Ellipsis as
+// described by mode until fits returns true.
+func ellipsizeLine(line []rune, mode EllipsisMode, fits func([]rune) bool) []rune {
+	if fits(line) {
+		return line
+	}
+	bounds := graphemeBounds(line)
+	n := len(bounds) - 1
+	// keep returns the line with k grapheme clusters kept.
+	keep := func(k int) []rune {
+		out := []rune{}
+		switch mode {
+		case EllipsisStart:
+			out = append(out, Ellipsis)
+			out = append(out, trimSpaceLeft(line[bounds[n-k]:])...)
+		case EllipsisMiddle:
+			out = append(out, trimSpaceRight(line[:bounds[(k+1)/2]])...)
+			out = append(out, Ellipsis)
+			out = append(out, trimSpaceLeft(line[bounds[n-k/2]:])...)
+		default:
+			out = append(out, trimSpaceRight(line[:bounds[k]])...)
+			out = append(out, Ellipsis)
+		}
+		return out
+	}
+	lo, hi := 0, n-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(keep(mid)) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return keep(lo)
+}
+
+// graphemeBounds returns the indices of the grapheme cluster boundaries of
+// runes, including 0 and len(runes).
+func graphemeBounds(runes []rune) []int {
+	bounds := []int{0}
+	for i := 0; i < len(runes); {
+		i = NextGrapheme(runes, i)
+		bounds = append(bounds, i)
+	}
+	return bounds
+}
+
+func trimSpaceLeft(runes []rune) []rune {
+	for len(runes) > 0 && unicode.IsSpace(runes[0]) {
+		runes = runes[1:]
+	}
+	return runes
+}
+
+func trimSpaceRight(runes []rune) []rune {
+	for len(runes) > 0 && unicode.IsSpace(runes[len(runes)-1]) {
+		runes = runes[:len(runes)-1]
+	}
+	return runes
+}
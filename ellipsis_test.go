@@ -0,0 +1,32 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestEllipsize(t *testing.T) {
+	f := testFace{'a', 'z', 10}
+	ellipsize := func(text string, size math.Size, mode EllipsisMode) string {
+		return string(Ellipsize(f, &TextBlock{Runes: []rune(text)}, size, mode))
+	}
+	line := math.Size{W: 50, H: 14}
+	test.AssertEquals(t, "abc", ellipsize("abc", line, EllipsisEnd))
+	test.AssertEquals(t, "abcdefghij", ellipsize("abcdefghij", line, EllipsisNone))
+	test.AssertEquals(t, "abcd…", ellipsize("abcdefghij", line, EllipsisEnd))
+	test.AssertEquals(t, "…ghij", ellipsize("abcdefghij", line, EllipsisStart))
+	test.AssertEquals(t, "ab…ij", ellipsize("abcdefghij", line, EllipsisMiddle))
+	test.AssertEquals(t, "ab…", ellipsize("ab    cdefghij", line, EllipsisEnd))
+	test.AssertEquals(t, "abcd…\nab", ellipsize("abcdefghij\nab", math.Size{W: 50, H: 28}, EllipsisEnd))
+	test.AssertEquals(t, "ab\ncd…", ellipsize("ab\ncd\nef", math.Size{W: 50, H: 30}, EllipsisEnd))
+	test.AssertEquals(t, "ab…", ellipsize("ab\ncd\nef", math.Size{W: 50, H: 5}, EllipsisStart))
+
+	tb := &TextBlock{Runes: []rune("ab cd ef gh"), Wrap: WrapWord, WrapWidth: 50}
+	test.AssertEquals(t, "ab c…", string(Ellipsize(f, tb, math.Size{W: 50, H: 14}, EllipsisEnd)))
+}
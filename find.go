@@ -0,0 +1,125 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// FindQuery describes the text searched for when finding and replacing text.
+type FindQuery struct {
+	// Text is the text to find, or a regular expression if Regexp is true.
+	Text string
+
+	// MatchCase is true if matches must have the same case as Text.
+	MatchCase bool
+
+	// WholeWord is true if matches must not start or end within a word.
+	WholeWord bool
+
+	// Regexp is true if Text is a regular expression in the syntax of the
+	// regexp package. Replacements may then refer to submatches with $1 or
+	// ${name}.
+	Regexp bool
+}
+
+// Compile returns the regular expression that finds the matches of q, or an
+// error if Text is not a valid regular expression.
+func (q FindQuery) Compile() (*regexp.Regexp, error) {
+	expr := q.Text
+	if !q.Regexp {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !q.MatchCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// FindMatch is a match of a FindQuery, found by FindQuery.Find.
+type FindMatch struct {
+	// Start and End are the rune indices of the match.
+	Start, End int
+
+	// submatches holds the byte indices of the match and its submatches in
+	// text, as returned by regexp.FindAllStringSubmatchIndex.
+	text       string
+	submatches []int
+	expr       *regexp.Regexp
+}
+
+// Selection returns the TextSelection spanning the match.
+func (m FindMatch) Selection() TextSelection {
+	return TextSelection{m.Start, m.End, false}
+}
+
+// Replacement returns the runes that replace the match with replacement.
+// Submatches of regular expression queries are expanded in replacement.
+func (m FindMatch) Replacement(replacement string) []rune {
+	if m.expr == nil {
+		return []rune(replacement)
+	}
+	return []rune(string(m.expr.ExpandString(nil, replacement, m.text, m.submatches)))
+}
+
+// Find returns the matches of q in runes, in order. Empty matches, and all
+// matches of an empty query, are ignored. An error is returned if the query
+// is not a valid regular expression.
+func (q FindQuery) Find(runes []rune) ([]FindMatch, error) {
+	if q.Text == "" {
+		return nil, nil
+	}
+	expr, err := q.Compile()
+	if err != nil {
+		return nil, err
+	}
+	var withExpr *regexp.Regexp
+	if q.Regexp {
+		withExpr = expr
+	}
+	text := string(runes)
+	matches := []FindMatch{}
+	// Byte indices are converted to rune indices as the matches are visited
+	// in order.
+	b, r := 0, 0
+	runeIndex := func(i int) int {
+		r += utf8.RuneCountInString(text[b:i])
+		b = i
+		return r
+	}
+	for _, sub := range expr.FindAllStringSubmatchIndex(text, -1) {
+		if sub[0] == sub[1] {
+			continue
+		}
+		m := FindMatch{
+			Start:      runeIndex(sub[0]),
+			End:        runeIndex(sub[1]),
+			text:       text,
+			submatches: sub,
+			expr:       withExpr,
+		}
+		if q.WholeWord && !isWholeWord(runes, m.Start, m.End) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// isWholeWord returns true if the runes [s, e) do not start or end within a
+// word.
+func isWholeWord(runes []rune, s, e int) bool {
+	inWord := func(i int) bool {
+		return i >= 0 && i < len(runes) && runeInWord(runes[i])
+	}
+	if inWord(s) && inWord(s-1) {
+		return false
+	}
+	if inWord(e-1) && inWord(e) {
+		return false
+	}
+	return true
+}
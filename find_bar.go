@@ -0,0 +1,37 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+// FindBar is a Control used to find and replace text in a TextBox. Editing
+// the query searches the target incrementally.
+type FindBar interface {
+	LinearLayout
+
+	// Target returns the TextBox searched by the find bar.
+	Target() TextBox
+
+	// SetTarget sets the TextBox searched by the find bar.
+	SetTarget(TextBox)
+
+	// Query returns the query entered in the find bar.
+	Query() FindQuery
+
+	// SetQuery sets the query shown in the find bar.
+	SetQuery(FindQuery)
+
+	// QueryError returns the error of the query, such as an invalid regular
+	// expression, or nil. The query text box is flagged while there is an
+	// error.
+	QueryError() error
+
+	// Replacement returns the replacement text entered in the find bar.
+	Replacement() string
+
+	// SetReplacement sets the replacement text shown in the find bar.
+	SetReplacement(string)
+
+	// FocusQuery gives the focus to the query text box, selecting its text.
+	FocusQuery()
+}
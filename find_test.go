@@ -0,0 +1,68 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	test "github.com/vcaesar/guix/testing"
+	"testing"
+)
+
+// findRanges returns the rune ranges of the matches of q in s.
+func findRanges(t *testing.T, q FindQuery, s string) [][2]int {
+	matches, err := q.Find([]rune(s))
+	test.AssertEquals(t, nil, err)
+	ranges := [][2]int{}
+	for _, m := range matches {
+		ranges = append(ranges, [2]int{m.Start, m.End})
+	}
+	return ranges
+}
+
+func TestFindQueryCase(t *testing.T) {
+	text := "Go go GO"
+	test.AssertEquals(t, [][2]int{{0, 2}, {3, 5}, {6, 8}}, findRanges(t, FindQuery{Text: "go"}, text))
+	test.AssertEquals(t, [][2]int{{3, 5}}, findRanges(t, FindQuery{Text: "go", MatchCase: true}, text))
+}
+
+func TestFindQueryWholeWord(t *testing.T) {
+	text := "cat concat cats cat_ cat."
+	q := FindQuery{Text: "cat", WholeWord: true}
+	test.AssertEquals(t, [][2]int{{0, 3}, {21, 24}}, findRanges(t, q, text))
+	q.Text = "cat."
+	test.AssertEquals(t, [][2]int{{21, 25}}, findRanges(t, q, text))
+}
+
+func TestFindQueryRegexp(t *testing.T) {
+	text := "a1 b22 c"
+	q := FindQuery{Text: `[a-z]\d+`}
+	test.AssertEquals(t, [][2]int{}, findRanges(t, q, text))
+	q.Regexp = true
+	test.AssertEquals(t, [][2]int{{0, 2}, {3, 6}}, findRanges(t, q, text))
+
+	// Empty matches are ignored.
+	q.Text = `\d*`
+	test.AssertEquals(t, [][2]int{{1, 2}, {4, 6}}, findRanges(t, q, text))
+
+	q.Text = "("
+	_, err := q.Find([]rune(text))
+	test.AssertEquals(t, true, err != nil)
+}
+
+func TestFindQueryRuneIndices(t *testing.T) {
+	text := "héllo wörld wörld"
+	test.AssertEquals(t, [][2]int{{6, 11}, {12, 17}}, findRanges(t, FindQuery{Text: "WÖRLD"}, text))
+	test.AssertEquals(t, [][2]int{}, findRanges(t, FindQuery{}, text))
+}
+
+func TestFindMatchReplacement(t *testing.T) {
+	text := []rune("key=value")
+	matches, _ := FindQuery{Text: `(\w+)=(?P<v>\w+)`, Regexp: true}.Find(text)
+	test.AssertEquals(t, 1, len(matches))
+	test.AssertEquals(t, "value:key", string(matches[0].Replacement("${v}:$1")))
+
+	// Replacements of plain queries are not expanded.
+	matches, _ = FindQuery{Text: "key"}.Find(text)
+	test.AssertEquals(t, "$1", string(matches[0].Replacement("$1")))
+}
@@ -15,6 +15,21 @@ type Font interface {
 	GlyphMaxSize() math.Size
 	Measure(*TextBlock) math.Size
 	Layout(*TextBlock) (offsets []math.Point)
+
+	// Carets returns the positions of a caret placed before each rune of the
+	// TextBlock, with an extra element for the end of the text. The positions
+	// are aligned in the same way as the offsets returned by Layout.
+	Carets(*TextBlock) []math.Point
+}
+
+// FontAscent returns the distance from the top of a line of text laid out
+// with f to its baseline. The caret of empty text aligned to the top of an
+// empty rect sits on the baseline.
+func FontAscent(f Font) int {
+	if carets := f.Carets(&TextBlock{}); len(carets) > 0 {
+		return carets[0].Y
+	}
+	return 0
 }
 
 // TextBlock is a sequence of runes to be laid out.
@@ -23,4 +38,37 @@ type TextBlock struct {
 	AlignRect math.Rect
 	H         HorizontalAlignment
 	V         VerticalAlignment
+
+	// Wrap is how lines wider than WrapWidth are broken. Lines are not
+	// wrapped if WrapWidth is 0.
+	Wrap      TextWrap
+	WrapWidth int
+
+	// LineSpacing is the distance between the baselines of lines, as a
+	// multiple of the line height. A LineSpacing of 0 is the same as 1.
+	LineSpacing float32
+}
+
+// TextWrap is the way that lines of text are broken to fit a width.
+type TextWrap int
+
+const (
+	// WrapNone only breaks lines at newlines.
+	WrapNone TextWrap = iota
+
+	// WrapWord breaks lines between words, and within words that do not fit
+	// on a line by themselves.
+	WrapWord
+
+	// WrapChar breaks lines between any two grapheme clusters.
+	WrapChar
+)
+
+// lineStep returns the distance between the baselines of lines of the
+// given height.
+func (t *TextBlock) lineStep(lineHeight int) int {
+	if t.LineSpacing <= 0 {
+		return lineHeight
+	}
+	return math.Round(float32(lineHeight) * t.LineSpacing)
 }
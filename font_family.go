@@ -0,0 +1,188 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// FontFamily is a Font composed of a chain of faces. Each rune is laid out
+// and drawn with the first face that has a glyph for it, so faces that cover
+// other scripts, symbols or emoji can be chained after the primary face. The
+// primary face determines the size, line height and baseline of the text.
+// Fonts with PostScript (CFF) outlines, such as Noto Sans CJK, must be loaded
+// with gxfont.Faces before they are created by Driver.CreateFont.
+//
+// Canvases draw a FontFamily by drawing each FontRun returned by Runs with
+// its face.
+type FontFamily struct {
+	faces []Font
+}
+
+// FontRun is a sequence of runes drawn with a single face of a FontFamily.
+type FontRun struct {
+	Font    Font
+	Runes   []rune
+	Offsets []math.Point
+}
+
+// CreateFontFamily returns a FontFamily that uses primary, falling back to
+// each of fallbacks in turn for runes that primary has no glyph for.
+func CreateFontFamily(primary Font, fallbacks ...Font) *FontFamily {
+	if primary == nil {
+		panic("Font cannot be nil")
+	}
+	faces := append([]Font{primary}, fallbacks...)
+	return &FontFamily{faces: faces}
+}
+
+// Faces returns the faces of the family, primary face first.
+func (f *FontFamily) Faces() []Font {
+	return append([]Font{}, f.faces...)
+}
+
+// Face returns the first face of the family that has a glyph for r, or the
+// primary face if none of them do. Faces that do not implement GlyphMetrics
+// are assumed to have glyphs for all runes.
+func (f *FontFamily) Face(r rune) Font {
+	for _, face := range f.faces {
+		if m, ok := face.(GlyphMetrics); !ok || m.HasGlyph(r) {
+			return face
+		}
+	}
+	return f.faces[0]
+}
+
+// Runs splits the runes and their offsets, as returned by Layout, into runs
+// of consecutive runes drawn with the same face.
+func (f *FontFamily) Runs(runes []rune, offsets []math.Point) []FontRun {
+	runs := []FontRun{}
+	for i, r := range runes {
+		face := f.Face(r)
+		if n := len(runs); n > 0 && runs[n-1].Font == face {
+			runs[n-1].Runes = append(runs[n-1].Runes, r)
+			runs[n-1].Offsets = append(runs[n-1].Offsets, offsets[i])
+			continue
+		}
+		runs = append(runs, FontRun{
+			Font:    face,
+			Runes:   []rune{r},
+			Offsets: []math.Point{offsets[i]},
+		})
+	}
+	return runs
+}
+
+func (f *FontFamily) shape(tb *TextBlock) (ShapedText, math.Point) {
+	t := ShapeTextBlock(tb, f.GlyphMaxSize().H, f)
+	rect, size := tb.AlignRect, t.Size
+	var origin math.Point
+	switch tb.H {
+	case AlignLeft:
+		origin.X = rect.Min.X
+	case AlignCenter:
+		origin.X = rect.Mid().X - (size.W / 2)
+	case AlignRight:
+		origin.X = rect.Max.X - size.W
+	}
+	switch tb.V {
+	case AlignTop:
+		origin.Y = rect.Min.Y
+	case AlignMiddle:
+		origin.Y = rect.Mid().Y - (size.H / 2)
+	case AlignBottom:
+		origin.Y = rect.Max.Y - size.H
+	}
+	return t, origin.AddY(FontAscent(f.faces[0]))
+}
+
+// GlyphMetrics compliance
+func (f *FontFamily) HasGlyph(r rune) bool {
+	for _, face := range f.faces {
+		if m, ok := face.(GlyphMetrics); !ok || m.HasGlyph(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FontFamily) Advance(r rune) int {
+	face := f.Face(r)
+	if m, ok := face.(GlyphMetrics); ok {
+		return m.Advance(r)
+	}
+	return face.Measure(&TextBlock{Runes: []rune{r}}).W
+}
+
+func (f *FontFamily) Kern(a, b rune) int {
+	face := f.Face(a)
+	if m, ok := face.(GlyphMetrics); ok && face == f.Face(b) {
+		return m.Kern(a, b)
+	}
+	return 0
+}
+
+// FontRenderer compliance. The settings are applied to each face that
+// implements FontRenderer, and read from the primary face.
+func (f *FontFamily) GlyphMode() GlyphMode {
+	if r, ok := f.faces[0].(FontRenderer); ok {
+		return r.GlyphMode()
+	}
+	return GlyphBitmap
+}
+
+func (f *FontFamily) SetGlyphMode(mode GlyphMode) {
+	for _, face := range f.faces {
+		SetGlyphMode(face, mode)
+	}
+}
+
+func (f *FontFamily) CoverageGamma() float32 {
+	if r, ok := f.faces[0].(FontRenderer); ok {
+		return r.CoverageGamma()
+	}
+	return DefaultCoverageGamma
+}
+
+func (f *FontFamily) SetCoverageGamma(gamma float32) {
+	for _, face := range f.faces {
+		SetCoverageGamma(face, gamma)
+	}
+}
+
+// Font compliance
+func (f *FontFamily) LoadGlyphs(first, last rune) {
+	for _, face := range f.faces {
+		face.LoadGlyphs(first, last)
+	}
+}
+
+func (f *FontFamily) Size() int {
+	return f.faces[0].Size()
+}
+
+func (f *FontFamily) GlyphMaxSize() math.Size {
+	return f.faces[0].GlyphMaxSize()
+}
+
+func (f *FontFamily) Measure(tb *TextBlock) math.Size {
+	return ShapeTextBlock(tb, f.GlyphMaxSize().H, f).Size
+}
+
+func (f *FontFamily) Layout(tb *TextBlock) (offsets []math.Point) {
+	t, origin := f.shape(tb)
+	for i, p := range t.Offsets {
+		t.Offsets[i] = p.Add(origin)
+	}
+	return t.Offsets
+}
+
+func (f *FontFamily) Carets(tb *TextBlock) []math.Point {
+	t, origin := f.shape(tb)
+	for i, p := range t.Carets {
+		t.Carets[i] = p.Add(origin)
+	}
+	return t.Carets
+}
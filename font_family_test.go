@@ -0,0 +1,83 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+// testFace is a Font with glyphs for the runes in [first, last], each advance
+// DIPs wide, and an ascent of 10.
+type testFace struct {
+	first, last rune
+	advance     int
+}
+
+func (f testFace) HasGlyph(r rune) bool        { return r >= f.first && r <= f.last }
+func (f testFace) Advance(r rune) int          { return f.advance }
+func (f testFace) Kern(a, b rune) int          { return 0 }
+func (f testFace) LoadGlyphs(first, last rune) {}
+func (f testFace) Size() int                   { return 12 }
+func (f testFace) GlyphMaxSize() math.Size     { return math.Size{W: 10, H: 14} }
+func (f testFace) Measure(tb *TextBlock) math.Size {
+	return ShapeTextBlock(tb, 14, f).Size
+}
+func (f testFace) Layout(tb *TextBlock) []math.Point { return nil }
+func (f testFace) Carets(tb *TextBlock) []math.Point {
+	return []math.Point{tb.AlignRect.Min.AddY(10)}
+}
+
+func TestFontFamily(t *testing.T) {
+	latin := testFace{'a', 'z', 8}
+	cjk := testFace{0x4e00, 0x9fff, 14}
+	f := CreateFontFamily(latin, cjk)
+
+	test.AssertEquals(t, Font(latin), f.Face('a'))
+	test.AssertEquals(t, Font(cjk), f.Face('中'))
+	test.AssertEquals(t, Font(latin), f.Face('☃'))
+
+	runes := []rune("ab中c")
+	offsets := f.Layout(&TextBlock{Runes: runes, AlignRect: math.CreateRect(5, 5, 100, 100)})
+	test.AssertEquals(t, []math.Point{{X: 5, Y: 15}, {X: 13, Y: 15}, {X: 21, Y: 15}, {X: 35, Y: 15}}, offsets)
+	test.AssertEquals(t, math.Size{W: 38, H: 14}, f.Measure(&TextBlock{Runes: runes}))
+
+	runs := f.Runs(runes, offsets)
+	test.AssertEquals(t, 3, len(runs))
+	test.AssertEquals(t, FontRun{Font: latin, Runes: []rune("ab"), Offsets: offsets[:2]}, runs[0])
+	test.AssertEquals(t, FontRun{Font: cjk, Runes: []rune("中"), Offsets: offsets[2:3]}, runs[1])
+	test.AssertEquals(t, FontRun{Font: latin, Runes: []rune("c"), Offsets: offsets[3:]}, runs[2])
+}
+
+// rendererFace is a testFace that implements FontRenderer.
+type rendererFace struct {
+	testFace
+	mode  GlyphMode
+	gamma float32
+}
+
+func (f *rendererFace) GlyphMode() GlyphMode           { return f.mode }
+func (f *rendererFace) SetGlyphMode(mode GlyphMode)    { f.mode = mode }
+func (f *rendererFace) CoverageGamma() float32         { return f.gamma }
+func (f *rendererFace) SetCoverageGamma(gamma float32) { f.gamma = gamma }
+
+func TestFontFamilyRenderer(t *testing.T) {
+	primary := &rendererFace{testFace: testFace{'a', 'z', 8}, gamma: DefaultCoverageGamma}
+	fallback := &rendererFace{testFace: testFace{0x4e00, 0x9fff, 14}, gamma: DefaultCoverageGamma}
+	f := CreateFontFamily(primary, testFace{'0', '9', 8}, fallback)
+
+	test.AssertEquals(t, GlyphBitmap, f.GlyphMode())
+	test.AssertEquals(t, true, SetGlyphMode(f, GlyphSDF))
+	test.AssertEquals(t, GlyphSDF, f.GlyphMode())
+	test.AssertEquals(t, GlyphSDF, fallback.GlyphMode())
+
+	test.AssertEquals(t, true, SetCoverageGamma(f, 2.2))
+	test.AssertEquals(t, float32(2.2), f.CoverageGamma())
+	test.AssertEquals(t, float32(2.2), fallback.CoverageGamma())
+
+	test.AssertEquals(t, false, SetGlyphMode(testFace{}, GlyphSDF))
+}
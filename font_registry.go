@@ -0,0 +1,186 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FontWeight is the thickness of the strokes of a font, from 100 (thin) to
+// 900 (black).
+type FontWeight int
+
+const (
+	FontWeightThin   FontWeight = 100
+	FontWeightLight  FontWeight = 300
+	FontWeightNormal FontWeight = 400
+	FontWeightMedium FontWeight = 500
+	FontWeightBold   FontWeight = 700
+	FontWeightBlack  FontWeight = 900
+)
+
+// FontStyle is the slant of a font.
+type FontStyle int
+
+const (
+	FontStyleNormal FontStyle = iota
+	FontStyleItalic
+)
+
+// FontKey identifies a font in a FontRegistry.
+type FontKey struct {
+	Family string
+	Weight FontWeight
+	Style  FontStyle
+	Size   int
+}
+
+// FontVariant is a variant of a theme's default font.
+type FontVariant int
+
+const (
+	FontRegular FontVariant = iota
+	FontBold
+	FontItalic
+	FontBoldItalic
+	FontHeading1
+	FontHeading2
+	FontHeading3
+)
+
+// Key returns the key of the variant of a font with the given family and
+// size. Headings are bold, and 2, 1.5 and 1.25 times the size.
+func (v FontVariant) Key(family string, size int) FontKey {
+	k := FontKey{Family: family, Weight: FontWeightNormal, Size: size}
+	switch v {
+	case FontBold:
+		k.Weight = FontWeightBold
+	case FontItalic:
+		k.Style = FontStyleItalic
+	case FontBoldItalic:
+		k.Weight, k.Style = FontWeightBold, FontStyleItalic
+	case FontHeading1:
+		k.Weight, k.Size = FontWeightBold, size*2
+	case FontHeading2:
+		k.Weight, k.Size = FontWeightBold, size*3/2
+	case FontHeading3:
+		k.Weight, k.Size = FontWeightBold, size*5/4
+	}
+	return k
+}
+
+// fontFace is the data of a font registered for a family, weight and style.
+type fontFace struct {
+	weight FontWeight
+	style  FontStyle
+	data   []byte
+}
+
+// FontRegistry holds the fonts registered for each family, weight and style,
+// and creates and caches fonts of those faces at the requested sizes. As
+// fonts are shared, so are their loaded glyphs.
+type FontRegistry struct {
+	sync.Mutex // guards families and fonts
+
+	driver   Driver
+	families map[string][]fontFace
+	fonts    map[FontKey]Font
+}
+
+// CreateFontRegistry returns an empty FontRegistry that creates fonts with
+// driver.
+func CreateFontRegistry(driver Driver) *FontRegistry {
+	return &FontRegistry{
+		driver:   driver,
+		families: make(map[string][]fontFace),
+		fonts:    make(map[FontKey]Font),
+	}
+}
+
+// Register adds the TrueType data for the given family, weight and style,
+// replacing any data registered for them before.
+func (r *FontRegistry) Register(family string, weight FontWeight, style FontStyle, data []byte) {
+	r.Lock()
+	defer r.Unlock()
+	faces := r.families[family]
+	for i, f := range faces {
+		if f.weight == weight && f.style == style {
+			faces = append(faces[:i], faces[i+1:]...)
+			break
+		}
+	}
+	r.families[family] = append(faces, fontFace{weight, style, data})
+	for k := range r.fonts {
+		if k.Family == family {
+			delete(r.fonts, k)
+		}
+	}
+}
+
+// Families returns the names of the registered families, sorted.
+func (r *FontRegistry) Families() []string {
+	r.Lock()
+	defer r.Unlock()
+	families := make([]string, 0, len(r.families))
+	for f := range r.families {
+		families = append(families, f)
+	}
+	sort.Strings(families)
+	return families
+}
+
+// match returns the key of the registered face that best matches k. Faces
+// of the requested style are preferred, then the nearest weight, preferring
+// heavier weights for bold requests and lighter weights otherwise.
+func (r *FontRegistry) match(k FontKey) (fontFace, FontKey, bool) {
+	faces := r.families[k.Family]
+	if len(faces) == 0 {
+		return fontFace{}, k, false
+	}
+	score := func(f fontFace) int {
+		d := int(f.weight - k.Weight)
+		heavier := d > 0
+		if d < 0 {
+			d = -d
+		}
+		if d != 0 && heavier != (k.Weight > FontWeightMedium) {
+			d *= 2 // Penalize weights in the wrong direction
+		}
+		if f.style != k.Style {
+			d += 10000
+		}
+		return d
+	}
+	best := faces[0]
+	for _, f := range faces[1:] {
+		if score(f) < score(best) {
+			best = f
+		}
+	}
+	return best, FontKey{k.Family, best.weight, best.style, k.Size}, true
+}
+
+// Font returns the font that best matches k, creating it if it has not been
+// requested before. If the exact weight or style is not registered for the
+// family, the nearest registered face is used.
+func (r *FontRegistry) Font(k FontKey) (Font, error) {
+	r.Lock()
+	defer r.Unlock()
+	face, key, ok := r.match(k)
+	if !ok {
+		return nil, fmt.Errorf("Font family '%s' is not registered", k.Family)
+	}
+	if f, found := r.fonts[key]; found {
+		return f, nil
+	}
+	f, err := r.driver.CreateFont(face.data, key.Size)
+	if err != nil {
+		return nil, err
+	}
+	r.fonts[key] = f
+	return f, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	test "github.com/vcaesar/guix/testing"
+)
+
+// fontDriver is a Driver that creates testFaces, recording the data and size
+// of each.
+type fontDriver struct {
+	Driver
+	created []FontKey
+}
+
+func (d *fontDriver) CreateFont(data []byte, size int) (Font, error) {
+	d.created = append(d.created, FontKey{Family: string(data), Size: size})
+	return testFace{'a', 'z', size}, nil
+}
+
+func TestFontRegistry(t *testing.T) {
+	d := &fontDriver{}
+	r := CreateFontRegistry(d)
+	r.Register("Sans", FontWeightNormal, FontStyleNormal, []byte("regular"))
+	r.Register("Sans", FontWeightBold, FontStyleNormal, []byte("bold"))
+	r.Register("Sans", FontWeightLight, FontStyleItalic, []byte("light-italic"))
+	r.Register("Mono", FontWeightNormal, FontStyleNormal, []byte("mono"))
+	test.AssertEquals(t, []string{"Mono", "Sans"}, r.Families())
+
+	font := func(k FontKey) Font {
+		f, err := r.Font(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	font(FontKey{"Sans", FontWeightNormal, FontStyleNormal, 12})
+	font(FontKey{"Sans", FontWeightBlack, FontStyleNormal, 12})
+	font(FontKey{"Sans", FontWeightMedium, FontStyleNormal, 12})
+	font(FontKey{"Sans", FontWeightNormal, FontStyleItalic, 12})
+	font(FontKey{"Mono", FontWeightBold, FontStyleItalic, 14})
+	font(FontKey{"Sans", FontWeightBold, FontStyleNormal, 24})
+	test.AssertEquals(t, []FontKey{
+		{Family: "regular", Size: 12},
+		{Family: "bold", Size: 12},
+		{Family: "light-italic", Size: 12},
+		{Family: "mono", Size: 14},
+		{Family: "bold", Size: 24},
+	}, d.created)
+
+	if _, err := r.Font(FontKey{Family: "Serif", Size: 12}); err == nil {
+		t.Error("Expected an error for an unregistered family")
+	}
+}
+
+func TestFontVariantKey(t *testing.T) {
+	test.AssertEquals(t, FontKey{"Sans", FontWeightNormal, FontStyleNormal, 12}, FontRegular.Key("Sans", 12))
+	test.AssertEquals(t, FontKey{"Sans", FontWeightBold, FontStyleItalic, 12}, FontBoldItalic.Key("Sans", 12))
+	test.AssertEquals(t, FontKey{"Sans", FontWeightBold, FontStyleNormal, 24}, FontHeading1.Key("Sans", 12))
+	test.AssertEquals(t, FontKey{"Sans", FontWeightBold, FontStyleNormal, 15}, FontHeading3.Key("Sans", 12))
+}
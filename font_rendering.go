@@ -0,0 +1,68 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+// GlyphMode is the way that a driver rasterizes the glyphs of a font. Glyphs
+// are always rasterized as grayscale coverage: subpixel (LCD) rendering is
+// not supported.
+type GlyphMode int
+
+const (
+	// GlyphBitmap rasterizes hinted glyph masks at the resolution of each
+	// window. Small text is sharpest, but glyphs are rasterized again
+	// whenever the resolution changes.
+	GlyphBitmap GlyphMode = iota
+
+	// GlyphSDF rasterizes each glyph once as a signed distance field, which
+	// is scaled to any resolution while keeping its edges crisp. This suits
+	// text that is zoomed, such as that of a code editor.
+	GlyphSDF
+)
+
+// DefaultCoverageGamma is the gamma that fonts blend their glyphs with by default.
+const DefaultCoverageGamma = 1.0
+
+// FontRenderer is implemented by fonts whose rendering can be tuned. The
+// fonts created by the gl and soft drivers implement it.
+type FontRenderer interface {
+	// GlyphMode returns the way that the glyphs of the font are rasterized.
+	GlyphMode() GlyphMode
+
+	// SetGlyphMode sets the way that the glyphs of the font are rasterized.
+	// Drivers that rasterize glyph outlines at every resolution may draw
+	// GlyphSDF fonts the same way as GlyphBitmap fonts.
+	SetGlyphMode(GlyphMode)
+
+	// Gamma returns the gamma that the coverage of glyphs is corrected with
+	// before blending.
+	CoverageGamma() float32
+
+	// SetCoverageGamma sets the gamma that the coverage of glyphs is corrected with
+	// before blending. Coverage is raised to the power of 1/gamma, so a gamma
+	// of 1 blends linearly. Gammas of around 1.8 to 2.2 compensate for
+	// blending in sRGB space, giving the thin strokes of small text their
+	// intended weight.
+	SetCoverageGamma(float32)
+}
+
+// SetGlyphMode sets the glyph mode of f if it implements FontRenderer,
+// returning true if it does.
+func SetGlyphMode(f Font, mode GlyphMode) bool {
+	if r, ok := f.(FontRenderer); ok {
+		r.SetGlyphMode(mode)
+		return true
+	}
+	return false
+}
+
+// SetCoverageGamma sets the gamma of f if it implements FontRenderer, returning true
+// if it does.
+func SetCoverageGamma(f Font, gamma float32) bool {
+	if r, ok := f.(FontRenderer); ok {
+		r.SetCoverageGamma(gamma)
+		return true
+	}
+	return false
+}
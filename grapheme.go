@@ -0,0 +1,192 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import "unicode"
+
+// graphemeBreak is the Grapheme_Cluster_Break property of a rune, as defined
+// by Unicode Standard Annex #29.
+type graphemeBreak int
+
+const (
+	gbOther graphemeBreak = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+	gbExtendedPictographic
+)
+
+func graphemeBreakOf(r rune) graphemeBreak {
+	switch {
+	case r < 0x7f && r >= 0x20:
+		return gbOther // Fast path for printable ASCII
+	case r == '\r':
+		return gbCR
+	case r == '\n':
+		return gbLF
+	case r == 0x200d:
+		return gbZWJ
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return gbRegionalIndicator
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return gbL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return gbV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return gbT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return gbLV
+		}
+		return gbLVT
+	case unicode.In(r, unicode.Mn, unicode.Me),
+		r == 0x200c,                  // Zero width non-joiner
+		r >= 0xfe00 && r <= 0xfe0f,   // Variation selectors
+		r >= 0x1f3fb && r <= 0x1f3ff, // Emoji skin tone modifiers
+		r >= 0xe0020 && r <= 0xe007f, // Tags
+		r >= 0xe0100 && r <= 0xe01ef: // Variation selectors supplement
+		return gbExtend
+	case unicode.Is(unicode.Mc, r):
+		return gbSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gbControl
+	case isExtendedPictographic(r):
+		return gbExtendedPictographic
+	}
+	return gbOther
+}
+
+// isExtendedPictographic returns true for the common emoji and pictographic
+// symbols.
+func isExtendedPictographic(r rune) bool {
+	switch {
+	case r == 0xa9, r == 0xae, r == 0x203c, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x21aa,
+		r >= 0x2300 && r <= 0x23ff,
+		r >= 0x2600 && r <= 0x27bf,
+		r >= 0x2b00 && r <= 0x2bff,
+		r == 0x3030, r == 0x303d, r == 0x3297, r == 0x3299,
+		r >= 0x1f000 && r <= 0x1faff:
+		return true
+	}
+	return false
+}
+
+// indicBlock returns the first rune of the block of r if it is one of the
+// Indic scripts that form conjuncts with a virama, or -1.
+func indicBlock(r rune) rune {
+	switch b := r &^ 0x7f; b {
+	case 0x0900, 0x0980, 0x0a80, 0x0b00, 0x0c00, 0x0d00:
+		return b // Devanagari, Bengali, Gujarati, Oriya, Telugu, Malayalam
+	}
+	return -1
+}
+
+// isIndicConsonant returns true if r is a consonant of a script with virama
+// conjuncts.
+func isIndicConsonant(r rune) bool {
+	b := indicBlock(r)
+	if b < 0 || !unicode.IsLetter(r) {
+		return false
+	}
+	o := r - b
+	return o >= 0x15 && o <= 0x39 || o >= 0x58 && o <= 0x5f || b == 0x0900 && o >= 0x78
+}
+
+// isIndicLinker returns true if r is the virama of a script with virama
+// conjuncts.
+func isIndicLinker(r rune) bool {
+	b := indicBlock(r)
+	return b >= 0 && r-b == 0x4d
+}
+
+// isConjunctLinked returns true if the consonant runes[i] is joined to the
+// consonant before it by a virama, forming a conjunct. This is rule GB9c of
+// Unicode Standard Annex #29.
+func isConjunctLinked(runes []rune, i int) bool {
+	linked := false
+	for j := i - 1; j >= 0; j-- {
+		switch r := runes[j]; {
+		case isIndicLinker(r):
+			linked = true
+		case graphemeBreakOf(r) == gbExtend:
+		default:
+			return linked && isIndicConsonant(r)
+		}
+	}
+	return false
+}
+
+// IsGraphemeBoundary returns true if there is a grapheme cluster boundary
+// before runes[i]. The start and end of runes are always boundaries.
+func IsGraphemeBoundary(runes []rune, i int) bool {
+	if i <= 0 || i >= len(runes) {
+		return true
+	}
+	a, b := graphemeBreakOf(runes[i-1]), graphemeBreakOf(runes[i])
+	switch {
+	case a == gbCR && b == gbLF:
+		return false
+	case a == gbCR, a == gbLF, a == gbControl, b == gbCR, b == gbLF, b == gbControl:
+		return true
+	case a == gbL && (b == gbL || b == gbV || b == gbLV || b == gbLVT),
+		(a == gbLV || a == gbV) && (b == gbV || b == gbT),
+		(a == gbLVT || a == gbT) && b == gbT:
+		return false // Hangul syllables
+	case b == gbExtend, b == gbZWJ, b == gbSpacingMark:
+		return false
+	case a == gbZWJ && b == gbExtendedPictographic:
+		// Emoji ZWJ sequences.
+		j := i - 2
+		for j >= 0 && graphemeBreakOf(runes[j]) == gbExtend {
+			j--
+		}
+		return j < 0 || graphemeBreakOf(runes[j]) != gbExtendedPictographic
+	case isIndicConsonant(runes[i]) && isConjunctLinked(runes, i):
+		return false // Indic conjuncts
+	case a == gbRegionalIndicator && b == gbRegionalIndicator:
+		// Flags are pairs of regional indicators.
+		n := 0
+		for j := i - 1; j >= 0 && graphemeBreakOf(runes[j]) == gbRegionalIndicator; j-- {
+			n++
+		}
+		return n%2 == 0
+	}
+	return true
+}
+
+// NextGrapheme returns the index of the first grapheme cluster boundary in
+// runes after i, or len(runes) if there is none.
+func NextGrapheme(runes []rune, i int) int {
+	if i >= len(runes) {
+		return len(runes)
+	}
+	for i++; !IsGraphemeBoundary(runes, i); i++ {
+	}
+	return i
+}
+
+// PrevGrapheme returns the index of the last grapheme cluster boundary in
+// runes before i, or 0 if there is none.
+func PrevGrapheme(runes []rune, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if i > len(runes) {
+		i = len(runes)
+	}
+	for i--; !IsGraphemeBoundary(runes, i); i-- {
+	}
+	return i
+}
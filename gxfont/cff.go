@@ -0,0 +1,292 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxfont
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// cffTolerance is the maximum distance, in font units, between a cubic curve
+// and the quadratic curves that approximate it.
+const cffTolerance = 0.5
+
+// point is a point of a TrueType glyph outline, in font units.
+type point struct {
+	x, y float64
+	on   bool // True for points on the curve, false for control points
+}
+
+// cffToTrueType converts font, a single OpenType font with CFF outlines, to a
+// TrueType font. The cubic curves of the glyphs are approximated with
+// quadratic ones, and the CFF table is replaced with glyf and loca tables.
+// The other tables are kept as they are.
+func cffToTrueType(font []byte) ([]byte, error) {
+	f, err := sfnt.Parse(font)
+	if err != nil {
+		return nil, fmt.Errorf("gxfont: %v", err)
+	}
+	tables := readTables(font)
+	if len(tables["head"]) < 54 || len(tables["maxp"]) < 6 {
+		return nil, fmt.Errorf("gxfont: bad head or maxp table")
+	}
+
+	// Loading the glyphs at one pixel per font unit gives the outlines in
+	// font units.
+	ppem := fixed.Int26_6(f.UnitsPerEm()) << 6
+	count := f.NumGlyphs()
+	glyf, loca := []byte{}, make([]byte, 4*(count+1))
+	maxPoints, maxContours := 0, 0
+	b := &sfnt.Buffer{}
+	for i := 0; i < count; i++ {
+		segments, err := f.LoadGlyph(b, sfnt.GlyphIndex(i), ppem, nil)
+		if err != nil && err != sfnt.ErrColoredGlyph {
+			return nil, fmt.Errorf("gxfont: glyph %d: %v", i, err)
+		}
+		contours := quadContours(segments)
+		points := 0
+		for _, c := range contours {
+			points += len(c)
+		}
+		if points > maxPoints {
+			maxPoints = points
+		}
+		if len(contours) > maxContours {
+			maxContours = len(contours)
+		}
+		glyf = appendGlyph(glyf, contours)
+		binary.BigEndian.PutUint32(loca[4*(i+1):], uint32(len(glyf)))
+	}
+
+	head := append([]byte(nil), tables["head"]...)
+	binary.BigEndian.PutUint16(head[50:], 1) // Long loca offsets
+	maxp := make([]byte, 32)
+	binary.BigEndian.PutUint32(maxp, 0x00010000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(count))
+	binary.BigEndian.PutUint16(maxp[6:], uint16(maxPoints))
+	binary.BigEndian.PutUint16(maxp[8:], uint16(maxContours))
+	binary.BigEndian.PutUint16(maxp[14:], 2) // maxZones
+
+	delete(tables, "CFF ")
+	delete(tables, "VORG")
+	tables["head"], tables["maxp"] = head, maxp
+	tables["glyf"], tables["loca"] = glyf, loca
+	return writeTables(tables), nil
+}
+
+// quadContours returns the contours of the glyph outline made of segments,
+// in font units with the Y axis increasing up, and in the clockwise direction
+// of TrueType outer contours. Cubic curves are approximated by quadratic
+// curves.
+func quadContours(segments sfnt.Segments) [][]point {
+	contours := [][]point{}
+	pt := func(p fixed.Point26_6) point {
+		return point{x: float64(p.X) / 64, y: -float64(p.Y) / 64, on: true}
+	}
+	var c []point
+	for _, s := range segments {
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			if len(c) > 0 {
+				contours = append(contours, closeContour(c))
+			}
+			c = []point{pt(s.Args[0])}
+		case sfnt.SegmentOpLineTo:
+			c = append(c, pt(s.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			ctrl := pt(s.Args[0])
+			ctrl.on = false
+			c = append(c, ctrl, pt(s.Args[1]))
+		case sfnt.SegmentOpCubeTo:
+			c = appendCubic(c, c[len(c)-1], pt(s.Args[0]), pt(s.Args[1]), pt(s.Args[2]))
+		}
+	}
+	if len(c) > 0 {
+		contours = append(contours, closeContour(c))
+	}
+	return contours
+}
+
+// closeContour drops the last point of c if it repeats the first, and
+// reverses the direction of c, as PostScript outer contours are
+// anticlockwise.
+func closeContour(c []point) []point {
+	if n := len(c); n > 1 && c[n-1] == c[0] {
+		c = c[:n-1]
+	}
+	for i, j := 1, len(c)-1; i < j; i, j = i+1, j-1 {
+		c[i], c[j] = c[j], c[i]
+	}
+	return c
+}
+
+// appendCubic appends to c the quadratic curves approximating the cubic curve
+// from p0 to p3 with the control points p1 and p2. The cubic curve is split
+// into as many pieces as needed for each to be approximated within
+// cffTolerance by a single quadratic curve.
+func appendCubic(c []point, p0, p1, p2, p3 point) []point {
+	// The error of the approximation of a cubic curve by a quadratic curve
+	// is bounded by √3/36 |p3 - 3p2 + 3p1 - p0|, and shrinks with the cube
+	// of the number of pieces.
+	d := math.Hypot(p3.x-3*p2.x+3*p1.x-p0.x, p3.y-3*p2.y+3*p1.y-p0.y)
+	n := int(math.Ceil(math.Cbrt(d * math.Sqrt(3) / 36 / cffTolerance)))
+	if n < 1 {
+		n = 1
+	} else if n > 16 {
+		n = 16
+	}
+
+	at := func(t float64) (pos, tangent point) {
+		u := 1 - t
+		pos = point{
+			x:  u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+			y:  u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+			on: true,
+		}
+		tangent = point{
+			x: 3 * (u*u*(p1.x-p0.x) + 2*u*t*(p2.x-p1.x) + t*t*(p3.x-p2.x)),
+			y: 3 * (u*u*(p1.y-p0.y) + 2*u*t*(p2.y-p1.y) + t*t*(p3.y-p2.y)),
+		}
+		return
+	}
+	q0, d0 := at(0)
+	for i := 1; i <= n; i++ {
+		q3, d3 := at(float64(i) / float64(n))
+		// The control points of the piece, and the control point of the
+		// quadratic curve with the same midpoint.
+		k := 1 / (3 * float64(n))
+		q1 := point{x: q0.x + k*d0.x, y: q0.y + k*d0.y}
+		q2 := point{x: q3.x - k*d3.x, y: q3.y - k*d3.y}
+		ctrl := point{x: (3*(q1.x+q2.x) - q0.x - q3.x) / 4, y: (3*(q1.y+q2.y) - q0.y - q3.y) / 4}
+		c = append(c, ctrl, q3)
+		q0, d0 = q3, d3
+	}
+	return c
+}
+
+// appendGlyph appends the TrueType simple glyph with the contours to glyf,
+// padded to 4 bytes. Glyphs without contours are empty.
+func appendGlyph(glyf []byte, contours [][]point) []byte {
+	if len(contours) == 0 {
+		return glyf
+	}
+	var xs, ys []int16
+	var flags []byte
+	ends := make([]uint16, len(contours))
+	for i, c := range contours {
+		for _, p := range c {
+			xs = append(xs, int16(math.Round(p.x)))
+			ys = append(ys, int16(math.Round(p.y)))
+			if p.on {
+				flags = append(flags, 1)
+			} else {
+				flags = append(flags, 0)
+			}
+		}
+		ends[i] = uint16(len(xs) - 1)
+	}
+	minX, minY, maxX, maxY := xs[0], ys[0], xs[0], ys[0]
+	for i := range xs {
+		if xs[i] < minX {
+			minX = xs[i]
+		}
+		if xs[i] > maxX {
+			maxX = xs[i]
+		}
+		if ys[i] < minY {
+			minY = ys[i]
+		}
+		if ys[i] > maxY {
+			maxY = ys[i]
+		}
+	}
+
+	put := func(v ...uint16) {
+		for _, v := range v {
+			glyf = append(glyf, byte(v>>8), byte(v))
+		}
+	}
+	put(uint16(len(contours)), uint16(minX), uint16(minY), uint16(maxX), uint16(maxY))
+	put(ends...)
+	put(0) // No instructions
+	glyf = append(glyf, flags...)
+	// With the short vector and same flags clear, the coordinates are 16 bit
+	// deltas from the previous point.
+	for _, coords := range [][]int16{xs, ys} {
+		prev := int16(0)
+		for _, v := range coords {
+			put(uint16(v - prev))
+			prev = v
+		}
+	}
+	for len(glyf)%4 != 0 {
+		glyf = append(glyf, 0)
+	}
+	return glyf
+}
+
+// readTables returns the tables of font, a single font whose table directory
+// has been checked to be within the data, by tag.
+func readTables(font []byte) map[string][]byte {
+	tables := map[string][]byte{}
+	count := int(binary.BigEndian.Uint16(font[4:]))
+	for i := 0; i < count; i++ {
+		rec := font[12+16*i:]
+		start := binary.BigEndian.Uint32(rec[8:])
+		length := binary.BigEndian.Uint32(rec[12:])
+		tables[string(rec[:4])] = font[start : start+length]
+	}
+	return tables
+}
+
+// writeTables returns a TrueType font holding the tables, sorted by tag.
+func writeTables(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	count := len(tags)
+	selector := 0
+	for 1<<(selector+1) <= count {
+		selector++
+	}
+	out := make([]byte, 12+16*count)
+	binary.BigEndian.PutUint32(out, tagTrueType)
+	binary.BigEndian.PutUint16(out[4:], uint16(count))
+	binary.BigEndian.PutUint16(out[6:], uint16(16<<selector))
+	binary.BigEndian.PutUint16(out[8:], uint16(selector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*count-16<<selector))
+	for i, tag := range tags {
+		t := tables[tag]
+		rec := out[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(t))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t)))
+		out = append(out, t...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+// checksum returns the checksum of a font table.
+func checksum(t []byte) uint32 {
+	sum := uint32(0)
+	for i := 0; i < len(t); i += 4 {
+		var word [4]byte
+		copy(word[:], t[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
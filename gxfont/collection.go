@@ -0,0 +1,168 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxfont
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	tagTrueType   = 0x00010000
+	tagTrue       = 0x74727565 // "true"
+	tagOpenType   = 0x4f54544f // "OTTO"
+	tagCollection = 0x74746366 // "ttcf"
+)
+
+// Faces returns the fonts in data, which holds a TrueType (.ttf) or OpenType
+// (.otf) font, or a collection of fonts (.ttc or .otc). Each font is returned
+// as the data of a single TrueType font, which can be passed to
+// guix.Driver.CreateFont. The outlines of OpenType fonts with PostScript (CFF)
+// outlines, such as NotoSansCJK-Regular.ttc, are converted to TrueType
+// outlines.
+func Faces(data []byte) ([][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("gxfont: font data is too short")
+	}
+	if binary.BigEndian.Uint32(data) != tagCollection {
+		face, err := extract(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{face}, nil
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	if count <= 0 || len(data) < 12+4*count {
+		return nil, errors.New("gxfont: bad font collection header")
+	}
+	faces := make([][]byte, count)
+	for i := range faces {
+		offset := int(binary.BigEndian.Uint32(data[12+4*i:]))
+		face, err := extract(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = face
+	}
+	return faces, nil
+}
+
+// extract returns the font whose table directory starts at offset in data as
+// a standalone font.
+func extract(data []byte, offset int) ([]byte, error) {
+	if offset < 0 || len(data)-offset < 12 {
+		return nil, errors.New("gxfont: bad font offset")
+	}
+	tag := binary.BigEndian.Uint32(data[offset:])
+	switch tag {
+	case tagTrueType, tagTrue, tagOpenType:
+	default:
+		return nil, errors.New("gxfont: unknown font format")
+	}
+	count := int(binary.BigEndian.Uint16(data[offset+4:]))
+	dir := data[offset : offset+12]
+	records := data[offset+12:]
+	if len(records) < 16*count {
+		return nil, errors.New("gxfont: font table directory is too short")
+	}
+
+	// Check every table is within data before sizing the output, as the
+	// lengths are read from the file.
+	size := 12 + 16*count
+	for i := 0; i < count; i++ {
+		rec := records[16*i : 16*i+16]
+		start := int64(binary.BigEndian.Uint32(rec[8:]))
+		length := int64(binary.BigEndian.Uint32(rec[12:]))
+		if start+length > int64(len(data)) {
+			return nil, fmt.Errorf("gxfont: table %q is out of bounds", rec[:4])
+		}
+		size += (int(length) + 3) &^ 3
+	}
+
+	// Lay out the tables after the new directory, aligned to 4 bytes.
+	out := make([]byte, 12+16*count, size)
+	copy(out, dir)
+	if tag == tagTrue {
+		binary.BigEndian.PutUint32(out, tagTrueType)
+	}
+	for i := 0; i < count; i++ {
+		rec := records[16*i : 16*i+16]
+		start := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		r := out[12+16*i : 12+16*i+16]
+		copy(r, rec)
+		binary.BigEndian.PutUint32(r[8:], uint32(len(out)))
+		out = append(out, data[start:start+length]...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	if tag == tagOpenType {
+		return cffToTrueType(out)
+	}
+	return out, nil
+}
+
+// Load reads the font file at path and returns its faces, as returned by
+// Faces.
+func Load(path string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Faces(data)
+}
+
+// Dirs is the list of directories searched by Find. It holds the system and
+// user font directories of the platform.
+var Dirs = fontDirs()
+
+func fontDirs() []string {
+	home := os.Getenv("HOME")
+	switch runtime.GOOS {
+	case "windows":
+		return []string{filepath.Join(os.Getenv("WINDIR"), "Fonts")}
+	case "darwin":
+		return []string{
+			"/System/Library/Fonts",
+			"/Library/Fonts",
+			filepath.Join(home, "Library", "Fonts"),
+		}
+	}
+	return []string{
+		"/usr/share/fonts",
+		"/usr/local/share/fonts",
+		filepath.Join(home, ".fonts"),
+		filepath.Join(home, ".local", "share", "fonts"),
+	}
+}
+
+// Find searches Dirs and their subdirectories for a font file with the given
+// name, such as "DroidSansFallbackFull.ttf", returning its path.
+func Find(name string) (string, error) {
+	errFound := errors.New("found")
+	for _, dir := range Dirs {
+		found := ""
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // Skip unreadable directories
+			}
+			if !info.IsDir() && info.Name() == name {
+				found = path
+				return errFound
+			}
+			return nil
+		})
+		if err == errFound {
+			return found, nil
+		}
+	}
+	return "", fmt.Errorf("gxfont: font %q not found", name)
+}
@@ -0,0 +1,138 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// collection returns a font collection holding the fonts.
+func collection(fonts ...[]byte) []byte {
+	header := make([]byte, 12+4*len(fonts))
+	binary.BigEndian.PutUint32(header, tagCollection)
+	binary.BigEndian.PutUint32(header[4:], 0x00010000)
+	binary.BigEndian.PutUint32(header[8:], uint32(len(fonts)))
+	data := header
+	for i, f := range fonts {
+		base := len(data)
+		binary.BigEndian.PutUint32(data[12+4*i:], uint32(base))
+		f = append([]byte{}, f...)
+		count := int(binary.BigEndian.Uint16(f[4:]))
+		for j := 0; j < count; j++ {
+			rec := f[12+16*j:]
+			binary.BigEndian.PutUint32(rec[8:], binary.BigEndian.Uint32(rec[8:])+uint32(base))
+		}
+		data = append(data, f...)
+	}
+	return data
+}
+
+func TestFacesCollection(t *testing.T) {
+	faces, err := Faces(collection(Default, Monospace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 2 {
+		t.Fatalf("Expected 2 faces, got %d", len(faces))
+	}
+	for i, src := range [][]byte{Default, Monospace} {
+		want, err := Faces(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(want[0], faces[i]) {
+			t.Errorf("Face %d differs from the original font", i)
+		}
+		if _, err := truetype.Parse(faces[i]); err != nil {
+			t.Errorf("Face %d: %v", i, err)
+		}
+	}
+}
+
+func TestFacesBadTableLength(t *testing.T) {
+	// A font whose two tables claim to be 4GB long must be rejected without
+	// allocating for them.
+	data := make([]byte, 12+16*2)
+	binary.BigEndian.PutUint32(data, tagTrueType)
+	binary.BigEndian.PutUint16(data[4:], 2)
+	for i := 0; i < 2; i++ {
+		rec := data[12+16*i:]
+		copy(rec, "glyf")
+		binary.BigEndian.PutUint32(rec[8:], 0)
+		binary.BigEndian.PutUint32(rec[12:], 0xFFFFFFFF)
+	}
+	if _, err := Faces(data); err == nil {
+		t.Error("Expected an error for out of bounds tables")
+	}
+	if _, err := Faces(collection(data)); err == nil {
+		t.Error("Expected an error for out of bounds tables in a collection")
+	}
+}
+
+func TestFacesCFF(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/CFFTest.otf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces, err := Faces(collection(Default, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 2 {
+		t.Fatalf("Expected 2 faces, got %d", len(faces))
+	}
+	src, err := sfnt.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := truetype.Parse(faces[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	upem := fixed.Int26_6(src.UnitsPerEm())
+	for _, r := range "01中" {
+		i := f.Index(r)
+		if i == 0 {
+			t.Errorf("No glyph for %q", r)
+			continue
+		}
+		want, _, err := src.GlyphBounds(&sfnt.Buffer{}, sfnt.GlyphIndex(i), upem<<6, font.HintingNone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := &truetype.GlyphBuf{}
+		if err := g.Load(f, upem<<6, i, font.HintingNone); err != nil {
+			t.Fatal(err)
+		}
+		// The Y axis of sfnt increases down, and of truetype increases up.
+		got := g.Bounds
+		got.Min.Y, got.Max.Y = -got.Max.Y, -got.Min.Y
+		if d := boundsDistance(want, got); d > 64 {
+			t.Errorf("Glyph %q: expected bounds %v, got %v", r, want, got)
+		}
+	}
+}
+
+// boundsDistance returns the largest distance between the edges of a and b.
+func boundsDistance(a, b fixed.Rectangle26_6) fixed.Int26_6 {
+	d := fixed.Int26_6(0)
+	for _, v := range []fixed.Int26_6{a.Min.X - b.Min.X, a.Min.Y - b.Min.Y, a.Max.X - b.Max.X, a.Max.Y - b.Max.Y} {
+		if v < 0 {
+			v = -v
+		}
+		if v > d {
+			d = v
+		}
+	}
+	return d
+}
@@ -9,6 +9,10 @@
 // license. Any notices distributed with applications build with guix
 // and using this package should include both the guix license and the
 // fonts license.
+//
+// Roboto is only provided in its regular style. The bold and italic faces
+// are the Go fonts, which are owned by the Go Authors and released under the
+// license of the Go project.
 package gxfont
 
 //go:generate go run mkfont.go
@@ -19,12 +23,29 @@ import (
 	"io/ioutil"
 )
 
+const (
+	// DefaultFamily is the family name of the Default font.
+	DefaultFamily = "Roboto"
+
+	// MonospaceFamily is the family name of the Monospace font.
+	MonospaceFamily = "Droid Sans Mono"
+)
+
 var (
 	// Default is the standard guix sans-serif font.
 	Default []byte = inflate(roboto_regular)
 
 	// Monospace is the standard guix fixed-width font.
 	Monospace []byte = inflate(droid_sans_mono)
+
+	// Bold is the bold face of the Default family.
+	Bold []byte = inflate(go_bold)
+
+	// Italic is the italic face of the Default family.
+	Italic []byte = inflate(go_italic)
+
+	// BoldItalic is the bold italic face of the Default family.
+	BoldItalic []byte = inflate(go_bold_italic)
 )
 
 func inflate(src []byte) []byte {
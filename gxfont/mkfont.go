@@ -4,9 +4,8 @@
 
 // +build ignore
 
-// Small program to generate roboto_regular.go and droid_sans_mono.go.
-//
-// TODO(crawshaw): italics, bold.
+// Small program to generate roboto_regular.go, droid_sans_mono.go and the
+// bold and italic Go fonts.
 package main
 
 import (
@@ -23,6 +22,9 @@ import (
 var urls = map[string]string{
 	"roboto_regular":  "https://github.com/google/fonts/raw/master/apache/roboto/Roboto-Regular.ttf",
 	"droid_sans_mono": "https://github.com/google/fonts/raw/master/apache/droidsansmono/DroidSansMono.ttf",
+	"go_bold":         "https://github.com/golang/image/raw/master/font/gofont/ttfs/Go-Bold.ttf",
+	"go_italic":       "https://github.com/golang/image/raw/master/font/gofont/ttfs/Go-Italic.ttf",
+	"go_bold_italic":  "https://github.com/golang/image/raw/master/font/gofont/ttfs/Go-Bold-Italic.ttf",
 }
 
 func write(name, url string) {
@@ -4,6 +4,10 @@
 
 package guix
 
+import (
+	"github.com/vcaesar/guix/math"
+)
+
 type KeyboardController struct {
 	window Window
 }
@@ -16,6 +20,9 @@ func CreateKeyboardController(w Window) *KeyboardController {
 	w.OnKeyUp(c.keyUp)
 	w.OnKeyRepeat(c.keyPress)
 	w.OnKeyStroke(c.keyStroke)
+	w.OnCompositionStart(c.compositionStart)
+	w.OnCompositionUpdate(c.compositionUpdate)
+	w.OnCompositionEnd(c.compositionEnd)
 	return c
 }
 
@@ -57,3 +64,43 @@ func (c *KeyboardController) keyStroke(ev KeyStrokeEvent) {
 	}
 	c.window.KeyStroke(ev)
 }
+
+// composer returns the focused control, or its nearest ancestor, that accepts
+// composed text, or nil if there is none.
+func (c *KeyboardController) composer() Composer {
+	f := Control(c.window.Focus())
+	for f != nil {
+		if composer, ok := f.(Composer); ok {
+			return composer
+		}
+		f, _ = f.Parent().(Control)
+	}
+	return nil
+}
+
+// anchorComposition anchors the input method's candidate window to the
+// pre-edit text of composer.
+func (c *KeyboardController) anchorComposition(composer Composer) {
+	o := ChildToParent(math.ZeroPoint, composer, c.window)
+	c.window.SetCompositionRect(composer.CompositionRect().Offset(o))
+}
+
+func (c *KeyboardController) compositionStart(ev CompositionEvent) {
+	if composer := c.composer(); composer != nil {
+		composer.CompositionStart(ev)
+		c.anchorComposition(composer)
+	}
+}
+
+func (c *KeyboardController) compositionUpdate(ev CompositionEvent) {
+	if composer := c.composer(); composer != nil {
+		composer.CompositionUpdate(ev)
+		c.anchorComposition(composer)
+	}
+}
+
+func (c *KeyboardController) compositionEnd(ev CompositionEvent) {
+	if composer := c.composer(); composer != nil {
+		composer.CompositionEnd(ev)
+	}
+}
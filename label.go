@@ -14,6 +14,21 @@ type Label interface {
 	SetColor(Color)
 	Multiline() bool
 	SetMultiline(bool)
+
+	// Wrap returns how lines that are wider than the label are broken.
+	// Lines are only wrapped if the label is multiline.
+	Wrap() TextWrap
+	SetWrap(TextWrap)
+
+	// LineSpacing returns the distance between the baselines of lines, as a
+	// multiple of the line height.
+	LineSpacing() float32
+	SetLineSpacing(float32)
+
+	// Ellipsis returns where text that does not fit in the label is replaced
+	// with an ellipsis.
+	Ellipsis() EllipsisMode
+	SetEllipsis(EllipsisMode)
 	SetHorizontalAlignment(HorizontalAlignment)
 	HorizontalAlignment() HorizontalAlignment
 	SetVerticalAlignment(VerticalAlignment)
@@ -17,6 +17,8 @@ type List interface {
 	SetBorderPen(Pen)
 	BackgroundBrush() Brush
 	SetBackgroundBrush(Brush)
+	Shadow() Shadow
+	SetShadow(Shadow)
 	ScrollTo(AdapterItem)
 	IsItemVisible(AdapterItem) bool
 	ItemControl(AdapterItem) Control
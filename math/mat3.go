@@ -61,6 +61,35 @@ func CreateMat3PositionToBarycentric(a, b, c Vec2) Mat3 {
 	}
 }
 
+// CreateMat3Translate returns a matrix that translates points by t.
+func CreateMat3Translate(t Vec2) Mat3 {
+	return Mat3{
+		1, 0, 0,
+		0, 1, 0,
+		t.X, t.Y, 1,
+	}
+}
+
+// CreateMat3Scale returns a matrix that scales points by s about the origin.
+func CreateMat3Scale(s Vec2) Mat3 {
+	return Mat3{
+		s.X, 0, 0,
+		0, s.Y, 0,
+		0, 0, 1,
+	}
+}
+
+// CreateMat3Rotate returns a matrix that rotates points by radians about the
+// origin. With the Y axis pointing down, positive angles rotate clockwise.
+func CreateMat3Rotate(radians float32) Mat3 {
+	s, c := Sinf(radians), Cosf(radians)
+	return Mat3{
+		+c, s, 0,
+		-s, c, 0,
+		0, 0, 1,
+	}
+}
+
 func (m Mat3) String() string {
 	s := make([]string, 9)
 	l := 0
@@ -131,3 +160,33 @@ func (m Mat3) DivS(s float32) Mat3 {
 		m.Row(2).DivS(s),
 	)
 }
+
+//       ╭          ╮   ╭          ╮
+//       │ M₀ M₁ M₂ │   │ O₀ O₁ O₂ │
+// M ⨯ O │ M₃ M₄ M₅ │ ⨯ │ O₃ O₄ O₅ │
+//       │ M₆ M₇ M₈ │   │ O₆ O₇ O₈ │
+//       ╰          ╯   ╰          ╯
+//
+// Transforming a point by the product applies M, then O.
+func (m Mat3) Mul(o Mat3) Mat3 {
+	return CreateMat3FromRows(
+		m.Row(0).MulM(o),
+		m.Row(1).MulM(o),
+		m.Row(2).MulM(o),
+	)
+}
+
+// Transform returns the point p transformed by m.
+func (m Mat3) Transform(p Vec2) Vec2 {
+	return Vec2{
+		p.X*m[0] + p.Y*m[3] + m[6],
+		p.X*m[1] + p.Y*m[4] + m[7],
+	}
+}
+
+// Scale returns the factor by which m scales areas, square rooted. For
+// matrices that scale uniformly, this is the factor by which lengths are
+// scaled.
+func (m Mat3) Scale() float32 {
+	return Sqrtf(Absf(m[0]*m[4] - m[1]*m[3]))
+}
@@ -21,3 +21,14 @@ func TestCreateMat3PositionToBarycentric(t *testing.T) {
 	test.AssertEquals(t, Vec3{0.0, 1.0, 1.0}, b.Vec3(1).MulM(m))
 	test.AssertEquals(t, Vec3{0.0, 0.0, 1.0}, c.Vec3(1).MulM(m))
 }
+
+func TestMat3Transform(t *testing.T) {
+	m := CreateMat3Scale(Vec2{2, 3}).Mul(CreateMat3Translate(Vec2{10, 20}))
+	test.AssertEquals(t, Vec2{12, 23}, m.Transform(Vec2{1, 1}))
+
+	r := CreateMat3Rotate(Pi / 2)
+	p := r.Transform(Vec2{1, 0})
+	test.AssertEquals(t, true, Absf(p.X) < 0.0001 && Absf(p.Y-1) < 0.0001)
+	test.AssertEquals(t, float32(1), r.Scale())
+	test.AssertEquals(t, float32(6), CreateMat3Scale(Vec2{4, 9}).Scale())
+}
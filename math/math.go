@@ -56,6 +56,18 @@ func Atanf(v float32) float32 {
 	return float32(math.Atan(float64(v)))
 }
 
+func Atan2f(y, x float32) float32 {
+	return float32(math.Atan2(float64(y), float64(x)))
+}
+
+func Expf(v float32) float32 {
+	return float32(math.Exp(float64(v)))
+}
+
+func Erff(v float32) float32 {
+	return float32(math.Erf(float64(v)))
+}
+
 func Sqrtf(v float32) float32 {
 	return float32(math.Sqrt(float64(v)))
 }
@@ -64,6 +76,14 @@ func Powf(v, e float32) float32 {
 	return float32(math.Pow(float64(v), float64(e)))
 }
 
+func Floorf(v float32) float32 {
+	return float32(math.Floor(float64(v)))
+}
+
+func Ceilf(v float32) float32 {
+	return float32(math.Ceil(float64(v)))
+}
+
 func Lerp(a, b int, s float32) int {
 	r := float32(b - a)
 	return a + int(r*s)
@@ -26,6 +26,7 @@ type Container struct {
 	parts.Attachable
 	parts.Container
 	parts.DrawPaint
+	parts.Identifiable
 	parts.InputEventHandler
 	parts.Layoutable
 	parts.Paddable
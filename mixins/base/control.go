@@ -21,6 +21,7 @@ type ControlOuter interface {
 type Control struct {
 	parts.Attachable
 	parts.DrawPaint
+	parts.Identifiable
 	parts.InputEventHandler
 	parts.Layoutable
 	parts.Parentable
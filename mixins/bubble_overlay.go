@@ -22,6 +22,7 @@ type BubbleOverlay struct {
 	arrowWidth  int
 	brush       guix.Brush
 	pen         guix.Pen
+	shadow      guix.Shadow
 }
 
 func (o *BubbleOverlay) Init(outer BubbleOverlayOuter, theme guix.Theme) {
@@ -81,6 +82,17 @@ func (o *BubbleOverlay) SetPen(pen guix.Pen) {
 	}
 }
 
+func (o *BubbleOverlay) Shadow() guix.Shadow {
+	return o.shadow
+}
+
+func (o *BubbleOverlay) SetShadow(shadow guix.Shadow) {
+	if o.shadow != shadow {
+		o.shadow = shadow
+		o.Redraw()
+	}
+}
+
 func (o *BubbleOverlay) Paint(c guix.Canvas) {
 	if !o.IsVisible() {
 		return
@@ -167,6 +179,9 @@ func (o *BubbleOverlay) Paint(c guix.Canvas) {
 			}
 			// fmt.Printf("D: %+v\n", p)
 		}
+		if o.shadow.IsVisible() {
+			c.DrawShadow(b, 5, 5, 5, 5, o.shadow)
+		}
 		c.DrawPolygon(p, o.pen, o.brush)
 	}
 	o.PaintChildren.Paint(c)
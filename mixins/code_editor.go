@@ -5,7 +5,6 @@
 package mixins
 
 import (
-	"fmt"
 	"github.com/vcaesar/guix"
 	"github.com/vcaesar/guix/math"
 	"strings"
@@ -20,11 +19,27 @@ type CodeEditor struct {
 	TextBox
 	outer              CodeEditorOuter
 	layers             guix.CodeSyntaxLayers
+	findLayer          *guix.CodeSyntaxLayer
 	suggestionAdapter  *SuggestionAdapter
 	suggestionList     guix.List
 	suggestionProvider guix.CodeSuggestionProvider
 	tabWidth           int
 	theme              guix.Theme
+
+	gutterVisible          bool
+	gutterDigits           int
+	gutterBrush            guix.Brush
+	lineNumberColor        guix.Color
+	currentLineNumberColor guix.Color
+	markerColors           map[guix.CodeMarkerKind]guix.Color
+	markers                guix.CodeMarkers
+	onGutterClicked        guix.Event
+
+	foldProvider    guix.CodeFoldProvider
+	foldingEnabled  bool
+	foldRanges      []guix.CodeFoldRange
+	foldRangesDirty bool // The text has changed since foldRanges was computed
+	folded          []codeFold
 }
 
 func (t *CodeEditor) updateSpans(edits []guix.TextBoxEdit) {
@@ -32,6 +47,34 @@ func (t *CodeEditor) updateSpans(edits []guix.TextBoxEdit) {
 	for _, l := range t.layers {
 		l.UpdateSpans(runeCount, edits)
 	}
+	t.markers.UpdateRuneIndices(runeCount, edits)
+	t.updateFoldsForEdits(runeCount, edits)
+	t.updateFindLayer()
+	if digits := t.lineNumberDigits(); digits != t.gutterDigits {
+		t.gutterDigits = digits
+		if t.gutterVisible {
+			t.List.DataChanged(true) // Resize the gutter of each line
+		}
+	}
+}
+
+// updateFindLayer highlights the matches of the find query while the find
+// bar is showing.
+func (t *CodeEditor) updateFindLayer() {
+	t.findLayer.Clear()
+	if t.IsFindBarShowing() {
+		matches, _ := t.controller.FindMatches(t.findQuery)
+		for _, m := range matches {
+			t.findLayer.Add(m.Start, m.End-m.Start)
+		}
+	}
+	t.onRedrawLines.Fire()
+}
+
+// paintLayers returns the layers painted by the lines. The find layer comes
+// first, so that the highlighted matches are drawn over the syntax layers.
+func (t *CodeEditor) paintLayers() guix.CodeSyntaxLayers {
+	return append(guix.CodeSyntaxLayers{t.findLayer}, t.layers...)
 }
 
 func (t *CodeEditor) Init(outer CodeEditorOuter, driver guix.Driver, theme guix.Theme, font guix.Font) {
@@ -43,8 +86,30 @@ func (t *CodeEditor) Init(outer CodeEditorOuter, driver guix.Driver, theme guix.
 	t.suggestionList = t.outer.CreateSuggestionList()
 	t.suggestionList.SetAdapter(t.suggestionAdapter)
 
+	t.findLayer = guix.CreateCodeSyntaxLayer()
+	t.findLayer.SetBackgroundColor(guix.Color{R: 0.36, G: 0.55, B: 1.0, A: 0.35})
+
+	t.gutterVisible = true
+	t.foldingEnabled = true
+	t.foldRangesDirty = true
+	t.gutterBrush = guix.TransparentBrush
+	t.lineNumberColor = guix.Gray50
+	t.currentLineNumberColor = guix.Gray90
+	t.markerColors = map[guix.CodeMarkerKind]guix.Color{
+		guix.BreakpointMarker:   {R: 0.9, G: 0.2, B: 0.2, A: 1.0},
+		guix.ErrorMarker:        {R: 1.0, G: 0.35, B: 0.35, A: 1.0},
+		guix.WarningMarker:      {R: 1.0, G: 0.8, B: 0.2, A: 1.0},
+		guix.DiffAddedMarker:    {R: 0.3, G: 0.75, B: 0.3, A: 1.0},
+		guix.DiffModifiedMarker: {R: 0.36, G: 0.55, B: 1.0, A: 1.0},
+		guix.DiffRemovedMarker:  {R: 0.9, G: 0.3, B: 0.3, A: 1.0},
+	}
+
 	t.TextBox.Init(outer, driver, theme, font)
+	t.gutterDigits = t.lineNumberDigits()
 	t.controller.OnTextChanged(t.updateSpans)
+	t.controller.OnSelectionChanged(t.revealCarets)
+	t.controller.SetLineHidden(t.IsLineHidden)
+	t.onFindQueryChanged.Listen(t.updateFindLayer)
 
 	// Interface compliance test
 	_ = guix.CodeEditor(t)
@@ -70,6 +135,83 @@ func (t *CodeEditor) SetSyntaxLayers(layers guix.CodeSyntaxLayers) {
 	t.onRedrawLines.Fire()
 }
 
+func (t *CodeEditor) FindLayer() *guix.CodeSyntaxLayer {
+	return t.findLayer
+}
+
+func (t *CodeEditor) GutterVisible() bool {
+	return t.gutterVisible
+}
+
+func (t *CodeEditor) SetGutterVisible(visible bool) {
+	if t.gutterVisible != visible {
+		t.gutterVisible = visible
+		t.List.DataChanged(true)
+	}
+}
+
+// SetGutterBrush sets the brush filling the background of the gutter.
+func (t *CodeEditor) SetGutterBrush(brush guix.Brush) {
+	t.gutterBrush = brush
+	t.onRedrawLines.Fire()
+}
+
+// SetLineNumberColors sets the color of the line numbers, and of the numbers
+// of the lines holding a caret.
+func (t *CodeEditor) SetLineNumberColors(color, current guix.Color) {
+	t.lineNumberColor = color
+	t.currentLineNumberColor = current
+	t.onRedrawLines.Fire()
+}
+
+func (t *CodeEditor) MarkerColor(kind guix.CodeMarkerKind) guix.Color {
+	return t.markerColors[kind]
+}
+
+func (t *CodeEditor) SetMarkerColor(kind guix.CodeMarkerKind, color guix.Color) {
+	t.markerColors[kind] = color
+	t.onRedrawLines.Fire()
+}
+
+func (t *CodeEditor) AddMarker(line int, kind guix.CodeMarkerKind) *guix.CodeMarker {
+	m := guix.CreateCodeMarker(kind, t.controller.LineStart(line))
+	t.markers = append(t.markers, m)
+	t.onRedrawLines.Fire()
+	return m
+}
+
+func (t *CodeEditor) RemoveMarker(m *guix.CodeMarker) {
+	if t.markers.Remove(m) {
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *CodeEditor) ClearMarkers() {
+	t.markers = nil
+	t.onRedrawLines.Fire()
+}
+
+func (t *CodeEditor) Markers() guix.CodeMarkers {
+	return append(guix.CodeMarkers(nil), t.markers...)
+}
+
+func (t *CodeEditor) MarkersAt(line int) guix.CodeMarkers {
+	var markers guix.CodeMarkers
+	for _, m := range t.markers {
+		if t.controller.LineIndex(m.RuneIndex()) == line {
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+func (t *CodeEditor) OnGutterClicked(f func(line int, ev guix.MouseEvent)) guix.EventSubscription {
+	if t.onGutterClicked == nil {
+		t.onGutterClicked = guix.CreateEvent(f)
+	}
+	return t.onGutterClicked.Listen(f)
+}
+
 func (t *CodeEditor) TabWidth() int {
 	return t.tabWidth
 }
@@ -102,7 +244,7 @@ func (t *CodeEditor) SortSuggestionList() {
 }
 
 func (t *CodeEditor) ShowSuggestionList() {
-	if t.suggestionProvider == nil || t.IsSuggestionListShowing() {
+	if t.suggestionProvider == nil || t.readOnly || t.IsSuggestionListShowing() {
 		return
 	}
 
@@ -139,10 +281,7 @@ func (t *CodeEditor) HideSuggestionList() {
 }
 
 func (t *CodeEditor) Line(idx int) TextBoxLine {
-	return guix.FindControl(t.ItemControl(idx).(guix.Parent), func(c guix.Control) bool {
-		_, b := c.(TextBoxLine)
-		return b
-	}).(TextBoxLine)
+	return t.line(idx)
 }
 
 // mixins.List overrides
@@ -154,6 +293,9 @@ func (t *CodeEditor) Click(ev guix.MouseEvent) (consume bool) {
 func (t *CodeEditor) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 	switch ev.Key {
 	case guix.KeyTab:
+		if t.readOnly {
+			return true
+		}
 		replace := true
 		for _, sel := range t.controller.Selections() {
 			s, e := sel.Range()
@@ -187,6 +329,15 @@ func (t *CodeEditor) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 		t.HideSuggestionList()
 	case guix.KeyRight:
 		t.HideSuggestionList()
+	case guix.KeyZ:
+		if ev.Modifier.Control() {
+			t.HideSuggestionList()
+		}
+	case guix.KeyF, guix.KeyF3:
+		if t.findKeyPress(ev) {
+			t.HideSuggestionList()
+			return true
+		}
 	case guix.KeyEnter:
 		controller := t.controller
 		if t.IsSuggestionListShowing() {
@@ -196,7 +347,7 @@ func (t *CodeEditor) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 			controller.ReplaceAll(text)
 			controller.Deselect(false)
 			t.HideSuggestionList()
-		} else {
+		} else if !t.readOnly {
 			t.controller.ReplaceWithNewlineKeepIndent()
 		}
 		return true
@@ -219,15 +370,18 @@ func (t *CodeEditor) KeyStroke(ev guix.KeyStrokeEvent) (consume bool) {
 
 // mixins.TextBox overrides
 func (t *CodeEditor) CreateLine(theme guix.Theme, index int) (TextBoxLine, guix.Control) {
-	lineNumber := theme.CreateLabel()
-	lineNumber.SetText(fmt.Sprintf("%.4d", index+1)) // Displayed lines start at 1
-
 	line := &CodeEditorLine{}
 	line.Init(line, theme, t, index)
+	if !t.gutterVisible {
+		return line, line
+	}
+
+	gutter := &CodeEditorGutter{}
+	gutter.Init(gutter, theme, t, index)
 
 	layout := theme.CreateLinearLayout()
 	layout.SetDirection(guix.LeftToRight)
-	layout.AddChild(lineNumber)
+	layout.AddChild(gutter)
 	layout.AddChild(line)
 
 	return line, layout
@@ -0,0 +1,281 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"sort"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// codeFold is a folded range, anchored to the starts of its first and last
+// lines so that it moves with the edits to the text.
+type codeFold struct {
+	start, end *guix.CodeMarker
+}
+
+func (t *CodeEditor) FoldProvider() guix.CodeFoldProvider {
+	return t.foldProvider
+}
+
+func (t *CodeEditor) SetFoldProvider(provider guix.CodeFoldProvider) {
+	if t.foldProvider != provider {
+		t.foldProvider = provider
+		t.foldRangesDirty = true
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *CodeEditor) FoldingEnabled() bool {
+	return t.foldingEnabled
+}
+
+func (t *CodeEditor) SetFoldingEnabled(enabled bool) {
+	if t.foldingEnabled != enabled {
+		t.foldingEnabled = enabled
+		t.foldRangesDirty = true
+		t.folded = nil
+		t.updateHiddenLines()
+		if t.gutterVisible {
+			t.List.DataChanged(true) // Resize the gutter of each line
+		}
+	}
+}
+
+func (t *CodeEditor) FoldRanges() []guix.CodeFoldRange {
+	return append([]guix.CodeFoldRange(nil), t.currentFoldRanges()...)
+}
+
+// currentFoldRanges returns the fold ranges of the text, computing them if
+// the text has changed since they were last computed.
+func (t *CodeEditor) currentFoldRanges() []guix.CodeFoldRange {
+	if t.foldRangesDirty {
+		switch {
+		case !t.foldingEnabled:
+			t.foldRanges = nil
+		case t.foldProvider != nil:
+			t.foldRanges = t.foldProvider.FoldRanges()
+		default:
+			t.foldRanges = guix.IndentFoldRanges(t.controller)
+		}
+		t.foldRangesDirty = false
+	}
+	return t.foldRanges
+}
+
+// foldRange returns the fold range starting on the line.
+func (t *CodeEditor) foldRange(line int) (guix.CodeFoldRange, bool) {
+	ranges := t.currentFoldRanges()
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].Start >= line })
+	if i < len(ranges) && ranges[i].Start == line {
+		return ranges[i], true
+	}
+	return guix.CodeFoldRange{}, false
+}
+
+// foldLines returns the range of lines of the fold.
+func (t *CodeEditor) foldLines(f codeFold) guix.CodeFoldRange {
+	return guix.CodeFoldRange{
+		Start: t.controller.LineIndex(f.start.RuneIndex()),
+		End:   t.controller.LineIndex(f.end.RuneIndex()),
+	}
+}
+
+// foldedAt returns the index of the fold starting on the line, or -1.
+func (t *CodeEditor) foldedAt(line int) int {
+	for i, f := range t.folded {
+		if t.controller.LineIndex(f.start.RuneIndex()) == line {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *CodeEditor) Fold(line int) bool {
+	r, found := t.foldRange(line)
+	if !found || !t.foldingEnabled {
+		return false
+	}
+	if t.foldedAt(line) < 0 {
+		t.addFold(r)
+		t.updateHiddenLines()
+		t.hideCarets(r)
+	}
+	return true
+}
+
+func (t *CodeEditor) Unfold(line int) bool {
+	i := t.foldedAt(line)
+	if i < 0 {
+		return false
+	}
+	t.folded = append(t.folded[:i], t.folded[i+1:]...)
+	t.updateHiddenLines()
+	return true
+}
+
+func (t *CodeEditor) ToggleFold(line int) bool {
+	if t.IsFolded(line) {
+		return t.Unfold(line)
+	}
+	return t.Fold(line)
+}
+
+func (t *CodeEditor) IsFolded(line int) bool {
+	return t.foldedAt(line) >= 0
+}
+
+func (t *CodeEditor) FoldAll() {
+	if !t.foldingEnabled {
+		return
+	}
+	ranges := t.currentFoldRanges()
+	t.folded = nil
+	for _, r := range ranges {
+		t.addFold(r)
+	}
+	t.updateHiddenLines()
+	for _, r := range ranges {
+		t.hideCarets(r)
+	}
+}
+
+func (t *CodeEditor) UnfoldAll() {
+	t.folded = nil
+	t.updateHiddenLines()
+}
+
+func (t *CodeEditor) IsLineHidden(line int) bool {
+	for _, f := range t.folded {
+		if r := t.foldLines(f); r.Start < line && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// addFold folds the range r.
+func (t *CodeEditor) addFold(r guix.CodeFoldRange) {
+	t.folded = append(t.folded, codeFold{
+		start: guix.CreateCodeMarker(0, t.controller.LineStart(r.Start)),
+		end:   guix.CreateCodeMarker(0, t.controller.LineStart(r.End)),
+	})
+}
+
+// hideCarets moves the carets in the hidden lines of the fold range to the
+// end of its first line.
+func (t *CodeEditor) hideCarets(r guix.CodeFoldRange) {
+	end := t.controller.LineEnd(r.Start)
+	moved := false
+	sel := t.controller.Selections()
+	for i, s := range sel {
+		if l := t.controller.LineIndex(s.Caret()); l > r.Start && l <= r.End {
+			sel[i] = guix.CreateTextSelection(end, end, false)
+			moved = true
+		}
+	}
+	if moved {
+		t.controller.SetSelections(sel)
+	}
+}
+
+// revealCarets unfolds the ranges hiding a caret, such as one placed by a
+// find or an undo.
+func (t *CodeEditor) revealCarets() {
+	for _, c := range t.controller.Carets() {
+		for t.IsLineHidden(t.controller.LineIndex(c)) {
+			if !t.unfoldAround(t.controller.LineIndex(c)) {
+				break
+			}
+		}
+	}
+}
+
+// unfoldAround unfolds the outermost folded range hiding the line, returning
+// false if there is none.
+func (t *CodeEditor) unfoldAround(line int) bool {
+	outer := -1
+	for _, f := range t.folded {
+		if r := t.foldLines(f); r.Start < line && line <= r.End && (outer < 0 || r.Start < outer) {
+			outer = r.Start
+		}
+	}
+	return outer >= 0 && t.Unfold(outer)
+}
+
+// updateFoldsForEdits moves the folded ranges for the edits, and marks the
+// fold ranges to be computed again when next needed.
+func (t *CodeEditor) updateFoldsForEdits(runeCount int, edits []guix.TextBoxEdit) {
+	t.foldRangesDirty = true
+	if len(t.folded) == 0 {
+		return
+	}
+	for _, f := range t.folded {
+		guix.CodeMarkers{f.start, f.end}.UpdateRuneIndices(runeCount, edits)
+	}
+	t.updateHiddenLines()
+}
+
+// updateHiddenLines drops the folds emptied by edits or starting on the same
+// line as another, and lays out the lines left visible.
+func (t *CodeEditor) updateHiddenLines() {
+	folded := t.folded[:0]
+	for _, f := range t.folded {
+		if r := t.foldLines(f); r.End > r.Start && !t.containsFold(folded, r.Start) {
+			folded = append(folded, f)
+		}
+	}
+	t.folded = folded
+
+	if len(folded) == 0 {
+		t.folded = nil
+		if t.visibleLines != nil {
+			t.setVisibleLines(nil)
+		}
+	} else {
+		ranges := make([]guix.CodeFoldRange, len(folded))
+		for i, f := range folded {
+			ranges[i] = t.foldLines(f)
+		}
+		sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+		visible := []int{}
+		hiddenTo, next := -1, 0
+		for l, count := 0, t.controller.LineCount(); l < count; l++ {
+			for ; next < len(ranges) && ranges[next].Start < l; next++ {
+				hiddenTo = math.Max(hiddenTo, ranges[next].End)
+			}
+			if l > hiddenTo {
+				visible = append(visible, l)
+			}
+		}
+		if !equalInts(visible, t.visibleLines) {
+			t.setVisibleLines(visible)
+		}
+	}
+	t.onRedrawLines.Fire()
+}
+
+// containsFold returns true if one of the folds starts on the line.
+func (t *CodeEditor) containsFold(folds []codeFold, line int) bool {
+	for _, f := range folds {
+		if t.controller.LineIndex(f.start.RuneIndex()) == line {
+			return true
+		}
+	}
+	return false
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"strconv"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/mixins/base"
+)
+
+// The width in pixels of the bar drawn by the diff markers.
+const codeEditorDiffBarWidth = 3
+
+type CodeEditorGutterOuter interface {
+	base.ControlOuter
+	PaintLineNumber(c guix.Canvas, r math.Rect)
+	PaintMarker(c guix.Canvas, r math.Rect, m *guix.CodeMarker)
+	PaintFoldToggle(c guix.Canvas, r math.Rect, folded bool)
+}
+
+// CodeEditorGutter is the part of the gutter of a CodeEditor beside a single
+// line, showing the line number, the fold toggle and the markers of the line.
+type CodeEditorGutter struct {
+	base.Control
+	outer     CodeEditorGutterOuter
+	ce        *CodeEditor
+	lineIndex int
+}
+
+func (g *CodeEditorGutter) Init(outer CodeEditorGutterOuter, theme guix.Theme, ce *CodeEditor, lineIndex int) {
+	g.Control.Init(outer, theme)
+	g.outer = outer
+	g.ce = ce
+	g.lineIndex = lineIndex
+	g.OnAttach(func() {
+		ev := g.ce.onRedrawLines.Listen(g.Redraw)
+		g.OnDetach(ev.Unlisten)
+	})
+}
+
+// markerRect returns the area in which the marker icons are drawn.
+func (g *CodeEditorGutter) markerRect() math.Rect {
+	h := g.Size().H
+	return math.CreateRect(0, 0, h, h)
+}
+
+// foldToggleRect returns the area in which the fold toggle is drawn.
+func (g *CodeEditorGutter) foldToggleRect() math.Rect {
+	s := g.Size()
+	x := s.W - codeEditorDiffBarWidth*2 - g.ce.foldToggleWidth()
+	return math.CreateRect(x, 0, x+g.ce.foldToggleWidth(), s.H)
+}
+
+// diffBarRect returns the area in which the diff markers are drawn.
+func (g *CodeEditorGutter) diffBarRect() math.Rect {
+	s := g.Size()
+	return math.CreateRect(s.W-codeEditorDiffBarWidth, 0, s.W, s.H)
+}
+
+// isDiffMarker returns true if the marker is drawn as a diff bar.
+func isDiffMarker(m *guix.CodeMarker) bool {
+	switch m.Kind() {
+	case guix.DiffAddedMarker, guix.DiffModifiedMarker, guix.DiffRemovedMarker:
+		return true
+	}
+	return false
+}
+
+func (g *CodeEditorGutter) DesiredSize(min, max math.Size) math.Size {
+	return math.Size{W: g.ce.gutterWidth(), H: max.H}
+}
+
+func (g *CodeEditorGutter) Paint(c guix.Canvas) {
+	r := g.Size().Rect()
+	c.DrawRect(r, g.ce.gutterBrush)
+
+	mr, fr := g.markerRect(), g.foldToggleRect()
+	numbers := math.CreateRect(mr.Max.X, 0, fr.Min.X, r.Max.Y)
+	g.outer.PaintLineNumber(c, numbers)
+	// A folded range keeps its toggle even if an edit has removed the range,
+	// so that it can still be unfolded.
+	folded := g.ce.IsFolded(g.lineIndex)
+	if _, found := g.ce.foldRange(g.lineIndex); found || folded {
+		g.outer.PaintFoldToggle(c, fr, folded)
+	}
+
+	for _, m := range g.ce.MarkersAt(g.lineIndex) {
+		if isDiffMarker(m) {
+			g.outer.PaintMarker(c, g.diffBarRect(), m)
+		} else {
+			g.outer.PaintMarker(c, mr, m)
+		}
+	}
+}
+
+func (g *CodeEditorGutter) PaintLineNumber(c guix.Canvas, r math.Rect) {
+	color := g.ce.lineNumberColor
+	controller := g.ce.controller
+	for _, caret := range controller.Carets() {
+		if controller.LineIndex(caret) == g.lineIndex {
+			color = g.ce.currentLineNumberColor
+			break
+		}
+	}
+	runes := []rune(strconv.Itoa(g.lineIndex + 1)) // Displayed lines start at 1
+	f := g.ce.font
+	offsets := f.Layout(&guix.TextBlock{
+		Runes:     runes,
+		AlignRect: r,
+		H:         guix.AlignRight,
+		V:         guix.AlignMiddle,
+	})
+	c.DrawRunes(f, runes, offsets, color)
+}
+
+func (g *CodeEditorGutter) PaintMarker(c guix.Canvas, r math.Rect, m *guix.CodeMarker) {
+	brush := guix.CreateBrush(g.ce.MarkerColor(m.Kind()))
+	icon := r.ContractI(r.H() / 5)
+	switch m.Kind() {
+	case guix.BreakpointMarker:
+		radius := float32(icon.H()) / 2
+		c.DrawRoundedRect(icon, radius, radius, radius, radius, guix.TransparentPen, brush)
+	case guix.ErrorMarker:
+		c.DrawRoundedRect(icon, 2, 2, 2, 2, guix.TransparentPen, brush)
+	case guix.WarningMarker:
+		c.DrawPolygon(guix.Polygon{
+			{Position: math.Point{X: icon.Mid().X, Y: icon.Min.Y}},
+			{Position: icon.BR()},
+			{Position: icon.BL()},
+		}, guix.TransparentPen, brush)
+	case guix.DiffAddedMarker, guix.DiffModifiedMarker:
+		c.DrawRect(r, brush)
+	case guix.DiffRemovedMarker:
+		// A notch at the top of the line, between the removed lines.
+		c.DrawRect(math.CreateRect(r.Min.X-codeEditorDiffBarWidth, 0, r.Max.X, 2), brush)
+	}
+}
+
+// PaintFoldToggle paints an arrow pointing right if the range starting on the
+// line is folded, or down if it is not.
+func (g *CodeEditorGutter) PaintFoldToggle(c guix.Canvas, r math.Rect, folded bool) {
+	m := r.Mid()
+	d := r.W() / 3
+	var arrow guix.Polygon
+	if folded {
+		arrow = guix.Polygon{
+			{Position: math.Point{X: m.X - d/2, Y: m.Y - d}},
+			{Position: math.Point{X: m.X + d - d/2, Y: m.Y}},
+			{Position: math.Point{X: m.X - d/2, Y: m.Y + d}},
+		}
+	} else {
+		arrow = guix.Polygon{
+			{Position: math.Point{X: m.X - d, Y: m.Y - d/2}},
+			{Position: math.Point{X: m.X + d, Y: m.Y - d/2}},
+			{Position: math.Point{X: m.X, Y: m.Y + d - d/2}},
+		}
+	}
+	c.DrawPolygon(arrow, guix.TransparentPen, guix.CreateBrush(g.ce.lineNumberColor))
+}
+
+// InputEventHandler override
+func (g *CodeEditorGutter) Click(ev guix.MouseEvent) (consume bool) {
+	if g.foldToggleRect().Contains(ev.Point) {
+		g.ce.ToggleFold(g.lineIndex)
+	}
+	for _, m := range g.ce.MarkersAt(g.lineIndex) {
+		r := g.markerRect()
+		if isDiffMarker(m) {
+			r = g.diffBarRect()
+		}
+		if r.Contains(ev.Point) {
+			m.Click(ev)
+		}
+	}
+	if g.ce.onGutterClicked != nil {
+		g.ce.onGutterClicked.Fire(g.lineIndex, ev)
+	}
+	g.Control.Click(ev)
+	return true
+}
+
+// gutterWidth returns the width of the gutter, which fits the largest line
+// number.
+func (t *CodeEditor) gutterWidth() int {
+	h := t.font.GlyphMaxSize().H
+	w := t.font.GlyphMaxSize().W
+	return h + t.gutterDigits*w + t.foldToggleWidth() + codeEditorDiffBarWidth*3
+}
+
+// foldToggleWidth returns the width of the fold toggles, which are only shown
+// if folding is enabled.
+func (t *CodeEditor) foldToggleWidth() int {
+	if !t.foldingEnabled {
+		return 0
+	}
+	return t.font.GlyphMaxSize().H * 3 / 4
+}
+
+// lineNumberDigits returns the number of digits shown for the line numbers.
+func (t *CodeEditor) lineNumberDigits() int {
+	return math.Max(len(strconv.Itoa(t.controller.LineCount())), 3)
+}
@@ -24,6 +24,7 @@ type CodeEditorLineOuter interface {
 	PaintBackgroundSpans(c guix.Canvas, info CodeEditorLinePaintInfo)
 	PaintGlyphs(c guix.Canvas, info CodeEditorLinePaintInfo)
 	PaintBorders(c guix.Canvas, info CodeEditorLinePaintInfo)
+	PaintFolded(c guix.Canvas, info CodeEditorLinePaintInfo)
 }
 
 // CodeEditorLine
@@ -45,7 +46,7 @@ func (t *CodeEditorLine) PaintBackgroundSpans(c guix.Canvas, info CodeEditorLine
 	start, _ := info.LineSpan.Span()
 	offsets := info.GlyphOffsets
 	remaining := interval.IntDataList{info.LineSpan}
-	for _, l := range t.ce.layers {
+	for _, l := range t.ce.paintLayers() {
 		if l != nil && l.BackgroundColor() != nil {
 			color := *l.BackgroundColor()
 			for _, span := range l.Spans().Overlaps(info.LineSpan) {
@@ -64,7 +65,7 @@ func (t *CodeEditorLine) PaintGlyphs(c guix.Canvas, info CodeEditorLinePaintInfo
 	start, _ := info.LineSpan.Span()
 	runes, offsets, font := info.Runes, info.GlyphOffsets, info.Font
 	remaining := interval.IntDataList{info.LineSpan}
-	for _, l := range t.ce.layers {
+	for _, l := range t.ce.paintLayers() {
 		if l != nil && l.Color() != nil {
 			color := *l.Color()
 			for _, span := range l.Spans().Overlaps(info.LineSpan) {
@@ -86,7 +87,7 @@ func (t *CodeEditorLine) PaintGlyphs(c guix.Canvas, info CodeEditorLinePaintInfo
 func (t *CodeEditorLine) PaintBorders(c guix.Canvas, info CodeEditorLinePaintInfo) {
 	start, _ := info.LineSpan.Span()
 	offsets := info.GlyphOffsets
-	for _, l := range t.ce.layers {
+	for _, l := range t.ce.paintLayers() {
 		if l != nil && l.BorderColor() != nil {
 			color := *l.BorderColor()
 			interval.Visit(l.Spans(), info.LineSpan, func(vs, ve uint64, _ int) {
@@ -98,12 +99,32 @@ func (t *CodeEditorLine) PaintBorders(c guix.Canvas, info CodeEditorLinePaintInf
 	}
 }
 
+// PaintFolded paints an ellipsis after the end of a line that starts a folded
+// range, in place of the hidden lines.
+func (t *CodeEditorLine) PaintFolded(c guix.Canvas, info CodeEditorLinePaintInfo) {
+	runes := []rune("...")
+	x := t.caretWidth // An empty line has the ellipsis at its origin
+	if n := len(info.GlyphOffsets); n > 0 {
+		x = info.GlyphOffsets[n-1].X + info.GlyphWidth*2
+	}
+	r := math.CreateRect(x, 1, x+info.GlyphWidth*len(runes), info.LineHeight-1)
+	color := t.ce.lineNumberColor
+	c.DrawRoundedRect(r, 2, 2, 2, 2, guix.CreatePen(1, color), guix.TransparentBrush)
+	offsets := info.Font.Layout(&guix.TextBlock{
+		Runes:     runes,
+		AlignRect: r,
+		H:         guix.AlignCenter,
+		V:         guix.AlignMiddle,
+	})
+	c.DrawRunes(info.Font, runes, offsets, color)
+}
+
 // DefaultTextBoxLine overrides
 func (t *CodeEditorLine) Paint(c guix.Canvas) {
 	font := t.ce.font
 	rect := t.Size().Rect().OffsetX(t.caretWidth)
 	controller := t.ce.controller
-	runes := controller.LineRunes(t.lineIndex)
+	runes := t.ce.displayRunes(controller.LineRunes(t.lineIndex))
 	start := controller.LineStart(t.lineIndex)
 	end := controller.LineEnd(t.lineIndex)
 
@@ -141,6 +162,23 @@ func (t *CodeEditorLine) Paint(c guix.Canvas) {
 
 		// Borders
 		t.outer.PaintBorders(c, info)
+
+		if t.ce.IsFolded(t.lineIndex) {
+			t.outer.PaintFolded(c, info)
+		}
+
+		// Input method pre-edit text
+		t.outer.PaintComposition(c)
+	} else {
+		t.outer.PaintPlaceholder(c)
+
+		if t.ce.IsFolded(t.lineIndex) {
+			t.outer.PaintFolded(c, CodeEditorLinePaintInfo{
+				GlyphWidth: font.GlyphMaxSize().W,
+				LineHeight: t.Size().H,
+				Font:       font,
+			})
+		}
 	}
 
 	// Carets
@@ -19,6 +19,8 @@ type DefaultTextBoxLineOuter interface {
 	PaintCaret(c guix.Canvas, top, bottom math.Point)
 	PaintSelections(c guix.Canvas)
 	PaintSelection(c guix.Canvas, top, bottom math.Point)
+	PaintComposition(c guix.Canvas)
+	PaintPlaceholder(c guix.Canvas)
 }
 
 // DefaultTextBoxLine
@@ -51,6 +53,12 @@ func (t *DefaultTextBoxLine) SetCaretWidth(width int) {
 	}
 }
 
+// CaretWidth returns the width of the carets, by which the text is offset
+// from the left of the line.
+func (t *DefaultTextBoxLine) CaretWidth() int {
+	return t.caretWidth
+}
+
 func (t *DefaultTextBoxLine) DesiredSize(min, max math.Size) math.Size {
 	return max
 }
@@ -61,6 +69,8 @@ func (t *DefaultTextBoxLine) Paint(c guix.Canvas) {
 	}
 
 	t.outer.PaintText(c)
+	t.outer.PaintPlaceholder(c)
+	t.outer.PaintComposition(c)
 
 	if t.textbox.HasFocus() {
 		t.outer.PaintCarets(c)
@@ -70,12 +80,12 @@ func (t *DefaultTextBoxLine) Paint(c guix.Canvas) {
 func (t *DefaultTextBoxLine) MeasureRunes(s, e int) math.Size {
 	controller := t.textbox.controller
 	return t.textbox.font.Measure(&guix.TextBlock{
-		Runes: controller.TextRunes()[s:e],
+		Runes: t.textbox.displayRunes(controller.TextRunes()[s:e]),
 	})
 }
 
 func (t *DefaultTextBoxLine) PaintText(c guix.Canvas) {
-	runes := []rune(t.textbox.controller.Line(t.lineIndex))
+	runes := t.textbox.displayRunes(t.textbox.controller.LineRunes(t.lineIndex))
 	f := t.textbox.font
 	offsets := f.Layout(&guix.TextBlock{
 		Runes:     runes,
@@ -92,9 +102,7 @@ func (t *DefaultTextBoxLine) PaintCarets(c guix.Canvas) {
 		e := controller.Caret(i)
 		l := controller.LineIndex(e)
 		if l == t.lineIndex {
-			s := controller.LineStart(l)
-			m := t.outer.MeasureRunes(s, e)
-			top := math.Point{X: t.caretWidth + m.W, Y: 0}
+			top := math.Point{X: t.caretWidth + t.PositionAt(e).X, Y: 0}
 			bottom := top.Add(math.Point{X: 0, Y: t.Size().H})
 			t.outer.PaintCaret(c, top, bottom)
 		}
@@ -131,25 +139,75 @@ func (t *DefaultTextBoxLine) PaintSelection(c guix.Canvas, top, bottom math.Poin
 	c.DrawRoundedRect(r, 1, 1, 1, 1, guix.TransparentPen, guix.Brush{Color: guix.Gray40})
 }
 
+// PaintPlaceholder paints the placeholder of the text box on the first line
+// while the text box is empty.
+func (t *DefaultTextBoxLine) PaintPlaceholder(c guix.Canvas) {
+	textbox := t.textbox
+	if t.lineIndex != 0 || textbox.placeholder == "" || len(textbox.controller.TextRunes()) > 0 {
+		return
+	}
+	runes := []rune(textbox.placeholder)
+	f := textbox.font
+	offsets := f.Layout(&guix.TextBlock{
+		Runes:     runes,
+		AlignRect: t.Size().Rect().OffsetX(t.caretWidth),
+		H:         guix.AlignLeft,
+		V:         guix.AlignBottom,
+	})
+	c.DrawRunes(f, runes, offsets, textbox.placeholderColor)
+}
+
+// PaintComposition underlines the pre-edit text of an input method.
+func (t *DefaultTextBoxLine) PaintComposition(c guix.Canvas) {
+	controller := t.textbox.controller
+	s, e := controller.Composition()
+	s = math.Max(s, controller.LineStart(t.lineIndex))
+	e = math.Min(e, controller.LineEnd(t.lineIndex))
+	if s >= e {
+		return
+	}
+	y := t.Size().H
+	r := math.CreateRect(t.caretWidth+t.PositionAt(s).X, y-1, t.caretWidth+t.PositionAt(e).X, y)
+	c.DrawRect(r, guix.CreateBrush(t.textbox.textColor))
+}
+
+// carets returns the positions of the carets before each rune of the line,
+// and at its end.
+func (t *DefaultTextBoxLine) carets() []math.Point {
+	line := t.textbox.controller.LineRunes(t.lineIndex)
+	return t.textbox.font.Carets(&guix.TextBlock{Runes: t.textbox.displayRunes(line)})
+}
+
 // TextBoxLine compliance
 func (t *DefaultTextBoxLine) RuneIndexAt(p math.Point) int {
-	font := t.textbox.font
 	controller := t.textbox.controller
 
-	x := p.X
-	line := controller.Line(t.lineIndex)
-	i := 0
-	for ; i < len(line) && x > font.Measure(&guix.TextBlock{Runes: []rune(line[:i+1])}).W; i++ {
+	// Pick the nearest caret at a grapheme cluster boundary.
+	runes := []rune(controller.Line(t.lineIndex))
+	carets := t.carets()
+	best, bestDist := 0, -1
+	for i := 0; i <= len(runes); i = guix.NextGrapheme(runes, i) {
+		d := carets[i].X - p.X
+		if d < 0 {
+			d = -d
+		}
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+		if i == len(runes) {
+			break
+		}
 	}
 
-	return controller.LineStart(t.lineIndex) + i
+	return controller.LineStart(t.lineIndex) + best
 }
 
 func (t *DefaultTextBoxLine) PositionAt(runeIndex int) math.Point {
-	font := t.textbox.font
 	controller := t.textbox.controller
 
 	x := runeIndex - controller.LineStart(t.lineIndex)
-	line := controller.Line(t.lineIndex)
-	return font.Measure(&guix.TextBlock{Runes: []rune(line[:x])}).Point()
+	return math.Point{
+		X: t.carets()[x].X,
+		Y: t.textbox.font.GlyphMaxSize().H,
+	}
 }
@@ -0,0 +1,189 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"github.com/vcaesar/guix"
+)
+
+type FindBarOuter interface {
+	LinearLayoutOuter
+}
+
+type FindBar struct {
+	LinearLayout
+
+	outer       FindBarOuter
+	target      guix.TextBox
+	query       guix.TextBox
+	replacement guix.TextBox
+	matchCase   guix.Button
+	wholeWord   guix.Button
+	regexp      guix.Button
+	queryError  error
+	updating    bool
+}
+
+func (b *FindBar) Init(outer FindBarOuter, theme guix.Theme) {
+	b.LinearLayout.Init(outer, theme)
+	b.outer = outer
+	b.SetDirection(guix.TopToBottom)
+
+	button := func(row guix.LinearLayout, text string, f func()) guix.Button {
+		btn := theme.CreateButton()
+		btn.SetText(text)
+		btn.OnClick(func(guix.MouseEvent) {
+			if b.target != nil {
+				f()
+			}
+		})
+		row.AddChild(btn)
+		return btn
+	}
+	toggle := func(row guix.LinearLayout, text string) guix.Button {
+		btn := button(row, text, b.queryChanged)
+		btn.SetType(guix.ToggleButton)
+		return btn
+	}
+
+	find := theme.CreateLinearLayout()
+	find.SetDirection(guix.LeftToRight)
+	b.query = theme.CreateTextBox()
+	b.query.SetDesiredWidth(160)
+	b.query.OnTextChanged(func([]guix.TextBoxEdit) { b.queryChanged() })
+	find.AddChild(b.query)
+	b.matchCase = toggle(find, "Aa")
+	b.wholeWord = toggle(find, "W")
+	b.regexp = toggle(find, ".*")
+	button(find, "<", func() { b.setQueryError(b.target.FindPrevious()) })
+	button(find, ">", func() { b.setQueryError(b.target.FindNext()) })
+	button(find, "All", func() {
+		n, err := b.target.SelectAllMatches()
+		b.setQueryError(n, err)
+		if n > 0 {
+			b.close()
+		}
+	})
+	button(find, "x", b.close)
+	b.AddChild(find)
+
+	replace := theme.CreateLinearLayout()
+	replace.SetDirection(guix.LeftToRight)
+	b.replacement = theme.CreateTextBox()
+	b.replacement.SetDesiredWidth(160)
+	replace.AddChild(b.replacement)
+	button(replace, "Replace", func() { b.setQueryError(b.target.ReplaceMatch(b.Replacement())) })
+	button(replace, "Replace all", func() { b.setQueryError(b.target.ReplaceAllMatches(b.Replacement())) })
+	b.AddChild(replace)
+
+	// Interface compliance test
+	_ = guix.FindBar(b)
+}
+
+// queryChanged searches the target incrementally when the query is edited.
+func (b *FindBar) queryChanged() {
+	if b.target == nil || b.updating {
+		return
+	}
+	b.target.SetFindQuery(b.Query())
+	b.setQueryError(b.target.FindNext())
+}
+
+// setQueryError takes the results of a find of the target, flagging the query
+// text box if the find returned an error.
+func (b *FindBar) setQueryError(_ interface{}, err error) {
+	b.queryError = err
+	// The query text box flags the error as a validation error.
+	b.query.SetValidator(func(string) error { return b.queryError })
+}
+
+// close hides the find bar and returns the focus to the target.
+func (b *FindBar) close() {
+	b.target.HideFindBar()
+	if b.target.Attached() {
+		guix.SetFocus(b.target)
+	}
+}
+
+func (b *FindBar) Target() guix.TextBox {
+	return b.target
+}
+
+func (b *FindBar) SetTarget(target guix.TextBox) {
+	b.target = target
+}
+
+func (b *FindBar) Query() guix.FindQuery {
+	return guix.FindQuery{
+		Text:      b.query.Text(),
+		MatchCase: b.matchCase.IsChecked(),
+		WholeWord: b.wholeWord.IsChecked(),
+		Regexp:    b.regexp.IsChecked(),
+	}
+}
+
+func (b *FindBar) SetQuery(q guix.FindQuery) {
+	b.updating = true
+	defer func() { b.updating = false }()
+	if b.query.Text() != q.Text {
+		b.query.SetText(q.Text)
+	}
+	b.matchCase.SetChecked(q.MatchCase)
+	b.wholeWord.SetChecked(q.WholeWord)
+	b.regexp.SetChecked(q.Regexp)
+	_, err := q.Compile()
+	b.setQueryError(nil, err)
+}
+
+func (b *FindBar) QueryError() error {
+	return b.queryError
+}
+
+func (b *FindBar) Replacement() string {
+	return b.replacement.Text()
+}
+
+func (b *FindBar) SetReplacement(replacement string) {
+	b.replacement.SetText(replacement)
+}
+
+func (b *FindBar) FocusQuery() {
+	guix.SetFocus(b.query)
+	b.query.SelectAll()
+}
+
+// InputEventHandler override
+func (b *FindBar) KeyPress(ev guix.KeyboardEvent) (consume bool) {
+	if b.target == nil {
+		return b.LinearLayout.KeyPress(ev)
+	}
+	switch ev.Key {
+	case guix.KeyEnter:
+		if ev.Modifier.Shift() {
+			b.setQueryError(b.target.FindPrevious())
+		} else {
+			b.setQueryError(b.target.FindNext())
+		}
+		return true
+	case guix.KeyEscape:
+		b.close()
+		return true
+	case guix.KeyTab:
+		// Consumed so that the target does not indent its selections
+		if b.query.HasFocus() {
+			b.replacement.SelectAll()
+			guix.SetFocus(b.replacement)
+		} else {
+			b.FocusQuery()
+		}
+		return true
+	case guix.KeyF:
+		if ev.Modifier.Control() {
+			b.FocusQuery()
+			return true
+		}
+	}
+	return b.LinearLayout.KeyPress(ev)
+}
@@ -25,6 +25,9 @@ type Label struct {
 	horizontalAlignment guix.HorizontalAlignment
 	verticalAlignment   guix.VerticalAlignment
 	multiline           bool
+	wrap                guix.TextWrap
+	lineSpacing         float32
+	ellipsis            guix.EllipsisMode
 	text                string
 }
 
@@ -38,6 +41,7 @@ func (l *Label) Init(outer LabelOuter, theme guix.Theme, font guix.Font, color g
 	l.color = color
 	l.horizontalAlignment = guix.AlignLeft
 	l.verticalAlignment = guix.AlignMiddle
+	l.lineSpacing = 1
 	// Interface compliance test
 	_ = guix.Label(l)
 }
@@ -86,12 +90,57 @@ func (l *Label) SetMultiline(multiline bool) {
 	}
 }
 
-func (l *Label) DesiredSize(min, max math.Size) math.Size {
+func (l *Label) Wrap() guix.TextWrap {
+	return l.wrap
+}
+
+func (l *Label) SetWrap(wrap guix.TextWrap) {
+	if l.wrap != wrap {
+		l.wrap = wrap
+		l.outer.Relayout()
+	}
+}
+
+func (l *Label) LineSpacing() float32 {
+	return l.lineSpacing
+}
+
+func (l *Label) SetLineSpacing(lineSpacing float32) {
+	if l.lineSpacing != lineSpacing {
+		l.lineSpacing = lineSpacing
+		l.outer.Relayout()
+	}
+}
+
+func (l *Label) Ellipsis() guix.EllipsisMode {
+	return l.ellipsis
+}
+
+func (l *Label) SetEllipsis(ellipsis guix.EllipsisMode) {
+	if l.ellipsis != ellipsis {
+		l.ellipsis = ellipsis
+		l.outer.Redraw()
+	}
+}
+
+// textBlock returns the TextBlock of the label's text, wrapped to width.
+func (l *Label) textBlock(width int) *guix.TextBlock {
 	t := l.text
 	if !l.multiline {
 		t = strings.Replace(t, "\n", " ", -1)
 	}
-	s := l.font.Measure(&guix.TextBlock{Runes: []rune(t)})
+	tb := &guix.TextBlock{
+		Runes:       []rune(t),
+		LineSpacing: l.lineSpacing,
+	}
+	if l.multiline {
+		tb.Wrap, tb.WrapWidth = l.wrap, width
+	}
+	return tb
+}
+
+func (l *Label) DesiredSize(min, max math.Size) math.Size {
+	s := l.font.Measure(l.textBlock(max.W))
 	return s.Clamp(min, max)
 }
 
@@ -120,17 +169,12 @@ func (l *Label) VerticalAlignment() guix.VerticalAlignment {
 // parts.DrawPaint overrides
 func (l *Label) Paint(c guix.Canvas) {
 	r := l.outer.Size().Rect()
-	t := l.text
-	if !l.multiline {
-		t = strings.Replace(t, "\n", " ", -1)
-	}
-
-	runes := []rune(t)
-	offsets := l.font.Layout(&guix.TextBlock{
-		Runes:     runes,
-		AlignRect: r,
-		H:         l.horizontalAlignment,
-		V:         l.verticalAlignment,
-	})
-	c.DrawRunes(l.font, runes, offsets, l.color)
+	tb := l.textBlock(r.W())
+	tb.AlignRect = r
+	tb.H = l.horizontalAlignment
+	tb.V = l.verticalAlignment
+	tb.Runes = guix.Ellipsize(l.font, tb, r.Size(), l.ellipsis)
+
+	offsets := l.font.Layout(tb)
+	c.DrawRunes(l.font, tb.Runes, offsets, l.color)
 }
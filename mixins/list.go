@@ -134,7 +134,7 @@ func (l *List) LayoutChildren() {
 		if found {
 			if details.mark == mark {
 				panic(fmt.Errorf("Adapter for control '%s' returned duplicate item (%v) for indices %v and %v",
-					guix.Path(l.outer), item, details.index, idx))
+					guix.ControlPath(l.outer), item, details.index, idx))
 			}
 		} else {
 			control := l.adapter.Create(l.theme, idx)
@@ -15,9 +15,10 @@ type BackgroundBorderPainterOuter interface {
 }
 
 type BackgroundBorderPainter struct {
-	outer BackgroundBorderPainterOuter
-	brush guix.Brush
-	pen   guix.Pen
+	outer  BackgroundBorderPainterOuter
+	brush  guix.Brush
+	pen    guix.Pen
+	shadow guix.Shadow
 }
 
 func (b *BackgroundBorderPainter) Init(outer BackgroundBorderPainterOuter) {
@@ -27,8 +28,11 @@ func (b *BackgroundBorderPainter) Init(outer BackgroundBorderPainterOuter) {
 }
 
 func (b *BackgroundBorderPainter) PaintBackground(c guix.Canvas, r math.Rect) {
-	if b.brush.Color.A != 0 {
-		w := b.pen.Width
+	w := b.pen.Width
+	if b.shadow.IsVisible() {
+		c.DrawShadow(r, w, w, w, w, b.shadow)
+	}
+	if b.brush.IsVisible() {
 		c.DrawRoundedRect(r, w, w, w, w, guix.TransparentPen, b.brush)
 	}
 }
@@ -61,3 +65,14 @@ func (b *BackgroundBorderPainter) SetBorderPen(pen guix.Pen) {
 		b.outer.Redraw()
 	}
 }
+
+func (b *BackgroundBorderPainter) Shadow() guix.Shadow {
+	return b.shadow
+}
+
+func (b *BackgroundBorderPainter) SetShadow(shadow guix.Shadow) {
+	if b.shadow != shadow {
+		b.shadow = shadow
+		b.outer.Redraw()
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parts
+
+type Identifiable struct {
+	id string
+}
+
+func (i *Identifiable) ID() string {
+	return i.id
+}
+
+func (i *Identifiable) SetID(id string) {
+	i.id = id
+}
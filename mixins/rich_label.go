@@ -0,0 +1,294 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"unicode"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/mixins/base"
+)
+
+type RichLabelOuter interface {
+	base.ControlOuter
+}
+
+// richRun is a sequence of runes of a RichLabel laid out on a single line
+// with the same attributes.
+type richRun struct {
+	font     guix.Font
+	runes    []rune
+	offsets  []math.Point
+	attrs    guix.TextAttributes
+	rect     math.Rect // The bounds of the run, the height of the line
+	baseline int
+}
+
+type RichLabel struct {
+	base.Control
+
+	outer         RichLabelOuter
+	text          *guix.AttributedString
+	font          guix.Font
+	color         guix.Color
+	linkColor     guix.Color
+	wrap          guix.TextWrap
+	onLinkClicked guix.Event
+
+	layoutWidth int
+	layoutRuns  []richRun
+	layoutSize  math.Size
+}
+
+func (l *RichLabel) Init(outer RichLabelOuter, theme guix.Theme, font guix.Font, color, linkColor guix.Color) {
+	if font == nil {
+		panic("Cannot create a rich label with a nil font")
+	}
+	l.Control.Init(outer, theme)
+	l.outer = outer
+	l.text = guix.CreateAttributedString("")
+	l.font = font
+	l.color = color
+	l.linkColor = linkColor
+	l.wrap = guix.WrapWord
+	l.layoutWidth = -1
+	// Interface compliance test
+	_ = guix.RichLabel(l)
+}
+
+func (l *RichLabel) invalidate() {
+	l.layoutWidth = -1
+	l.outer.Relayout()
+}
+
+func (l *RichLabel) Text() *guix.AttributedString {
+	return l.text
+}
+
+func (l *RichLabel) SetText(text *guix.AttributedString) {
+	if text == nil {
+		text = guix.CreateAttributedString("")
+	}
+	l.text = text
+	l.invalidate()
+}
+
+func (l *RichLabel) Font() guix.Font {
+	return l.font
+}
+
+func (l *RichLabel) SetFont(font guix.Font) {
+	if l.font != font {
+		l.font = font
+		l.invalidate()
+	}
+}
+
+func (l *RichLabel) Color() guix.Color {
+	return l.color
+}
+
+func (l *RichLabel) SetColor(color guix.Color) {
+	if l.color != color {
+		l.color = color
+		l.outer.Redraw()
+	}
+}
+
+func (l *RichLabel) LinkColor() guix.Color {
+	return l.linkColor
+}
+
+func (l *RichLabel) SetLinkColor(color guix.Color) {
+	if l.linkColor != color {
+		l.linkColor = color
+		l.outer.Redraw()
+	}
+}
+
+func (l *RichLabel) Wrap() guix.TextWrap {
+	return l.wrap
+}
+
+func (l *RichLabel) SetWrap(wrap guix.TextWrap) {
+	if l.wrap != wrap {
+		l.wrap = wrap
+		l.invalidate()
+	}
+}
+
+func (l *RichLabel) OnLinkClicked(f func(guix.MouseEvent, string)) guix.EventSubscription {
+	if l.onLinkClicked == nil {
+		l.onLinkClicked = guix.CreateEvent(f)
+	}
+	return l.onLinkClicked.Listen(f)
+}
+
+// LinkAt returns the link of the span at the point p, or an empty string if
+// there is no link at p.
+func (l *RichLabel) LinkAt(p math.Point) string {
+	for _, r := range l.layout(l.outer.Size().W) {
+		if r.attrs.Link != "" && r.rect.Contains(p) {
+			return r.attrs.Link
+		}
+	}
+	return ""
+}
+
+// pieces splits the runes [start, end) into the pieces that lines may be
+// broken between, depending on the wrap mode. Each newline is a piece of
+// its own.
+func (l *RichLabel) pieces(start, end int) [][2]int {
+	runes := l.text.Runes()[:end]
+	pieces := [][2]int{}
+	for s := start; s < end; {
+		e := s
+		switch {
+		case runes[s] == '\n':
+			e++
+		case l.wrap == guix.WrapChar:
+			e = guix.NextGrapheme(runes, s)
+			for e < end && runes[e] != '\n' && unicode.IsSpace(runes[e]) {
+				e++
+			}
+		default:
+			// A word and the whitespace that follows it.
+			for e < end && runes[e] != '\n' && !unicode.IsSpace(runes[e]) {
+				e++
+			}
+			for e < end && runes[e] != '\n' && unicode.IsSpace(runes[e]) {
+				e++
+			}
+		}
+		pieces = append(pieces, [2]int{s, e})
+		s = e
+	}
+	return pieces
+}
+
+// layout lays out the text in lines no wider than width, unless wrapping is
+// disabled, returning the runs of text.
+func (l *RichLabel) layout(width int) []richRun {
+	if l.layoutWidth == width {
+		return l.layoutRuns
+	}
+	runs := []richRun{}
+	lineStart, lineTop, x := 0, 0, 0
+	size := math.Size{}
+
+	endLine := func() {
+		ascent, height := 0, 0
+		for _, r := range runs[lineStart:] {
+			ascent = math.Max(ascent, guix.FontAscent(r.font))
+			height = math.Max(height, r.font.GlyphMaxSize().H)
+		}
+		if lineStart == len(runs) {
+			height = l.font.GlyphMaxSize().H
+		}
+		for i := lineStart; i < len(runs); i++ {
+			r := &runs[i]
+			r.baseline = lineTop + ascent
+			r.rect.Min.Y, r.rect.Max.Y = lineTop, lineTop+height
+			r.offsets = r.font.Layout(&guix.TextBlock{
+				Runes:     r.runes,
+				AlignRect: math.Rect{Min: math.Point{X: r.rect.Min.X, Y: r.baseline - guix.FontAscent(r.font)}},
+			})
+		}
+		lineStart, lineTop, x = len(runs), lineTop+height, 0
+		size.H = lineTop
+	}
+
+	runes := l.text.Runes()
+	l.text.Runs(func(start, end int, a guix.TextAttributes) {
+		font := a.Font
+		if font == nil {
+			font = l.font
+		}
+		for _, p := range l.pieces(start, end) {
+			piece := runes[p[0]:p[1]]
+			if piece[0] == '\n' {
+				endLine()
+				continue
+			}
+			trimmed := piece
+			for len(trimmed) > 0 && unicode.IsSpace(trimmed[len(trimmed)-1]) {
+				trimmed = trimmed[:len(trimmed)-1]
+			}
+			w := font.Measure(&guix.TextBlock{Runes: piece}).W
+			tw := font.Measure(&guix.TextBlock{Runes: trimmed}).W
+			if l.wrap != guix.WrapNone && x > 0 && x+tw > width {
+				endLine()
+			}
+			n := len(runs)
+			if n > lineStart && runs[n-1].attrs == a && runs[n-1].font == font {
+				// Extend the previous run on the line.
+				runs[n-1].runes = append(runs[n-1].runes, piece...)
+				runs[n-1].rect.Max.X = x + w
+			} else {
+				runs = append(runs, richRun{
+					font:  font,
+					runes: append([]rune{}, piece...),
+					attrs: a,
+					rect:  math.Rect{Min: math.Point{X: x}, Max: math.Point{X: x + w}},
+				})
+			}
+			size.W = math.Max(size.W, x+tw)
+			x += w
+		}
+	})
+	if lineStart < len(runs) || len(runs) == 0 {
+		endLine()
+	}
+
+	l.layoutWidth, l.layoutRuns, l.layoutSize = width, runs, size
+	return runs
+}
+
+func (l *RichLabel) DesiredSize(min, max math.Size) math.Size {
+	l.layout(max.W)
+	return l.layoutSize.Clamp(min, max)
+}
+
+// parts.DrawPaint overrides
+func (l *RichLabel) Paint(c guix.Canvas) {
+	for _, r := range l.layout(l.outer.Size().W) {
+		a := r.attrs
+		color := l.color
+		if a.Link != "" {
+			color = l.linkColor
+		}
+		if a.Color != nil {
+			color = *a.Color
+		}
+		if a.BackgroundColor != nil {
+			c.DrawRect(r.rect, guix.CreateBrush(*a.BackgroundColor))
+		}
+		c.DrawRunes(r.font, r.runes, r.offsets, color)
+
+		thickness := math.Max(r.font.Size()/12, 1)
+		if a.Underline || a.Link != "" {
+			y := r.baseline + thickness
+			c.DrawRect(math.CreateRect(r.rect.Min.X, y, r.rect.Max.X, y+thickness), guix.CreateBrush(color))
+		}
+		if a.Strikethrough {
+			y := r.baseline - guix.FontAscent(r.font)/3
+			c.DrawRect(math.CreateRect(r.rect.Min.X, y, r.rect.Max.X, y+thickness), guix.CreateBrush(color))
+		}
+	}
+}
+
+// InputEventHandler override
+func (l *RichLabel) Click(ev guix.MouseEvent) (consume bool) {
+	if ev.Button == guix.MouseButtonLeft {
+		if link := l.LinkAt(ev.Point); link != "" {
+			if l.onLinkClicked != nil {
+				l.onLinkClicked.Fire(ev, link)
+			}
+			return true
+		}
+	}
+	return l.Control.Click(ev)
+}
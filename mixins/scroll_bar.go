@@ -103,6 +103,12 @@ func (s *ScrollBar) DesiredSize(min, max math.Size) math.Size {
 	}
 }
 
+func (s *ScrollBar) SetSize(size math.Size) {
+	s.Control.SetSize(size)
+	// The bar is positioned relative to the size of the rail.
+	s.updateBarRect()
+}
+
 func (s *ScrollBar) Paint(c guix.Canvas) {
 	c.DrawRoundedRect(s.outer.Size().Rect(), 3, 3, 3, 3, s.railPen, s.railBrush)
 	c.DrawRoundedRect(s.barRect, 3, 3, 3, 3, s.barPen, s.barBrush)
@@ -202,6 +208,7 @@ func (s *ScrollBar) Orientation() guix.Orientation {
 func (s *ScrollBar) SetOrientation(o guix.Orientation) {
 	if s.orientation != o {
 		s.orientation = o
+		s.updateBarRect()
 		s.Redraw()
 	}
 }
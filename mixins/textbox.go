@@ -5,6 +5,7 @@
 package mixins
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/vcaesar/guix"
@@ -39,6 +40,26 @@ type TextBox struct {
 	selectionDragging bool
 	selectionDrag     guix.TextSelection
 	desiredWidth      int
+	visibleLines      []int // The lines laid out, or nil to lay out every line
+	readOnly          bool
+	passwordChar      rune
+	placeholder       string
+	placeholderColor  guix.Color
+
+	editFilter          guix.TextBoxEditFilter
+	maxLength           int
+	validator           guix.TextBoxValidator
+	validationError     error
+	onValidationChanged guix.Event
+
+	findQuery          guix.FindQuery
+	findBar            guix.FindBar
+	findBarChild       *guix.Child
+	onFindQueryChanged guix.Event
+
+	onCompositionStart  guix.Event
+	onCompositionUpdate guix.Event
+	onCompositionEnd    guix.Event
 }
 
 func (t *TextBox) lineMouseDown(line TextBoxLine, ev guix.MouseEvent) {
@@ -70,15 +91,20 @@ func (t *TextBox) Init(outer TextBoxOuter, driver guix.Driver, theme guix.Theme,
 	t.driver = driver
 	t.font = font
 	t.onRedrawLines = guix.CreateEvent(func() {})
+	t.onFindQueryChanged = guix.CreateEvent(func() {})
 	t.controller = guix.CreateTextBoxController()
 	t.adapter = &TextBoxAdapter{TextBox: t}
 	t.desiredWidth = 100
 	t.SetScrollBarEnabled(false) // Defaults to single line
 	t.OnGainedFocus(func() { t.onRedrawLines.Fire() })
-	t.OnLostFocus(func() { t.onRedrawLines.Fire() })
+	t.OnLostFocus(func() {
+		t.commitComposition()
+		t.onRedrawLines.Fire()
+	})
 	t.controller.OnTextChanged(func([]guix.TextBoxEdit) {
 		t.onRedrawLines.Fire()
 		t.List.DataChanged(false)
+		t.validate()
 	})
 	t.controller.OnSelectionChanged(func() {
 		t.onRedrawLines.Fire()
@@ -88,6 +114,7 @@ func (t *TextBox) Init(outer TextBoxOuter, driver guix.Driver, theme guix.Theme,
 
 	// Interface compliance test
 	_ = guix.TextBox(t)
+	_ = guix.Composer(t)
 }
 
 func (t *TextBox) textRect() math.Rect {
@@ -185,6 +212,9 @@ func (t *TextBox) Carets() []int {
 }
 
 func (t *TextBox) RuneIndexAt(pnt math.Point) (index int, found bool) {
+	if t.findBarChild != nil && t.findBarChild.Bounds().Contains(pnt) {
+		return -1, false // The lines of the find bar's text boxes
+	}
 	for _, child := range guix.ControlsUnder(pnt, t) {
 		line, _ := child.C.(TextBoxLine)
 		if line == nil {
@@ -218,8 +248,338 @@ func (t *TextBox) LineEnd(line int) int {
 	return t.controller.LineEnd(line)
 }
 
+func (t *TextBox) ReadOnly() bool {
+	return t.readOnly
+}
+
+func (t *TextBox) SetReadOnly(readOnly bool) {
+	if t.readOnly != readOnly {
+		t.readOnly = readOnly
+		if readOnly {
+			t.controller.CancelComposition()
+		}
+	}
+}
+
+func (t *TextBox) PasswordChar() rune {
+	return t.passwordChar
+}
+
+func (t *TextBox) SetPasswordChar(passwordChar rune) {
+	if t.passwordChar != passwordChar {
+		t.passwordChar = passwordChar
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *TextBox) Placeholder() string {
+	return t.placeholder
+}
+
+func (t *TextBox) SetPlaceholder(placeholder string) {
+	if t.placeholder != placeholder {
+		t.placeholder = placeholder
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *TextBox) PlaceholderColor() guix.Color {
+	return t.placeholderColor
+}
+
+func (t *TextBox) SetPlaceholderColor(color guix.Color) {
+	if t.placeholderColor != color {
+		t.placeholderColor = color
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *TextBox) EditFilter() guix.TextBoxEditFilter {
+	return t.editFilter
+}
+
+func (t *TextBox) SetEditFilter(f guix.TextBoxEditFilter) {
+	t.editFilter = f
+	t.updateEditFilter()
+}
+
+func (t *TextBox) MaxLength() int {
+	return t.maxLength
+}
+
+func (t *TextBox) SetMaxLength(maxLength int) {
+	t.maxLength = maxLength
+	t.updateEditFilter()
+}
+
+// updateEditFilter sets the controller's edit filter to the edit filter
+// followed by the maximum length.
+func (t *TextBox) updateEditFilter() {
+	f := t.editFilter
+	if t.maxLength > 0 {
+		f = guix.ChainEditFilters(f, guix.MaxLengthFilter(t.maxLength))
+	}
+	t.controller.SetEditFilter(f)
+}
+
+func (t *TextBox) Validator() guix.TextBoxValidator {
+	return t.validator
+}
+
+func (t *TextBox) SetValidator(validator guix.TextBoxValidator) {
+	t.validator = validator
+	t.validate()
+}
+
+func (t *TextBox) ValidationError() error {
+	return t.validationError
+}
+
+func (t *TextBox) OnValidationChanged(f func(error)) guix.EventSubscription {
+	if t.onValidationChanged == nil {
+		t.onValidationChanged = guix.CreateEvent(f)
+	}
+	return t.onValidationChanged.Listen(f)
+}
+
+// validate validates the text, firing the validation changed event if the
+// validation error changed.
+func (t *TextBox) validate() {
+	var err error
+	if t.validator != nil {
+		err = t.validator(t.controller.Text())
+	}
+	old := t.validationError
+	if (err == nil) == (old == nil) && (err == nil || err.Error() == old.Error()) {
+		return
+	}
+	t.validationError = err
+	if t.onValidationChanged != nil {
+		t.onValidationChanged.Fire(err)
+	}
+}
+
+// displayRunes returns runes as they are displayed, masked by the password
+// character if one is set.
+func (t *TextBox) displayRunes(runes []rune) []rune {
+	if t.passwordChar == 0 {
+		return runes
+	}
+	masked := make([]rune, len(runes))
+	for i := range masked {
+		masked[i] = t.passwordChar
+	}
+	return masked
+}
+
+func (t *TextBox) Undo() bool {
+	if t.readOnly || !t.controller.Undo() {
+		return false
+	}
+	t.ScrollToRune(t.controller.FirstCaret())
+	return true
+}
+
+func (t *TextBox) Redo() bool {
+	if t.readOnly || !t.controller.Redo() {
+		return false
+	}
+	t.ScrollToRune(t.controller.FirstCaret())
+	return true
+}
+
+func (t *TextBox) CanUndo() bool {
+	return t.controller.CanUndo()
+}
+
+func (t *TextBox) CanRedo() bool {
+	return t.controller.CanRedo()
+}
+
+func (t *TextBox) FindQuery() guix.FindQuery {
+	return t.findQuery
+}
+
+func (t *TextBox) SetFindQuery(q guix.FindQuery) {
+	if t.findQuery == q {
+		return
+	}
+	t.findQuery = q
+	if t.findBar != nil && t.findBar.Query() != q {
+		t.findBar.SetQuery(q)
+	}
+	t.onFindQueryChanged.Fire()
+}
+
+// findSelected scrolls to the match selected by a find, returning found.
+func (t *TextBox) findSelected(found bool) bool {
+	if found {
+		t.ScrollToRune(t.controller.FirstSelection().First())
+		t.ScrollToRune(t.controller.LastSelection().Last())
+	}
+	return found
+}
+
+func (t *TextBox) FindNext() (bool, error) {
+	// The selected match is skipped, but a selection that is not a match is
+	// not, so that editing the query refines the match at the selection.
+	from := t.controller.FirstSelection().Start()
+	if t.controller.IsMatchSelected(t.findQuery) {
+		from++
+	}
+	t.controller.StoreCaretLocations()
+	found, err := t.controller.SelectNextMatch(t.findQuery, from)
+	return t.findSelected(found), err
+}
+
+func (t *TextBox) FindPrevious() (bool, error) {
+	from := t.controller.FirstSelection().Start()
+	t.controller.StoreCaretLocations()
+	found, err := t.controller.SelectPreviousMatch(t.findQuery, from)
+	return t.findSelected(found), err
+}
+
+func (t *TextBox) SelectAllMatches() (int, error) {
+	t.controller.StoreCaretLocations()
+	n, err := t.controller.SelectAllMatches(t.findQuery)
+	t.findSelected(n > 0)
+	return n, err
+}
+
+func (t *TextBox) ReplaceMatch(replacement string) (bool, error) {
+	if t.readOnly {
+		return false, nil
+	}
+	replaced, err := t.controller.ReplaceMatch(t.findQuery, replacement)
+	if err != nil {
+		return false, err
+	}
+	if !replaced {
+		_, err = t.FindNext()
+		return false, err
+	}
+	t.findSelected(true)
+	return true, nil
+}
+
+func (t *TextBox) ReplaceAllMatches(replacement string) (int, error) {
+	if t.readOnly {
+		return 0, nil
+	}
+	n, err := t.controller.ReplaceAllMatches(t.findQuery, replacement)
+	t.findSelected(n > 0)
+	return n, err
+}
+
+func (t *TextBox) IsFindBarShowing() bool {
+	return t.findBarChild != nil
+}
+
+func (t *TextBox) ShowFindBar() {
+	if t.findBar == nil {
+		t.findBar = t.theme.CreateFindBar()
+		t.findBar.SetTarget(t.outer.(guix.TextBox))
+		t.findBar.SetQuery(t.findQuery)
+	}
+	if t.findBarChild == nil {
+		t.findBarChild = t.AddChild(t.findBar)
+		t.onFindQueryChanged.Fire()
+	}
+	if t.Attached() {
+		t.findBar.FocusQuery()
+	}
+}
+
+func (t *TextBox) HideFindBar() {
+	if t.findBarChild != nil {
+		t.findBarChild = nil
+		t.RemoveChild(t.findBar)
+		t.onFindQueryChanged.Fire()
+	}
+}
+
+func (t *TextBox) OnCompositionStart(f func(guix.CompositionEvent)) guix.EventSubscription {
+	if t.onCompositionStart == nil {
+		t.onCompositionStart = guix.CreateEvent(f)
+	}
+	return t.onCompositionStart.Listen(f)
+}
+
+func (t *TextBox) OnCompositionUpdate(f func(guix.CompositionEvent)) guix.EventSubscription {
+	if t.onCompositionUpdate == nil {
+		t.onCompositionUpdate = guix.CreateEvent(f)
+	}
+	return t.onCompositionUpdate.Listen(f)
+}
+
+func (t *TextBox) OnCompositionEnd(f func(guix.CompositionEvent)) guix.EventSubscription {
+	if t.onCompositionEnd == nil {
+		t.onCompositionEnd = guix.CreateEvent(f)
+	}
+	return t.onCompositionEnd.Listen(f)
+}
+
+func (t *TextBox) IsComposing() bool {
+	return t.controller.IsComposing()
+}
+
+// commitComposition commits the pre-edit text, as when the text box loses
+// the focus during a composition.
+func (t *TextBox) commitComposition() {
+	if t.controller.IsComposing() {
+		s, e := t.controller.Composition()
+		text := string(t.controller.TextRunes()[s:e])
+		t.CompositionEnd(guix.CompositionEvent{Text: text, Cursor: e - s})
+	}
+}
+
+// line returns the TextBoxLine displaying the line with the given index, or
+// nil if the line is not visible.
+func (t *TextBox) line(index int) TextBoxLine {
+	row := t.lineRow(index)
+	if t.rowLine(row) != index {
+		return nil // Hidden
+	}
+	switch c := t.ItemControl(row).(type) {
+	case TextBoxLine:
+		return c
+	case guix.Parent:
+		line, _ := guix.FindControl(c, func(c guix.Control) bool {
+			_, b := c.(TextBoxLine)
+			return b
+		}).(TextBoxLine)
+		return line
+	}
+	return nil
+}
+
+// setVisibleLines sets the ordered lines that are laid out, hiding the
+// others. A nil slice lays out every line.
+func (t *TextBox) setVisibleLines(lines []int) {
+	t.visibleLines = lines
+	t.List.DataChanged(true)
+}
+
+// lineRow returns the index of the row of the list displaying the line, or
+// of the last visible line before it if the line is hidden.
+func (t *TextBox) lineRow(line int) int {
+	if t.visibleLines == nil {
+		return line
+	}
+	row := sort.SearchInts(t.visibleLines, line+1) - 1
+	return math.Max(row, 0)
+}
+
+// rowLine returns the index of the line displayed by the row of the list.
+func (t *TextBox) rowLine(row int) int {
+	if t.visibleLines == nil {
+		return row
+	}
+	return t.visibleLines[row]
+}
+
 func (t *TextBox) ScrollToLine(i int) {
-	t.List.ScrollTo(i)
+	t.List.ScrollTo(t.lineRow(i))
 }
 
 func (t *TextBox) ScrollToRune(i int) {
@@ -343,14 +703,20 @@ func (t *TextBox) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 		t.ScrollToRune(t.controller.LastCaret())
 		return true
 	case guix.KeyBackspace:
-		t.controller.Backspace()
+		if !t.readOnly {
+			t.controller.Backspace()
+		}
 		return true
 	case guix.KeyDelete:
-		t.controller.Delete()
+		if !t.readOnly {
+			t.controller.Delete()
+		}
 		return true
 	case guix.KeyEnter:
 		if t.multiline {
-			t.controller.ReplaceWithNewline()
+			if !t.readOnly {
+				t.controller.ReplaceWithNewline()
+			}
 			return true
 		}
 	case guix.KeyA:
@@ -362,6 +728,9 @@ func (t *TextBox) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 		fallthrough
 	case guix.KeyC:
 		if ev.Modifier.Control() {
+			if t.passwordChar != 0 || (ev.Key == guix.KeyX && t.readOnly) {
+				return true // The text must not leave the text box
+			}
 			parts := make([]string, t.controller.SelectionCount())
 			for i := range parts {
 				parts[i] = t.controller.SelectionText(i)
@@ -380,20 +749,59 @@ func (t *TextBox) KeyPress(ev guix.KeyboardEvent) (consume bool) {
 		}
 	case guix.KeyV:
 		if ev.Modifier.Control() {
+			if t.readOnly {
+				return true
+			}
 			str, _ := t.driver.GetClipboard()
 			t.controller.ReplaceAll(str)
 			t.controller.Deselect(false)
 			return true
 		}
+	case guix.KeyZ:
+		if ev.Modifier.Control() {
+			if ev.Modifier.Shift() {
+				t.Redo()
+			} else {
+				t.Undo()
+			}
+			return true
+		}
+	case guix.KeyF, guix.KeyF3:
+		if t.multiline && t.findKeyPress(ev) {
+			return true
+		}
 	case guix.KeyEscape:
+		if t.IsFindBarShowing() {
+			t.HideFindBar()
+			return true
+		}
 		t.controller.ClearSelections()
 	}
 
 	return t.List.KeyPress(ev)
 }
 
+// findKeyPress handles the shortcuts to find text, returning true if ev was
+// one of them.
+func (t *TextBox) findKeyPress(ev guix.KeyboardEvent) bool {
+	switch {
+	case ev.Key == guix.KeyF && ev.Modifier.Control():
+		t.ShowFindBar()
+	case ev.Key == guix.KeyF3 && ev.Modifier.Shift():
+		t.FindPrevious()
+	case ev.Key == guix.KeyF3:
+		t.FindNext()
+	default:
+		return false
+	}
+	return true
+}
+
 func (t *TextBox) KeyStroke(ev guix.KeyStrokeEvent) (consume bool) {
-	if !ev.Modifier.Control() && !ev.Modifier.Alt() {
+	if t.controller.IsComposing() {
+		return true // The input method commits the composed text
+	}
+	if !ev.Modifier.Control() && !ev.Modifier.Alt() && !t.readOnly {
 		t.controller.ReplaceAllRunes([]rune{ev.Character})
 		t.controller.Deselect(false)
 	}
@@ -409,6 +817,10 @@ func (t *TextBox) Click(ev guix.MouseEvent) (consume bool) {
 func (t *TextBox) DoubleClick(ev guix.MouseEvent) (consume bool) {
 	if p, ok := t.RuneIndexAt(ev.Point); ok {
 		s, e := t.controller.WordAt(p)
+		if t.passwordChar != 0 {
+			// Word boundaries would reveal the masked text
+			s, e = 0, len(t.controller.TextRunes())
+		}
 		if ev.Modifier&guix.ModControl != 0 {
 			t.controller.AddSelection(guix.CreateTextSelection(s, e, false))
 		} else {
@@ -436,7 +848,92 @@ func (t *TextBox) CreateLine(theme guix.Theme, index int) (line TextBoxLine, con
 	return l, l
 }
 
+// guix.Composer compliance
+func (t *TextBox) CompositionStart(ev guix.CompositionEvent) {
+	if t.readOnly {
+		return
+	}
+	t.controller.BeginComposition()
+	t.controller.UpdateComposition([]rune(ev.Text), ev.Cursor)
+	t.ScrollToRune(t.controller.FirstCaret())
+	if t.onCompositionStart != nil {
+		t.onCompositionStart.Fire(ev)
+	}
+}
+
+func (t *TextBox) CompositionUpdate(ev guix.CompositionEvent) {
+	if t.readOnly {
+		return
+	}
+	t.controller.UpdateComposition([]rune(ev.Text), ev.Cursor)
+	t.ScrollToRune(t.controller.FirstCaret())
+	if t.onCompositionUpdate != nil {
+		t.onCompositionUpdate.Fire(ev)
+	}
+}
+
+func (t *TextBox) CompositionEnd(ev guix.CompositionEvent) {
+	if t.readOnly {
+		return
+	}
+	t.controller.EndComposition([]rune(ev.Text))
+	t.ScrollToRune(t.controller.FirstCaret())
+	if t.onCompositionEnd != nil {
+		t.onCompositionEnd.Fire(ev)
+	}
+}
+
+func (t *TextBox) CompositionRect() math.Rect {
+	s, e := t.controller.Composition()
+	if !t.controller.IsComposing() {
+		s = t.controller.LastCaret()
+		e = s
+	}
+	index := t.controller.LineIndex(s)
+	line := t.line(index)
+	if line == nil {
+		return t.textRect()
+	}
+	e = math.Min(e, t.controller.LineEnd(index))
+	o := guix.ChildToParent(math.ZeroPoint, line, t.outer)
+	x := 0
+	if l, ok := line.(interface{ CaretWidth() int }); ok {
+		x = l.CaretWidth() // The lines paint the text after the caret width
+	}
+	top, bottom := line.PositionAt(s), line.PositionAt(e)
+	return math.CreateRect(x+top.X, 0, x+bottom.X, bottom.Y).Offset(o)
+}
+
 // mixins.List overrides
+func (t *TextBox) LayoutChildren() {
+	t.List.LayoutChildren()
+	if t.findBarChild != nil {
+		// The find bar floats over the top-right of the lines
+		bounds := t.textRect()
+		cm := t.findBar.Margin()
+		cs := t.findBar.DesiredSize(math.ZeroSize, bounds.Size().Contract(cm).Max(math.ZeroSize))
+		at := math.Point{X: bounds.Max.X - cs.W - cm.R, Y: bounds.Min.Y + cm.T}
+		t.findBarChild.Layout(cs.Rect().Offset(at).Intersect(bounds))
+	}
+}
+
+func (t *TextBox) Paint(c guix.Canvas) {
+	t.List.Paint(c)
+	if child := t.findBarChild; child != nil {
+		// Painted last, so that the find bar is drawn over the lines
+		c.Push()
+		c.AddClip(child.Bounds())
+		t.PaintChildren.PaintChild(c, child, -1)
+		c.Pop()
+	}
+}
+
+func (t *TextBox) PaintChild(c guix.Canvas, child *guix.Child, idx int) {
+	if child != t.findBarChild {
+		t.List.PaintChild(c, child, idx)
+	}
+}
+
 func (t *TextBox) PaintSelection(c guix.Canvas, r math.Rect) {}
 
 func (t *TextBox) PaintMouseOverBackground(c guix.Canvas, r math.Rect) {}
@@ -448,6 +945,9 @@ type TextBoxAdapter struct {
 }
 
 func (t *TextBoxAdapter) Count() int {
+	if t.TextBox.visibleLines != nil {
+		return len(t.TextBox.visibleLines)
+	}
 	return math.Max(t.TextBox.controller.LineCount(), 1)
 }
 
@@ -465,7 +965,7 @@ func (t *TextBoxAdapter) Size(theme guix.Theme) math.Size {
 }
 
 func (t *TextBoxAdapter) Create(theme guix.Theme, index int) guix.Control {
-	line, container := t.TextBox.outer.CreateLine(theme, index)
+	line, container := t.TextBox.outer.CreateLine(theme, t.TextBox.rowLine(index))
 	line.OnMouseDown(func(ev guix.MouseEvent) {
 		t.TextBox.lineMouseDown(line, ev)
 	})
@@ -29,28 +29,31 @@ type Window struct {
 	parts.Paddable
 	parts.PaintChildren
 
-	driver             guix.Driver
-	outer              WindowOuter
-	viewport           guix.Viewport
-	windowedSize       math.Size
-	mouseController    *guix.MouseController
-	keyboardController *guix.KeyboardController
-	focusController    *guix.FocusController
-	layoutPending      bool
-	drawPending        bool
-	updatePending      bool
-	onClose            guix.Event // Raised by viewport
-	onResize           guix.Event // Raised by viewport
-	onMouseMove        guix.Event // Raised by viewport
-	onMouseEnter       guix.Event // Raised by viewport
-	onMouseExit        guix.Event // Raised by viewport
-	onMouseDown        guix.Event // Raised by viewport
-	onMouseUp          guix.Event // Raised by viewport
-	onMouseScroll      guix.Event // Raised by viewport
-	onKeyDown          guix.Event // Raised by viewport
-	onKeyUp            guix.Event // Raised by viewport
-	onKeyRepeat        guix.Event // Raised by viewport
-	onKeyStroke        guix.Event // Raised by viewport
+	driver              guix.Driver
+	outer               WindowOuter
+	viewport            guix.Viewport
+	windowedSize        math.Size
+	mouseController     *guix.MouseController
+	keyboardController  *guix.KeyboardController
+	focusController     *guix.FocusController
+	layoutPending       bool
+	drawPending         bool
+	updatePending       bool
+	onClose             guix.Event // Raised by viewport
+	onResize            guix.Event // Raised by viewport
+	onMouseMove         guix.Event // Raised by viewport
+	onMouseEnter        guix.Event // Raised by viewport
+	onMouseExit         guix.Event // Raised by viewport
+	onMouseDown         guix.Event // Raised by viewport
+	onMouseUp           guix.Event // Raised by viewport
+	onMouseScroll       guix.Event // Raised by viewport
+	onKeyDown           guix.Event // Raised by viewport
+	onKeyUp             guix.Event // Raised by viewport
+	onKeyRepeat         guix.Event // Raised by viewport
+	onKeyStroke         guix.Event // Raised by viewport
+	onCompositionStart  guix.Event // Raised by viewport
+	onCompositionUpdate guix.Event // Raised by viewport
+	onCompositionEnd    guix.Event // Raised by viewport
 
 	onClick       guix.Event // Raised by MouseController
 	onDoubleClick guix.Event // Raised by MouseController
@@ -106,6 +109,9 @@ func (w *Window) Init(outer WindowOuter, driver guix.Driver, width, height int,
 	w.onKeyUp = guix.CreateEvent(func(guix.KeyboardEvent) {})
 	w.onKeyRepeat = guix.CreateEvent(func(guix.KeyboardEvent) {})
 	w.onKeyStroke = guix.CreateEvent(func(guix.KeyStrokeEvent) {})
+	w.onCompositionStart = guix.CreateEvent(func(guix.CompositionEvent) {})
+	w.onCompositionUpdate = guix.CreateEvent(func(guix.CompositionEvent) {})
+	w.onCompositionEnd = guix.CreateEvent(func(guix.CompositionEvent) {})
 
 	w.onClick = guix.CreateEvent(func(guix.MouseEvent) {})
 	w.onDoubleClick = guix.CreateEvent(func(guix.MouseEvent) {})
@@ -331,6 +337,22 @@ func (w *Window) OnKeyStroke(f func(guix.KeyStrokeEvent)) guix.EventSubscription
 	return w.onKeyStroke.Listen(f)
 }
 
+func (w *Window) OnCompositionStart(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return w.onCompositionStart.Listen(f)
+}
+
+func (w *Window) OnCompositionUpdate(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return w.onCompositionUpdate.Listen(f)
+}
+
+func (w *Window) OnCompositionEnd(f func(guix.CompositionEvent)) guix.EventSubscription {
+	return w.onCompositionEnd.Listen(f)
+}
+
+func (w *Window) SetCompositionRect(r math.Rect) {
+	w.viewport.SetCompositionRect(r)
+}
+
 func (w *Window) Relayout() {
 	w.layoutPending = true
 	w.requestUpdate()
@@ -378,6 +400,9 @@ func (w *Window) setViewport(v guix.Viewport) {
 		v.OnKeyUp(func(ev guix.KeyboardEvent) { w.onKeyUp.Fire(ev) }),
 		v.OnKeyRepeat(func(ev guix.KeyboardEvent) { w.onKeyRepeat.Fire(ev) }),
 		v.OnKeyStroke(func(ev guix.KeyStrokeEvent) { w.onKeyStroke.Fire(ev) }),
+		v.OnCompositionStart(func(ev guix.CompositionEvent) { w.onCompositionStart.Fire(ev) }),
+		v.OnCompositionUpdate(func(ev guix.CompositionEvent) { w.onCompositionUpdate.Fire(ev) }),
+		v.OnCompositionEnd(func(ev guix.CompositionEvent) { w.onCompositionEnd.Fire(ev) }),
 	}
 	w.Relayout()
 }
@@ -8,7 +8,9 @@ import (
 	"time"
 )
 
-var doubleClickTime = time.Millisecond * 300
+// DoubleClickTime is the maximum duration between two mouse button releases
+// for the second to be treated as a double-click.
+var DoubleClickTime = time.Millisecond * 300
 
 type MouseController struct {
 	window          Window
@@ -90,7 +92,7 @@ func (m *MouseController) mouseUp(ev MouseEvent) {
 
 	setFocusCount := m.focusController.SetFocusCount()
 
-	dblClick := time.Since(m.lastUpTime[ev.Button]) < doubleClickTime
+	dblClick := time.Since(m.lastUpTime[ev.Button]) < DoubleClickTime
 	clickConsumed := false
 	for i := len(m.lastDown[ev.Button]) - 1; i >= 0; i-- {
 		cp := m.lastDown[ev.Button][i]
@@ -0,0 +1,294 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// FillRule determines which regions enclosed by a Path2D are filled.
+type FillRule int
+
+const (
+	// NonZero fills the regions that the path winds around a non-zero number
+	// of times. Holes are made with subpaths of the opposite direction.
+	NonZero FillRule = iota
+	// EvenOdd fills the regions enclosed by an odd number of subpaths. Any
+	// subpath inside another makes a hole.
+	EvenOdd
+)
+
+// PathVerb is the kind of a PathSegment.
+type PathVerb int
+
+const (
+	// PathMoveTo begins a new subpath at Points[0].
+	PathMoveTo PathVerb = iota
+	// PathLineTo is a straight line to Points[0].
+	PathLineTo
+	// PathQuadTo is a quadratic Bézier curve with the control point
+	// Points[0], ending at Points[1].
+	PathQuadTo
+	// PathCubicTo is a cubic Bézier curve with the control points Points[0]
+	// and Points[1], ending at Points[2].
+	PathCubicTo
+	// PathClose closes the subpath with a straight line to its start.
+	PathClose
+)
+
+// PathSegment is a single command of a Path2D.
+type PathSegment struct {
+	Verb   PathVerb
+	Points [3]math.Vec2
+}
+
+// Path2D is an outline of straight lines and curves, in DIPs, made of any
+// number of subpaths. The zero Path2D is empty and ready to use.
+type Path2D struct {
+	FillRule FillRule
+	Segments []PathSegment
+
+	start, current math.Vec2
+}
+
+// Clone returns a copy of the path that does not share its segments.
+func (p *Path2D) Clone() *Path2D {
+	c := *p
+	c.Segments = append([]PathSegment(nil), p.Segments...)
+	return &c
+}
+
+func (p *Path2D) add(verb PathVerb, points ...math.Vec2) {
+	s := PathSegment{Verb: verb}
+	copy(s.Points[:], points)
+	p.Segments = append(p.Segments, s)
+	if len(points) > 0 {
+		p.current = points[len(points)-1]
+	}
+}
+
+// MoveTo begins a new subpath at pt.
+func (p *Path2D) MoveTo(pt math.Vec2) {
+	p.add(PathMoveTo, pt)
+	p.start = pt
+}
+
+// LineTo adds a straight line from the current point to pt.
+func (p *Path2D) LineTo(pt math.Vec2) {
+	p.add(PathLineTo, pt)
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to pt, with the
+// control point c.
+func (p *Path2D) QuadTo(c, pt math.Vec2) {
+	p.add(PathQuadTo, c, pt)
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to pt, with the
+// control points c1 and c2.
+func (p *Path2D) CubicTo(c1, c2, pt math.Vec2) {
+	p.add(PathCubicTo, c1, c2, pt)
+}
+
+// ArcTo adds an elliptical arc from the current point to pt, as in SVG path
+// data. radius holds the radii of the ellipse, which is rotated by rotation
+// radians. Of the four possible arcs, largeArc selects one that sweeps more
+// than 180 degrees and sweep selects one that is drawn clockwise. Radii too
+// small to reach pt are scaled up. Arcs are stored as cubic Bézier curves.
+func (p *Path2D) ArcTo(radius math.Vec2, rotation float32, largeArc, sweep bool, pt math.Vec2) {
+	from := p.current
+	rx, ry := math.Absf(radius.X), math.Absf(radius.Y)
+	if from == pt {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		p.LineTo(pt)
+		return
+	}
+	sinφ, cosφ := math.Sinf(rotation), math.Cosf(rotation)
+
+	// Find the center of the ellipse, in the ellipse's rotated space, as
+	// described in the SVG specification's implementation notes.
+	d := from.Sub(pt).MulS(0.5)
+	x1, y1 := cosφ*d.X+sinφ*d.Y, -sinφ*d.X+cosφ*d.Y
+	if λ := x1*x1/(rx*rx) + y1*y1/(ry*ry); λ > 1 {
+		rx, ry = rx*math.Sqrtf(λ), ry*math.Sqrtf(λ)
+	}
+	n := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	c := math.Sqrtf(math.Maxf(n/(rx*rx*y1*y1+ry*ry*x1*x1), 0))
+	if largeArc == sweep {
+		c = -c
+	}
+	cx, cy := c*rx*y1/ry, -c*ry*x1/rx
+	mid := from.Add(pt).MulS(0.5)
+	center := math.Vec2{X: cosφ*cx - sinφ*cy + mid.X, Y: sinφ*cx + cosφ*cy + mid.Y}
+
+	angle := func(x, y float32) float32 { return math.Atan2f(y, x) }
+	θ := angle((x1-cx)/rx, (y1-cy)/ry)
+	Δθ := angle((-x1-cx)/rx, (-y1-cy)/ry) - θ
+	if sweep && Δθ < 0 {
+		Δθ += math.TwoPi
+	} else if !sweep && Δθ > 0 {
+		Δθ -= math.TwoPi
+	}
+
+	// Approximate the arc with cubic curves of at most 90 degrees each.
+	steps := int(math.Ceilf(math.Absf(Δθ)/(math.Pi/2) - 0.001))
+	if steps < 1 {
+		steps = 1
+	}
+	step := Δθ / float32(steps)
+	k := 4.0 / 3 * math.Tanf(step/4)
+	point := func(θ float32) (position, tangent math.Vec2) {
+		s, c := math.Sinf(θ), math.Cosf(θ)
+		x, y := rx*c, ry*s
+		dx, dy := -rx*s, ry*c
+		position = math.Vec2{X: cosφ*x - sinφ*y + center.X, Y: sinφ*x + cosφ*y + center.Y}
+		tangent = math.Vec2{X: cosφ*dx - sinφ*dy, Y: sinφ*dx + cosφ*dy}
+		return position, tangent
+	}
+	p0, t0 := point(θ)
+	for i := 1; i <= steps; i++ {
+		p1, t1 := point(θ + step*float32(i))
+		if i == steps {
+			p1 = pt
+		}
+		p.CubicTo(p0.Add(t0.MulS(k)), p1.Sub(t1.MulS(k)), p1)
+		p0, t0 = p1, t1
+	}
+}
+
+// Close closes the current subpath with a straight line to its start.
+func (p *Path2D) Close() {
+	p.add(PathClose)
+	p.current = p.start
+}
+
+// AddRect adds the rectangle r as a closed, clockwise subpath.
+func (p *Path2D) AddRect(r math.Rect) {
+	p.MoveTo(r.TL().Vec2())
+	p.LineTo(r.TR().Vec2())
+	p.LineTo(r.BR().Vec2())
+	p.LineTo(r.BL().Vec2())
+	p.Close()
+}
+
+// AddEllipse adds the ellipse with the given center and radii as a closed,
+// clockwise subpath.
+func (p *Path2D) AddEllipse(center, radius math.Vec2) {
+	right := center.Add(math.Vec2{X: radius.X})
+	left := center.Sub(math.Vec2{X: radius.X})
+	p.MoveTo(right)
+	p.ArcTo(radius, 0, false, true, left)
+	p.ArcTo(radius, 0, false, true, right)
+	p.Close()
+}
+
+// Bounds returns the smallest rectangle containing all the points of the
+// path, including control points.
+func (p *Path2D) Bounds() math.Rect {
+	var min, max math.Vec2
+	first := true
+	for _, s := range p.Segments {
+		n := 0
+		switch s.Verb {
+		case PathMoveTo, PathLineTo:
+			n = 1
+		case PathQuadTo:
+			n = 2
+		case PathCubicTo:
+			n = 3
+		}
+		for _, v := range s.Points[:n] {
+			if first {
+				min, max, first = v, v, false
+				continue
+			}
+			min = math.Vec2{X: math.Minf(min.X, v.X), Y: math.Minf(min.Y, v.Y)}
+			max = math.Vec2{X: math.Maxf(max.X, v.X), Y: math.Maxf(max.Y, v.Y)}
+		}
+	}
+	return math.Rect{
+		Min: math.Point{X: int(math.Floorf(min.X)), Y: int(math.Floorf(min.Y))},
+		Max: math.Point{X: int(math.Ceilf(max.X)), Y: int(math.Ceilf(max.Y))},
+	}
+}
+
+// Contour is a subpath of a Path2D flattened to a polyline.
+type Contour struct {
+	Points []math.Vec2
+	Closed bool
+}
+
+// Flatten returns the subpaths of the path with their curves approximated by
+// straight lines, deviating from the curves by no more than tolerance DIPs.
+// Subpaths with fewer than two points are omitted.
+func (p *Path2D) Flatten(tolerance float32) []Contour {
+	contours := []Contour{}
+	var c Contour
+	var start, current math.Vec2
+	end := func(closed bool) {
+		if n := len(c.Points); closed && n > 1 && c.Points[0] == c.Points[n-1] {
+			c.Points = c.Points[:n-1] // The closing line is implicit
+		}
+		if len(c.Points) > 1 {
+			c.Closed = closed
+			contours = append(contours, c)
+		}
+		c = Contour{}
+	}
+	for _, s := range p.Segments {
+		if s.Verb != PathMoveTo && len(c.Points) == 0 {
+			c.Points = []math.Vec2{current}
+			start = current
+		}
+		switch s.Verb {
+		case PathMoveTo:
+			end(false)
+			start, current = s.Points[0], s.Points[0]
+			c.Points = []math.Vec2{current}
+			continue
+		case PathLineTo:
+			current = s.Points[0]
+			c.Points = append(c.Points, current)
+		case PathQuadTo:
+			a, b, e := current, s.Points[0], s.Points[1]
+			// The error of n uniform steps is at most |a - 2b + e| / 4n².
+			n := flattenSteps(a.Sub(b.MulS(2)).Add(e).Len()/4, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				c.Points = append(c.Points, a.MulS(u*u).Add(b.MulS(2*u*t)).Add(e.MulS(t*t)))
+			}
+			current = e
+		case PathCubicTo:
+			a, b, d, e := current, s.Points[0], s.Points[1], s.Points[2]
+			// The error of n uniform steps is at most 3/4 • max(|a - 2b + d|,
+			// |b - 2d + e|) / n².
+			dd := math.Maxf(a.Sub(b.MulS(2)).Add(d).Len(), b.Sub(d.MulS(2)).Add(e).Len())
+			n := flattenSteps(dd*3/4, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				c.Points = append(c.Points, a.MulS(u*u*u).Add(b.MulS(3*u*u*t)).Add(d.MulS(3*u*t*t)).Add(e.MulS(t*t*t)))
+			}
+			current = e
+		case PathClose:
+			end(true)
+			current = start
+		}
+	}
+	end(false)
+	return contours
+}
+
+// flattenSteps returns the number of uniform steps needed to flatten a curve with
+// the error bound e • 1/n² to within the tolerance.
+func flattenSteps(e, tolerance float32) int {
+	if tolerance <= 0 {
+		tolerance = 0.1
+	}
+	return math.Clamp(int(math.Ceilf(math.Sqrtf(e/tolerance))), 1, 256)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestPathFlatten(t *testing.T) {
+	p := &Path2D{}
+	p.MoveTo(math.Vec2{X: 0, Y: 0})
+	p.LineTo(math.Vec2{X: 10, Y: 0})
+	p.QuadTo(math.Vec2{X: 10, Y: 10}, math.Vec2{X: 0, Y: 10})
+	p.Close()
+	p.MoveTo(math.Vec2{X: 20, Y: 0})
+	p.LineTo(math.Vec2{X: 30, Y: 0})
+
+	contours := p.Flatten(0.1)
+	test.AssertEquals(t, 2, len(contours))
+	test.AssertEquals(t, true, contours[0].Closed)
+	test.AssertEquals(t, math.Vec2{X: 0, Y: 10}, contours[0].Points[len(contours[0].Points)-1])
+	for _, pt := range contours[0].Points[2:] {
+		// The curve stays within its control polygon.
+		if pt.X < 0 || pt.X > 10 || pt.Y < 0 || pt.Y > 10 {
+			t.Errorf("Point %v is outside the curve's bounds", pt)
+		}
+	}
+	test.AssertEquals(t, Contour{Points: []math.Vec2{{X: 20, Y: 0}, {X: 30, Y: 0}}}, contours[1])
+	test.AssertEquals(t, math.CreateRect(0, 0, 30, 10), p.Bounds())
+}
+
+func TestPathArcTo(t *testing.T) {
+	p := &Path2D{}
+	p.MoveTo(math.Vec2{X: 0, Y: 0})
+	p.ArcTo(math.Vec2{X: 5, Y: 5}, 0, false, true, math.Vec2{X: 10, Y: 0})
+
+	// A clockwise semicircle from (0, 0) to (10, 0) bulges upwards.
+	test.AssertEquals(t, 3, len(p.Segments))
+	test.AssertEquals(t, math.Vec2{X: 10, Y: 0}, p.Segments[2].Points[2])
+	for _, c := range p.Flatten(0.01) {
+		for _, pt := range c.Points {
+			d := pt.Sub(math.Vec2{X: 5, Y: 0}).Len()
+			if math.Absf(d-5) > 0.01 || pt.Y > 0.01 {
+				t.Errorf("Point %v is not on the arc", pt)
+			}
+		}
+	}
+
+	// Radii too small to reach the end point are scaled up.
+	p = &Path2D{}
+	p.MoveTo(math.Vec2{X: 0, Y: 0})
+	p.ArcTo(math.Vec2{X: 1, Y: 1}, 0, false, false, math.Vec2{X: 10, Y: 0})
+	for _, c := range p.Flatten(0.01) {
+		for _, pt := range c.Points {
+			if pt.Y < -0.01 {
+				t.Errorf("Point %v is not on the arc", pt)
+			}
+		}
+	}
+}
+
+func TestPenDashesClosedLoop(t *testing.T) {
+	square := []math.Vec2{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}}
+	test.AssertEquals(t, [][]math.Vec2{
+		{{X: 2, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}, {X: 2, Y: 0}},
+	}, CreatePen(1, Black).Dashes(square, true))
+}
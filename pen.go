@@ -4,17 +4,182 @@
 
 package guix
 
+import (
+	"github.com/vcaesar/guix/math"
+)
+
 var (
 	DefaultPen     Pen = CreatePen(1.0, Black)
 	TransparentPen Pen = CreatePen(0.0, Transparent)
 	WhitePen       Pen = CreatePen(1.0, White)
 )
 
+// DefaultMiterLimit is the miter limit used by pens with a MiterLimit of 0.
+const DefaultMiterLimit = 4
+
+// LineCap is the shape of the ends of lines and dashes.
+type LineCap int
+
+const (
+	// ButtCap ends the line squarely at its end point.
+	ButtCap LineCap = iota
+	// RoundCap ends the line with a semicircle around its end point.
+	RoundCap
+	// SquareCap ends the line squarely, half the pen width beyond its end
+	// point.
+	SquareCap
+)
+
+// LineJoin is the shape of the outside corners of lines.
+type LineJoin int
+
+const (
+	// MiterJoin extends the outer edges of the lines until they meet. Joins
+	// longer than the pen's miter limit are beveled.
+	MiterJoin LineJoin = iota
+	// RoundJoin joins the lines with a circular arc around the corner.
+	RoundJoin
+	// BevelJoin joins the outer edges of the lines with a straight line.
+	BevelJoin
+)
+
+// Dash is the dash pattern of a pen.
+type Dash struct {
+	// Pattern holds the alternating lengths of dashes and gaps in DIPs,
+	// starting with a dash. A pattern of odd length is repeated to make it
+	// even.
+	Pattern []float32
+
+	// Offset is the distance into the pattern at which lines start.
+	Offset float32
+}
+
 type Pen struct {
 	Width float32
 	Color Color
+	Cap   LineCap
+	Join  LineJoin
+
+	// MiterLimit is the maximum ratio of the length of a miter join to the
+	// pen width. If 0, DefaultMiterLimit is used.
+	MiterLimit float32
+
+	// Dash, if not nil, is the dash pattern of the pen. Dashes are shared
+	// between copies of the pen and must not be modified once the pen has
+	// been used.
+	Dash *Dash
 }
 
 func CreatePen(width float32, color Color) Pen {
-	return Pen{width, color}
+	return Pen{Width: width, Color: color}
+}
+
+// CreateDashedPen returns a pen with the dash pattern of alternating dash and
+// gap lengths in DIPs.
+func CreateDashedPen(width float32, color Color, pattern ...float32) Pen {
+	return Pen{Width: width, Color: color, Dash: &Dash{Pattern: pattern}}
+}
+
+// IsVisible returns false if the pen draws nothing.
+func (p Pen) IsVisible() bool {
+	return p.Width > 0 && p.Color.A > 0
+}
+
+// EffectiveMiterLimit returns the miter limit of the pen, or
+// DefaultMiterLimit if MiterLimit is 0.
+func (p Pen) EffectiveMiterLimit() float32 {
+	if p.MiterLimit <= 0 {
+		return DefaultMiterLimit
+	}
+	return p.MiterLimit
+}
+
+// Dashes splits the polyline points into the polylines of the dashes of the
+// pen. If closed is true, the polyline is treated as a closed loop and a dash
+// that spans the first point is returned as a single polyline. If the pen has
+// no dash pattern, Dashes returns the polyline unchanged, or for a closed
+// polyline of more than two points, a single loop that starts and ends midway
+// along the first line, so that every point is a join rather than a cap.
+func (p Pen) Dashes(points []math.Vec2, closed bool) [][]math.Vec2 {
+	pattern, length := p.dashPattern()
+	if length == 0 && closed && len(points) > 2 {
+		mid := points[0].Add(points[1]).MulS(0.5)
+		loop := append([]math.Vec2{mid}, points[1:]...)
+		return [][]math.Vec2{append(loop, points[0], mid)}
+	}
+	if closed && len(points) > 0 {
+		points = append(append([]math.Vec2{}, points...), points[0])
+	}
+	if length == 0 {
+		return [][]math.Vec2{points}
+	}
+
+	// Find the position in the pattern of the start of the line.
+	offset := p.Dash.Offset - math.Floorf(p.Dash.Offset/length)*length
+	i := 0
+	for offset >= pattern[i] {
+		offset -= pattern[i]
+		i = (i + 1) % len(pattern)
+	}
+	remaining := pattern[i] - offset
+	startsOn := i%2 == 0
+
+	dashes := [][]math.Vec2{}
+	var dash []math.Vec2
+	if startsOn && len(points) > 0 {
+		dash = []math.Vec2{points[0]}
+	}
+	for j := 1; j < len(points); j++ {
+		a, b := points[j-1], points[j]
+		segment := b.Sub(a).Len()
+		pos := float32(0)
+		for segment-pos > remaining {
+			pos += remaining
+			pt := a.Add(b.Sub(a).MulS(pos / segment))
+			if i%2 == 0 {
+				if dash = append(dash, pt); dash[0] != pt || len(dash) > 2 {
+					dashes = append(dashes, dash) // Zero length dashes are dropped
+				}
+				dash = nil
+			} else {
+				dash = []math.Vec2{pt}
+			}
+			i = (i + 1) % len(pattern)
+			remaining = pattern[i]
+		}
+		remaining -= segment - pos
+		if i%2 == 0 {
+			dash = append(dash, b)
+		}
+	}
+	if len(dash) > 1 {
+		if closed && startsOn && len(dashes) > 0 {
+			// The last dash continues into the first.
+			dashes[0] = append(dash, dashes[0][1:]...)
+		} else {
+			dashes = append(dashes, dash)
+		}
+	}
+	return dashes
+}
+
+// dashPattern returns the pen's dash pattern, made even in length, and the
+// total length of the pattern. If the pen is not dashed or the pattern is
+// invalid then the returned length is 0.
+func (p Pen) dashPattern() ([]float32, float32) {
+	if p.Dash == nil || len(p.Dash.Pattern) == 0 {
+		return nil, 0
+	}
+	pattern := p.Dash.Pattern
+	if len(pattern)%2 == 1 {
+		pattern = append(append([]float32{}, pattern...), pattern...)
+	}
+	length := float32(0)
+	for _, l := range pattern {
+		if l < 0 {
+			return nil, 0
+		}
+		length += l
+	}
+	return pattern, length
 }
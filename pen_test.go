@@ -0,0 +1,54 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestPenDashes(t *testing.T) {
+	line := []math.Vec2{{X: 0, Y: 0}, {X: 10, Y: 0}}
+	pen := CreateDashedPen(1, Black, 3, 1)
+	test.AssertEquals(t, [][]math.Vec2{
+		{{X: 0, Y: 0}, {X: 3, Y: 0}},
+		{{X: 4, Y: 0}, {X: 7, Y: 0}},
+		{{X: 8, Y: 0}, {X: 10, Y: 0}},
+	}, pen.Dashes(line, false))
+
+	pen.Dash.Offset = 2
+	test.AssertEquals(t, [][]math.Vec2{
+		{{X: 0, Y: 0}, {X: 1, Y: 0}},
+		{{X: 2, Y: 0}, {X: 5, Y: 0}},
+		{{X: 6, Y: 0}, {X: 9, Y: 0}},
+	}, pen.Dashes(line, false))
+
+	test.AssertEquals(t, [][]math.Vec2{line}, CreatePen(1, Black).Dashes(line, false))
+}
+
+func TestPenDashesAroundCorner(t *testing.T) {
+	square := []math.Vec2{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}}
+	pen := CreateDashedPen(1, Black, 6, 2)
+	test.AssertEquals(t, [][]math.Vec2{
+		{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 2}},
+		{{X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 2}},
+	}, pen.Dashes(square, true))
+
+	// The dash that spans the first point is joined into one.
+	pen.Dash.Offset = 4
+	test.AssertEquals(t, [][]math.Vec2{
+		{{X: 0, Y: 4}, {X: 0, Y: 0}, {X: 2, Y: 0}},
+		{{X: 4, Y: 0}, {X: 4, Y: 4}, {X: 2, Y: 4}},
+	}, pen.Dashes(square, true))
+}
+
+func TestPenMiterLimit(t *testing.T) {
+	pen := CreatePen(1, Black)
+	test.AssertEquals(t, float32(DefaultMiterLimit), pen.EffectiveMiterLimit())
+	pen.MiterLimit = 10
+	test.AssertEquals(t, float32(10), pen.EffectiveMiterLimit())
+}
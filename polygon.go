@@ -14,3 +14,45 @@ type PolygonVertex struct {
 }
 
 type Polygon []PolygonVertex
+
+// Bounds returns the smallest rectangle containing all the vertices of p.
+func (p Polygon) Bounds() math.Rect {
+	if len(p) == 0 {
+		return math.Rect{}
+	}
+	r := math.Rect{Min: p[0].Position, Max: p[0].Position}
+	for _, v := range p[1:] {
+		r.Min = r.Min.Min(v.Position)
+		r.Max = r.Max.Max(v.Position)
+	}
+	return r
+}
+
+// Corner returns the points where the rounded corner at the vertex i meets
+// the edges to the vertices before and after it, treating p as closed. If the
+// vertex is not rounded then both points are its position. The corner is a
+// quadratic curve from ab to ac, with the vertex as its control point.
+func (p Polygon) Corner(i int) (ab, ac math.Vec2) {
+	cnt := len(p)
+	a := p[i].Position.Vec2()
+	r := p[i].RoundedRadius
+	if r <= 0 {
+		return a, a
+	}
+	ba := p[(i+cnt-1)%cnt].Position.Vec2().Sub(a)
+	ca := p[(i+1)%cnt].Position.Vec2().Sub(a)
+	baLen, caLen := ba.Len(), ca.Len()
+	if baLen < 0.001 || caLen < 0.001 {
+		return a, a // Zero length edge, nothing to round
+	}
+	baDir, caDir := ba.DivS(baLen), ca.DivS(caLen)
+	dp := baDir.Dot(caDir)
+	if dp < -0.99999 || dp > 0.99999 {
+		return a, a // Straight or degenerate, nothing to round
+	}
+	α := math.Acosf(dp) / 2
+	// Distance along each edge from a to the points where the circle of radius
+	// r touches the edges. This cannot be further than half way along an edge.
+	d := math.Minf(r/math.Tanf(α), baLen/2, caLen/2)
+	return a.Add(baDir.MulS(d)), a.Add(caDir.MulS(d))
+}
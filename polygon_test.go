@@ -0,0 +1,36 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestPolygonCorner(t *testing.T) {
+	p := Polygon{
+		{Position: math.Point{X: 0, Y: 0}, RoundedRadius: 2},
+		{Position: math.Point{X: 10, Y: 0}},
+		{Position: math.Point{X: 10, Y: 10}, RoundedRadius: 20},
+		{Position: math.Point{X: 0, Y: 10}},
+	}
+	round := func(v math.Vec2) math.Point {
+		return math.Point{X: math.Round(v.X), Y: math.Round(v.Y)}
+	}
+	ab, ac := p.Corner(0)
+	test.AssertEquals(t, math.Point{X: 0, Y: 2}, round(ab))
+	test.AssertEquals(t, math.Point{X: 2, Y: 0}, round(ac))
+
+	// The corner cannot extend past half way along an edge.
+	ab, ac = p.Corner(2)
+	test.AssertEquals(t, math.Point{X: 10, Y: 5}, round(ab))
+	test.AssertEquals(t, math.Point{X: 5, Y: 10}, round(ac))
+
+	ab, ac = p.Corner(1)
+	test.AssertEquals(t, math.Vec2{X: 10, Y: 0}, ab)
+	test.AssertEquals(t, ab, ac)
+}
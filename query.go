@@ -0,0 +1,447 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Selector locates controls in a control tree. Selectors are written in a
+// subset of the CSS selector syntax:
+//
+//	Button                 controls with the type name Button
+//	*                      any control
+//	#save                  controls with the ID "save"
+//	[id=save]              the same as #save
+//	[text=OK]              controls with a Text() string of "OK"
+//	[text^=O]              ... with text starting with "O"
+//	[text$=K]              ... with text ending with "K"
+//	[text*="O K"]          ... with text containing "O K"
+//	[text]                 controls that have a Text() method
+//	:first-child           controls that are the first child of their parent
+//	:last-child            controls that are the last child of their parent
+//	:nth-child(2)          controls that are the 2nd child of their parent
+//	:visible               controls that are visible
+//	A B                    controls matching B with an ancestor matching A
+//	A > B                  controls matching B with a parent matching A
+//
+// A type name matches the name of the control's concrete type, ignoring any
+// pointer indirection and package name. For example, the controls created by
+// the basic theme's CreateButton are matched by Button.
+type Selector struct {
+	source string
+	steps  []selectorStep
+}
+
+type selectorCombinator int
+
+const (
+	descendantCombinator selectorCombinator = iota
+	childCombinator
+)
+
+type selectorStep struct {
+	combinator selectorCombinator // Relation to the previous step
+	typeName   string             // Empty matches any type
+	filters    []func(Control) bool
+}
+
+func (s selectorStep) match(c Control) bool {
+	if s.typeName != "" && typeName(c) != s.typeName {
+		return false
+	}
+	for _, f := range s.filters {
+		if !f(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses the selector string s, returning an error if s is not
+// a valid selector.
+func ParseSelector(s string) (*Selector, error) {
+	p := &selectorParser{s: s}
+	steps, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid selector %q: %v", s, err)
+	}
+	return &Selector{source: s, steps: steps}, nil
+}
+
+// MustParseSelector is like ParseSelector but panics if s is not a valid
+// selector.
+func MustParseSelector(s string) *Selector {
+	sel, err := ParseSelector(s)
+	if err != nil {
+		panic(err)
+	}
+	return sel
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// Match returns true if the control c is matched by the selector.
+func (s *Selector) Match(c Control) bool {
+	return s.matchStep(c, len(s.steps)-1)
+}
+
+func (s *Selector) matchStep(c Control, i int) bool {
+	step := s.steps[i]
+	if !step.match(c) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if step.combinator == childCombinator {
+		p, ok := c.Parent().(Control)
+		return ok && s.matchStep(p, i-1)
+	}
+	for p, ok := c.Parent().(Control); ok; p, ok = p.Parent().(Control) {
+		if s.matchStep(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns all the descendants of root that are matched by the selector,
+// in depth-first order.
+func (s *Selector) Query(root Parent) ControlList {
+	var l ControlList
+	var visit func(p Parent)
+	visit = func(p Parent) {
+		for _, child := range p.Children() {
+			if s.Match(child.Control) {
+				l = append(l, child.Control)
+			}
+			if parent, ok := child.Control.(Parent); ok {
+				visit(parent)
+			}
+		}
+	}
+	visit(root)
+	return l
+}
+
+// Query returns all the descendants of root that are matched by the selector
+// string, in depth-first order. Query panics if selector is not valid.
+func Query(root Parent, selector string) ControlList {
+	return MustParseSelector(selector).Query(root)
+}
+
+// QueryFirst returns the first descendant of root that is matched by the
+// selector string, or nil if there is no match. QueryFirst panics if
+// selector is not valid.
+func QueryFirst(root Parent, selector string) Control {
+	if l := Query(root, selector); len(l) > 0 {
+		return l[0]
+	}
+	return nil
+}
+
+// QueryInto assigns the first descendant of root that is matched by the
+// selector string and is assignable to the value pointed to by ptr. QueryInto
+// returns false, leaving the value unchanged, if there is no such control.
+// For example:
+//
+//	var ok Button
+//	QueryInto(window, "Button[text=OK]", &ok)
+//
+// QueryInto panics if selector is not valid or if ptr is not a non-nil
+// pointer.
+func QueryInto(root Parent, selector string, ptr interface{}) bool {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		panic(fmt.Errorf("QueryInto requires a non-nil pointer, got %T", ptr))
+	}
+	dst := v.Elem()
+	for _, c := range Query(root, selector) {
+		if cv := reflect.ValueOf(c); cv.Type().AssignableTo(dst.Type()) {
+			dst.Set(cv)
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(c Control) string {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func childIndex(c Control) (index, count int) {
+	p := c.Parent()
+	if p == nil {
+		return -1, 0
+	}
+	children := p.Children()
+	return children.IndexOf(c), len(children)
+}
+
+type selectorParser struct {
+	s string
+	i int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.i
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n') {
+		p.i++
+	}
+	return p.i > start
+}
+
+func (p *selectorParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected '%c'", c)
+	}
+	p.i++
+	return nil
+}
+
+func (p *selectorParser) errorf(msg string, args ...interface{}) error {
+	if p.eof() {
+		return fmt.Errorf(msg+" at end of selector", args...)
+	}
+	return fmt.Errorf(msg+" at offset %d", append(args, p.i)...)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func (p *selectorParser) ident() (string, error) {
+	start := p.i
+	for !p.eof() && isIdentChar(p.peek()) {
+		p.i++
+	}
+	if p.i == start {
+		return "", p.errorf("expected identifier")
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *selectorParser) parse() ([]selectorStep, error) {
+	var steps []selectorStep
+	combinator := descendantCombinator
+	p.skipSpace()
+	for {
+		step, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+		step.combinator = combinator
+		steps = append(steps, step)
+
+		hadSpace := p.skipSpace()
+		switch {
+		case p.eof():
+			return steps, nil
+		case p.peek() == '>':
+			p.i++
+			p.skipSpace()
+			combinator = childCombinator
+		case hadSpace:
+			combinator = descendantCombinator
+		default:
+			return nil, p.errorf("unexpected '%c'", p.peek())
+		}
+	}
+}
+
+func (p *selectorParser) step() (selectorStep, error) {
+	var s selectorStep
+	start := p.i
+	if p.peek() == '*' {
+		p.i++
+	} else if isIdentChar(p.peek()) {
+		s.typeName, _ = p.ident()
+	}
+	for {
+		var f func(Control) bool
+		var err error
+		switch p.peek() {
+		case '#':
+			p.i++
+			var id string
+			if id, err = p.ident(); err == nil {
+				f = func(c Control) bool { return c.ID() == id }
+			}
+		case '[':
+			p.i++
+			f, err = p.attribute()
+		case ':':
+			p.i++
+			f, err = p.pseudoClass()
+		default:
+			if p.i == start {
+				return s, p.errorf("expected selector")
+			}
+			return s, nil
+		}
+		if err != nil {
+			return s, err
+		}
+		s.filters = append(s.filters, f)
+	}
+}
+
+func (p *selectorParser) attribute() (func(Control) bool, error) {
+	p.skipSpace()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	var get func(c Control) (string, bool)
+	switch name {
+	case "id":
+		get = func(c Control) (string, bool) { return c.ID(), true }
+	case "text":
+		get = func(c Control) (string, bool) {
+			if t, ok := c.(interface {
+				Text() string
+			}); ok {
+				return t.Text(), true
+			}
+			return "", false
+		}
+	default:
+		return nil, fmt.Errorf("unknown attribute '%s'", name)
+	}
+
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.i++
+		return func(c Control) bool { _, ok := get(c); return ok }, nil
+	}
+
+	var op byte
+	switch p.peek() {
+	case '^', '$', '*':
+		op = p.peek()
+		p.i++
+	}
+	if err := p.expect('='); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+
+	var test func(s string) bool
+	switch op {
+	case '^':
+		test = func(s string) bool { return strings.HasPrefix(s, value) }
+	case '$':
+		test = func(s string) bool { return strings.HasSuffix(s, value) }
+	case '*':
+		test = func(s string) bool { return strings.Contains(s, value) }
+	default:
+		test = func(s string) bool { return s == value }
+	}
+	return func(c Control) bool {
+		s, ok := get(c)
+		return ok && test(s)
+	}, nil
+}
+
+// value parses a quoted string, or an unquoted value that extends to the
+// closing ']'.
+func (p *selectorParser) value() (string, error) {
+	if q := p.peek(); q == '"' || q == '\'' {
+		start := p.i
+		p.i++
+		for !p.eof() && p.peek() != q {
+			if p.peek() == '\\' {
+				p.i++
+			}
+			p.i++
+		}
+		if err := p.expect(q); err != nil {
+			return "", err
+		}
+		if q == '\'' {
+			return strings.Replace(p.s[start+1:p.i-1], `\'`, `'`, -1), nil
+		}
+		return strconv.Unquote(p.s[start:p.i])
+	}
+	start := p.i
+	for !p.eof() && p.peek() != ']' {
+		p.i++
+	}
+	return strings.TrimSpace(p.s[start:p.i]), nil
+}
+
+func (p *selectorParser) pseudoClass() (func(Control) bool, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "first-child":
+		return func(c Control) bool {
+			i, _ := childIndex(c)
+			return i == 0
+		}, nil
+	case "last-child":
+		return func(c Control) bool {
+			i, n := childIndex(c)
+			return i >= 0 && i == n-1
+		}, nil
+	case "nth-child":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		start := p.i
+		for !p.eof() && '0' <= p.peek() && p.peek() <= '9' {
+			p.i++
+		}
+		n, err := strconv.Atoi(p.s[start:p.i])
+		if err != nil || n < 1 {
+			return nil, p.errorf("expected positive child number")
+		}
+		p.skipSpace()
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return func(c Control) bool {
+			i, _ := childIndex(c)
+			return i == n-1
+		}, nil
+	case "visible":
+		return func(c Control) bool { return c.IsVisible() }, nil
+	default:
+		return nil, fmt.Errorf("unknown pseudo-class ':%s'", name)
+	}
+}
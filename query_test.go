@@ -0,0 +1,85 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix_test
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix"
+	test "github.com/vcaesar/guix/testing"
+	"github.com/vcaesar/guix/testing/guixtest"
+)
+
+func TestParseSelector(t *testing.T) {
+	for _, s := range []string{
+		"Button",
+		"*",
+		"#save",
+		"LinearLayout > Button[text=OK]",
+		"LinearLayout Label:nth-child(2)",
+		`Button[text*="a ]b"]:visible`,
+		"* > *:first-child:last-child",
+	} {
+		_, err := guix.ParseSelector(s)
+		test.AssertEquals(t, nil, err)
+	}
+	for _, s := range []string{
+		"",
+		"Button >",
+		"Button[colour=red]",
+		"Button[text=OK",
+		"Label:nth-child(0)",
+		"Label:hovered",
+		"Label,Button",
+	} {
+		_, err := guix.ParseSelector(s)
+		test.AssertEquals(t, true, err != nil)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		r.Do(func() {
+			outer := r.Theme.CreateLinearLayout()
+			inner := r.Theme.CreateLinearLayout()
+			inner.SetID("toolbar")
+			for _, s := range []string{"OK", "Cancel"} {
+				b := r.Theme.CreateButton()
+				b.SetText(s)
+				inner.AddChild(b)
+			}
+			l := r.Theme.CreateLabel()
+			l.SetText("Status: OK")
+			l.SetID("status")
+			outer.AddChild(inner)
+			outer.AddChild(l)
+			r.Window.AddChild(outer)
+		})
+
+		r.Do(func() {
+			w := r.Window
+			test.AssertEquals(t, 2, len(guix.Query(w, "LinearLayout")))
+			test.AssertEquals(t, 2, len(guix.Query(w, "LinearLayout Button")))
+			test.AssertEquals(t, 0, len(guix.Query(w, "LinearLayout > LinearLayout > LinearLayout")))
+			test.AssertEquals(t, 1, len(guix.Query(w, "LinearLayout > Button[text=OK]")))
+			test.AssertEquals(t, 1, len(guix.Query(w, "#toolbar > :last-child")))
+			test.AssertEquals(t, 1, len(guix.Query(w, "Button > Label[text=Cancel]")))
+			// Buttons display their text with a child Label.
+			test.AssertEquals(t, 3, len(guix.Query(w, "[text$=OK]")))
+			test.AssertEquals(t, 5, len(guix.Query(w, "[text]")))
+
+			cancel := guix.QueryFirst(w, "#toolbar Button:nth-child(2)")
+			test.AssertEquals(t, "Cancel", cancel.(guix.Button).Text())
+			test.AssertEquals(t, true, guix.QueryFirst(w, "Button[text=Apply]") == nil)
+
+			var label guix.Label
+			test.AssertEquals(t, true, guix.QueryInto(w, "#status", &label))
+			test.AssertEquals(t, "Status: OK", label.Text())
+
+			var button guix.Button
+			test.AssertEquals(t, false, guix.QueryInto(w, "#status", &button))
+		})
+	})
+}
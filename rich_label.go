@@ -0,0 +1,32 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+// RichLabel is a read-only control that displays an AttributedString, with
+// per-span fonts, colors, backgrounds, underlines and links.
+type RichLabel interface {
+	Control
+	Text() *AttributedString
+	SetText(*AttributedString)
+
+	// Font and Color are used for the spans of the text that do not
+	// specify a font or color.
+	Font() Font
+	SetFont(Font)
+	Color() Color
+	SetColor(Color)
+
+	// LinkColor is used for link spans that do not specify a color.
+	LinkColor() Color
+	SetLinkColor(Color)
+
+	// Wrap returns how lines that are wider than the label are broken.
+	Wrap() TextWrap
+	SetWrap(TextWrap)
+
+	// OnLinkClicked subscribes f to be called with the link of a span when
+	// the span is clicked.
+	OnLinkClicked(f func(MouseEvent, string)) EventSubscription
+}
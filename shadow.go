@@ -0,0 +1,194 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/vcaesar/guix/math"
+)
+
+// Shadow describes the drop shadow of a rectangle, as drawn by
+// Canvas.DrawShadow.
+type Shadow struct {
+	// Offset is the offset of the shadow from the rectangle, in DIPs.
+	Offset math.Vec2
+
+	// Blur is the blur radius in DIPs. The edge of the shadow fades over a
+	// distance of twice the blur radius. A blur of 0 gives a hard edge.
+	Blur float32
+
+	// Spread is the distance the rectangle is grown by before it is blurred,
+	// or shrunk by if negative.
+	Spread float32
+
+	Color Color
+}
+
+// NoShadow is a shadow that draws nothing.
+var NoShadow Shadow
+
+func CreateShadow(offset math.Vec2, blur, spread float32, color Color) Shadow {
+	return Shadow{Offset: offset, Blur: blur, Spread: spread, Color: color}
+}
+
+// IsVisible returns false if the shadow draws nothing.
+func (s Shadow) IsVisible() bool {
+	return s.Color.A > 0
+}
+
+// Bounds returns the rectangle covered by the shadow of the rectangle r.
+func (s Shadow) Bounds(r math.Rect) math.Rect {
+	e := s.Spread + math.Maxf(s.Blur, 0)*1.5
+	return math.Rect{
+		Min: math.Point{
+			X: int(math.Floorf(float32(r.Min.X) + s.Offset.X - e)),
+			Y: int(math.Floorf(float32(r.Min.Y) + s.Offset.Y - e)),
+		},
+		Max: math.Point{
+			X: int(math.Ceilf(float32(r.Max.X) + s.Offset.X + e)),
+			Y: int(math.Ceilf(float32(r.Max.Y) + s.Offset.Y + e)),
+		},
+	}.Canon()
+}
+
+// shadowShape is the rounded rectangle casting a shadow, after applying the
+// offset and spread.
+type shadowShape struct {
+	min, max       math.Vec2
+	tl, tr, bl, br float32
+}
+
+func (s Shadow) shape(r math.Rect, tl, tr, bl, br float32) shadowShape {
+	spread := math.Vec2{X: s.Spread, Y: s.Spread}
+	sh := shadowShape{
+		min: r.Min.Vec2().Add(s.Offset).Sub(spread),
+		max: r.Max.Vec2().Add(s.Offset).Add(spread),
+	}
+	// Rounded corners grow with the spread, square corners stay square.
+	limit := math.Minf(sh.max.X-sh.min.X, sh.max.Y-sh.min.Y) / 2
+	radius := func(r float32) float32 {
+		if r <= 0 {
+			return 0
+		}
+		return math.Clampf(r+s.Spread, 0, limit)
+	}
+	sh.tl, sh.tr, sh.bl, sh.br = radius(tl), radius(tr), radius(bl), radius(br)
+	return sh
+}
+
+// Path returns the outline of the shadow of the rounded rectangle r before it
+// is blurred, after applying the offset and spread.
+func (s Shadow) Path(r math.Rect, tl, tr, bl, br float32) *Path2D {
+	sh := s.shape(r, tl, tr, bl, br)
+	p := &Path2D{}
+	if sh.min.X >= sh.max.X || sh.min.Y >= sh.max.Y {
+		return p
+	}
+	corner := func(radius float32, pt math.Vec2) {
+		p.ArcTo(math.Vec2{X: radius, Y: radius}, 0, false, true, pt)
+	}
+	p.MoveTo(math.Vec2{X: sh.min.X + sh.tl, Y: sh.min.Y})
+	p.LineTo(math.Vec2{X: sh.max.X - sh.tr, Y: sh.min.Y})
+	corner(sh.tr, math.Vec2{X: sh.max.X, Y: sh.min.Y + sh.tr})
+	p.LineTo(math.Vec2{X: sh.max.X, Y: sh.max.Y - sh.br})
+	corner(sh.br, math.Vec2{X: sh.max.X - sh.br, Y: sh.max.Y})
+	p.LineTo(math.Vec2{X: sh.min.X + sh.bl, Y: sh.max.Y})
+	corner(sh.bl, math.Vec2{X: sh.min.X, Y: sh.max.Y - sh.bl})
+	p.LineTo(math.Vec2{X: sh.min.X, Y: sh.min.Y + sh.tl})
+	corner(sh.tl, math.Vec2{X: sh.min.X + sh.tl, Y: sh.min.Y})
+	p.Close()
+	return p
+}
+
+// inset returns the distance of the edge of the shape from its left and
+// right sides at y, due to the rounded corners.
+func (sh shadowShape) inset(y float32) (left, right float32) {
+	corner := func(r float32, top bool) float32 {
+		var d float32
+		if top {
+			d = sh.min.Y + r - y
+		} else {
+			d = y - (sh.max.Y - r)
+		}
+		if d <= 0 {
+			return 0
+		}
+		return r - math.Sqrtf(math.Maxf(r*r-d*d, 0))
+	}
+	if y < (sh.min.Y+sh.max.Y)/2 {
+		return corner(sh.tl, true), corner(sh.tr, true)
+	}
+	return corner(sh.bl, false), corner(sh.br, false)
+}
+
+// Alpha returns the opacity, from 0 to 1, of the shadow of the rounded
+// rectangle r at the point p. The opacity is not multiplied by the alpha of
+// the shadow's color.
+//
+// The shadow is the rounded rectangle convolved with a Gaussian whose
+// standard deviation is half the blur radius. Horizontally the convolution
+// is solved exactly; vertically it is sampled.
+func (s Shadow) Alpha(r math.Rect, tl, tr, bl, br float32, p math.Vec2) float32 {
+	sh := s.shape(r, tl, tr, bl, br)
+	if sh.min.X >= sh.max.X || sh.min.Y >= sh.max.Y {
+		return 0
+	}
+	σ := s.Blur / 2
+	if σ < 0.01 {
+		if p.Y < sh.min.Y || p.Y >= sh.max.Y {
+			return 0
+		}
+		left, right := sh.inset(p.Y)
+		if p.X < sh.min.X+left || p.X >= sh.max.X-right {
+			return 0
+		}
+		return 1
+	}
+	low, high := math.Maxf(p.Y-3*σ, sh.min.Y), math.Minf(p.Y+3*σ, sh.max.Y)
+	if low >= high {
+		return 0
+	}
+	const steps = 8
+	step := (high - low) / steps
+	k := 1 / (σ * math.Sqrtf(2))
+	alpha := float32(0)
+	for i := 0; i < steps; i++ {
+		y := low + step*(float32(i)+0.5)
+		left, right := sh.inset(y)
+		dy := (y - p.Y) / σ
+		g := math.Expf(-dy*dy/2) / (σ * math.Sqrtf(math.TwoPi))
+		x := (math.Erff((p.X-sh.min.X-left)*k) - math.Erff((p.X-sh.max.X+right)*k)) / 2
+		alpha += x * g * step
+	}
+	return math.Saturate(alpha)
+}
+
+// Image returns an image of the shadow of the rounded rectangle r with
+// pixelsPerDip pixels to each DIP, covering Bounds(r).
+func (s Shadow) Image(r math.Rect, tl, tr, bl, br float32, pixelsPerDip float32) image.Image {
+	b := s.Bounds(r)
+	w := math.Max(int(math.Ceilf(float32(b.W())*pixelsPerDip)), 1)
+	h := math.Max(int(math.Ceilf(float32(b.H())*pixelsPerDip)), 1)
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	c := s.Color.Saturate()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := math.Vec2{
+				X: float32(b.Min.X) + (float32(x)+0.5)*float32(b.W())/float32(w),
+				Y: float32(b.Min.Y) + (float32(y)+0.5)*float32(b.H())/float32(h),
+			}
+			a := s.Alpha(r, tl, tr, bl, br, p) * c.A
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(c.R*255 + 0.5),
+				G: uint8(c.G*255 + 0.5),
+				B: uint8(c.B*255 + 0.5),
+				A: uint8(a*255 + 0.5),
+			})
+		}
+	}
+	return img
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestShadowAlpha(t *testing.T) {
+	r := math.CreateRect(0, 0, 100, 100)
+	s := CreateShadow(math.Vec2{X: 10, Y: 0}, 4, 0, Black)
+	near := func(want, got float32) {
+		if math.Absf(want-got) > 0.02 {
+			t.Errorf("Expected alpha %v, got %v", want, got)
+		}
+	}
+	near(1, s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 60, Y: 50}))
+	near(0.5, s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 110, Y: 50}))
+	near(0, s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 120, Y: 50}))
+	near(0.25, s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 110, Y: 100}))
+
+	// Rounded corners fade earlier.
+	if a := s.Alpha(r, 0, 20, 0, 0, math.Vec2{X: 109, Y: 1}); a > 0.1 {
+		t.Errorf("Expected the rounded corner to be clear, got %v", a)
+	}
+
+	// Spread grows the shadow before blurring.
+	s = CreateShadow(math.Vec2{}, 0, 5, Black)
+	test.AssertEquals(t, float32(1), s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 104, Y: 50}))
+	test.AssertEquals(t, float32(0), s.Alpha(r, 0, 0, 0, 0, math.Vec2{X: 106, Y: 50}))
+	test.AssertEquals(t, math.CreateRect(-5, -5, 105, 105), s.Bounds(r))
+	test.AssertEquals(t, math.CreateRect(-5, -5, 105, 105), s.Path(r, 0, 0, 0, 0).Bounds())
+}
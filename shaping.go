@@ -0,0 +1,350 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"unicode"
+
+	"github.com/vcaesar/guix/math"
+)
+
+// GlyphMetrics provides the metrics of a font's glyphs to ShapeText.
+// Drivers implement it on their fonts.
+type GlyphMetrics interface {
+	// HasGlyph returns true if the font has a glyph for r.
+	HasGlyph(r rune) bool
+
+	// Advance returns the advance width of the glyph for r, in DIPs.
+	Advance(r rune) int
+
+	// Kern returns the kerning adjustment between the glyphs for a and b,
+	// where a is drawn to the left of b, in DIPs.
+	Kern(a, b rune) int
+}
+
+// ShapedText is the layout of a sequence of runes produced by ShapeText.
+// Positions are in DIPs, relative to the left end of the baseline of the
+// first line.
+type ShapedText struct {
+	// Glyphs holds the rune whose glyph is drawn for each rune of the text,
+	// as returned by ShapeGlyphs.
+	Glyphs []rune
+
+	// Offsets holds the position of the glyph of each rune.
+	Offsets []math.Point
+
+	// Carets holds the position of a caret placed before each rune, with an
+	// extra element for the end of the text. Runes within a grapheme cluster
+	// share the caret position of the start of the cluster.
+	Carets []math.Point
+
+	// Size is the size of the text.
+	Size math.Size
+}
+
+// ShapeText lays out the runes, which may span several lines, using the
+// metrics m and a line height of lineHeight DIPs. Lines are reordered for
+// display with the Unicode Bidirectional Algorithm, grapheme clusters are
+// kept together, contextual forms are chosen with ShapeGlyphs and pairs of
+// glyphs are kerned. The pre-base matras of Indic scripts, such as the
+// Devanagari vowel sign I, are drawn before the consonants of their cluster.
+// Other Indic forms, such as reph and conjunct ligatures, are substituted by
+// the font's OpenType tables, which are not applied: conjuncts are drawn as
+// their consonants with a visible virama.
+func ShapeText(runes []rune, lineHeight int, m GlyphMetrics) ShapedText {
+	return ShapeTextBlock(&TextBlock{Runes: runes}, lineHeight, m)
+}
+
+// ShapeTextBlock lays out the runes of tb like ShapeText, also wrapping and
+// spacing the lines as described by tb. The alignment of tb is ignored.
+func ShapeTextBlock(tb *TextBlock, lineHeight int, m GlyphMetrics) ShapedText {
+	runes := tb.Runes
+	t := ShapedText{
+		Glyphs:  ShapeGlyphs(runes, m),
+		Offsets: make([]math.Point, len(runes)),
+		Carets:  make([]math.Point, len(runes)+1),
+		Size:    math.Size{H: lineHeight},
+	}
+	levels := BidiLevels(runes)
+	step := tb.lineStep(lineHeight)
+	y := 0
+	for s := 0; s <= len(runes); {
+		e := s
+		for e < len(runes) && runes[e] != '\n' {
+			e++
+		}
+		for _, l := range wrapLine(runes, s, e, tb, m) {
+			w := t.shapeLine(runes, levels, l.start, l.end, y, m)
+			if l.end > l.start {
+				t.Size = t.Size.Max(math.Size{W: w, H: y + lineHeight})
+			}
+			// Whitespace at a line break hangs off the end of the line.
+			for i := l.end + 1; i < l.next; i++ {
+				t.Offsets[i], t.Carets[i] = t.Carets[l.end], t.Carets[l.end]
+			}
+			if l.end < l.next {
+				t.Offsets[l.end] = t.Carets[l.end]
+			}
+			y += step
+		}
+		if e < len(runes) {
+			t.Offsets[e] = t.Carets[e] // The line break
+		}
+		s = e + 1
+	}
+	return t
+}
+
+// lineRange is a line of text produced by wrapLine. The runes [start, end)
+// are laid out on the line, and the next line starts at next.
+type lineRange struct{ start, end, next int }
+
+// wrapLine breaks the runes [s, e), which hold no newlines, into lines as
+// described by tb.
+func wrapLine(runes []rune, s, e int, tb *TextBlock, m GlyphMetrics) []lineRange {
+	if tb.Wrap == WrapNone || tb.WrapWidth <= 0 {
+		return []lineRange{{s, e, e}}
+	}
+	runes = runes[:e]
+	lines := []lineRange{}
+	for {
+		l := lineRange{s, e, e}
+		wordEnd := -1 // The end of the last word on the line
+		for i, x := s, 0; i < e; {
+			n := NextGrapheme(runes, i)
+			space := unicode.IsSpace(runes[i])
+			if space && i > s && !unicode.IsSpace(runes[i-1]) {
+				wordEnd = i
+			}
+			w := 0
+			for j := i; j < n; j++ {
+				w += m.Advance(runes[j])
+			}
+			if x+w > tb.WrapWidth && i > s && !space {
+				l.end, l.next = i, i
+				if tb.Wrap == WrapWord && wordEnd > s {
+					l.end, l.next = wordEnd, wordEnd
+					for l.next < e && unicode.IsSpace(runes[l.next]) {
+						l.next++
+					}
+				}
+				break
+			}
+			x += w
+			i = n
+		}
+		lines = append(lines, l)
+		if l.next >= e {
+			return lines
+		}
+		s = l.next
+	}
+}
+
+// shapeLine lays out the runes [s, e) of a single line at the height y,
+// returning the width of the line.
+func (t *ShapedText) shapeLine(runes []rune, levels []int, s, e, y int, m GlyphMetrics) int {
+	type cluster struct{ start, end, left, right int }
+	clusters := []cluster{}
+	clusterLevels := []int{}
+	for i := s; i < e; i = NextGrapheme(runes[:e], i) {
+		clusters = append(clusters, cluster{start: i, end: NextGrapheme(runes[:e], i)})
+		clusterLevels = append(clusterLevels, levels[i])
+	}
+
+	x := 0
+	var prev rune
+	prevRTL := false
+	for _, ci := range VisualOrder(clusterLevels) {
+		c := &clusters[ci]
+		rtl := clusterLevels[ci]%2 == 1
+		first := true
+		matra := preBaseMatra(runes, c.start, c.end)
+		for k := c.start; k < c.end; k++ {
+			i := k
+			if matra > c.start {
+				// Draw the pre-base matra before the consonants.
+				switch {
+				case k == c.start:
+					i = matra
+				case k <= matra:
+					i = k - 1
+				}
+			}
+			g := t.Glyphs[i]
+			if g != 0 && prev != 0 && !unicode.IsSpace(g) {
+				if rtl && prevRTL {
+					x += m.Kern(g, prev)
+				} else {
+					x += m.Kern(prev, g)
+				}
+			}
+			if first {
+				c.left, first = x, false
+			}
+			t.Offsets[i] = math.Point{X: x, Y: y}
+			if g != 0 {
+				x += m.Advance(g)
+				prev = g
+			}
+		}
+		prevRTL = rtl
+		c.right = x
+	}
+
+	// Carets are placed at the leading edge of each cluster, in the
+	// direction of the cluster.
+	for ci, c := range clusters {
+		x := c.left
+		if clusterLevels[ci]%2 == 1 {
+			x = c.right
+		}
+		for i := c.start; i < c.end; i++ {
+			t.Carets[i] = math.Point{X: x, Y: y}
+		}
+	}
+	// The caret at the end of the line is at the trailing edge of the last
+	// cluster.
+	end := math.Point{Y: y}
+	if n := len(clusters); n > 0 {
+		end.X = clusters[n-1].right
+		if clusterLevels[n-1]%2 == 1 {
+			end.X = clusters[n-1].left
+		}
+	}
+	t.Carets[e] = end
+	return x
+}
+
+// isPreBaseMatra returns true if r is a vowel sign of an Indic script that
+// follows its consonants in the text, but is drawn before them.
+func isPreBaseMatra(r rune) bool {
+	switch r {
+	case 0x093f, 0x094e, // Devanagari
+		0x09bf, 0x09c7, 0x09c8, // Bengali
+		0x0a3f,                 // Gurmukhi
+		0x0abf,                 // Gujarati
+		0x0b47,                 // Oriya
+		0x0bc6, 0x0bc7, 0x0bc8, // Tamil
+		0x0d46, 0x0d47, 0x0d48, // Malayalam
+		0x0dd9, 0x0dda, 0x0ddb: // Sinhala
+		return true
+	}
+	return false
+}
+
+// preBaseMatra returns the index of the pre-base matra of the cluster of
+// runes [s, e), or -1 if it has none.
+func preBaseMatra(runes []rune, s, e int) int {
+	for i := s + 1; i < e; i++ {
+		if isPreBaseMatra(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// arabicForms holds the first of the presentation forms of Arabic letters,
+// and the number of forms. Letters with 4 forms are dual-joining, and have
+// isolated, final, initial and medial forms in that order. Letters with 2
+// forms are right-joining, and have isolated and final forms.
+var arabicForms = map[rune]struct {
+	first rune
+	count int
+}{
+	0x0621: {0xfe80, 1}, 0x0622: {0xfe81, 2}, 0x0623: {0xfe83, 2}, 0x0624: {0xfe85, 2},
+	0x0625: {0xfe87, 2}, 0x0626: {0xfe89, 4}, 0x0627: {0xfe8d, 2}, 0x0628: {0xfe8f, 4},
+	0x0629: {0xfe93, 2}, 0x062a: {0xfe95, 4}, 0x062b: {0xfe99, 4}, 0x062c: {0xfe9d, 4},
+	0x062d: {0xfea1, 4}, 0x062e: {0xfea5, 4}, 0x062f: {0xfea9, 2}, 0x0630: {0xfeab, 2},
+	0x0631: {0xfead, 2}, 0x0632: {0xfeaf, 2}, 0x0633: {0xfeb1, 4}, 0x0634: {0xfeb5, 4},
+	0x0635: {0xfeb9, 4}, 0x0636: {0xfebd, 4}, 0x0637: {0xfec1, 4}, 0x0638: {0xfec5, 4},
+	0x0639: {0xfec9, 4}, 0x063a: {0xfecd, 4}, 0x0641: {0xfed1, 4}, 0x0642: {0xfed5, 4},
+	0x0643: {0xfed9, 4}, 0x0644: {0xfedd, 4}, 0x0645: {0xfee1, 4}, 0x0646: {0xfee5, 4},
+	0x0647: {0xfee9, 4}, 0x0648: {0xfeed, 2}, 0x0649: {0xfeef, 2}, 0x064a: {0xfef1, 4},
+}
+
+// lamAlef maps the alefs that form a ligature with a preceding lam to the
+// isolated form of the ligature. The final form follows it.
+var lamAlef = map[rune]rune{
+	0x0622: 0xfef5,
+	0x0623: 0xfef7,
+	0x0625: 0xfef9,
+	0x0627: 0xfefb,
+}
+
+const (
+	tatweel = 0x0640
+	lam     = 0x0644
+	zwj     = 0x200d
+)
+
+// joinsBefore returns true if r can join to the rune after it.
+func joinsBefore(r rune) bool {
+	if f, ok := arabicForms[r]; ok {
+		return f.count == 4
+	}
+	return r == tatweel || r == zwj
+}
+
+// joinsAfter returns true if r can join to the rune before it.
+func joinsAfter(r rune) bool {
+	if f, ok := arabicForms[r]; ok {
+		return f.count > 1
+	}
+	return r == tatweel || r == zwj
+}
+
+// ShapeGlyphs returns the rune whose glyph is drawn for each of the runes.
+// Arabic letters are replaced with their contextual presentation forms and
+// lam-alef pairs with their ligatures, where m has glyphs for them. Runes
+// that are drawn as part of a ligature are 0.
+func ShapeGlyphs(runes []rune, m GlyphMetrics) []rune {
+	glyphs := append([]rune(nil), runes...)
+	// neighbour returns the index of the nearest rune to i in the direction
+	// d that is not a transparent mark, or -1.
+	neighbour := func(i, d int) int {
+		for i += d; i >= 0 && i < len(runes); i += d {
+			if !unicode.In(runes[i], unicode.Mn, unicode.Me) {
+				return i
+			}
+		}
+		return -1
+	}
+	for i, r := range runes {
+		f, ok := arabicForms[r]
+		if !ok || glyphs[i] == 0 {
+			continue
+		}
+		p, n := neighbour(i, -1), neighbour(i, 1)
+		joinPrev := f.count > 1 && p >= 0 && joinsBefore(runes[p])
+		joinNext := f.count == 4 && n >= 0 && joinsAfter(runes[n])
+
+		if r == lam && n >= 0 {
+			if lig, ok := lamAlef[runes[n]]; ok {
+				if joinPrev {
+					lig++
+				}
+				if m.HasGlyph(lig) {
+					glyphs[i], glyphs[n] = lig, 0
+					continue
+				}
+			}
+		}
+
+		form := f.first
+		switch {
+		case joinPrev && joinNext:
+			form += 3
+		case joinNext:
+			form += 2
+		case joinPrev:
+			form++
+		}
+		if m.HasGlyph(form) {
+			glyphs[i] = form
+		}
+	}
+	return glyphs
+}
@@ -0,0 +1,138 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/vcaesar/guix/math"
+	test "github.com/vcaesar/guix/testing"
+)
+
+// testMetrics has a glyph for every rune, 10 DIPs wide, except for
+// non-spacing marks, which have no width. The pair AV is kerned.
+type testMetrics struct{}
+
+func (testMetrics) HasGlyph(r rune) bool { return true }
+func (testMetrics) Advance(r rune) int {
+	if unicode.Is(unicode.Mn, r) {
+		return 0
+	}
+	return 10
+}
+func (testMetrics) Kern(a, b rune) int {
+	if a == 'A' && b == 'V' {
+		return -2
+	}
+	return 0
+}
+
+func TestGraphemes(t *testing.T) {
+	runes := []rune("e\u0301x")
+	test.AssertEquals(t, 2, NextGrapheme(runes, 0))
+	test.AssertEquals(t, 0, PrevGrapheme(runes, 2))
+	test.AssertEquals(t, 3, NextGrapheme(runes, 2))
+
+	flags := []rune("\U0001f1ec\U0001f1e7\U0001f1eb\U0001f1f7")
+	test.AssertEquals(t, 2, NextGrapheme(flags, 0))
+	test.AssertEquals(t, 4, NextGrapheme(flags, 2))
+	test.AssertEquals(t, 2, PrevGrapheme(flags, 4))
+
+	crlf := []rune("a\r\nb")
+	test.AssertEquals(t, 3, NextGrapheme(crlf, 1))
+	test.AssertEquals(t, 1, PrevGrapheme(crlf, 3))
+
+	family := []rune("\U0001f469\u200d\U0001f469!")
+	test.AssertEquals(t, 3, NextGrapheme(family, 0))
+
+	// क्षि is a single cluster: a conjunct of क and ष with the vowel sign I.
+	conjunct := []rune("\u0915\u094d\u0937\u093f\u0915")
+	test.AssertEquals(t, 4, NextGrapheme(conjunct, 0))
+	test.AssertEquals(t, 0, PrevGrapheme(conjunct, 4))
+}
+
+func TestBidiLevels(t *testing.T) {
+	runes := []rune("abc אבג 123")
+	levels := BidiLevels(runes)
+	test.AssertEquals(t, []int{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2}, levels)
+	test.AssertEquals(t, []int{0, 1, 2, 3, 8, 9, 10, 7, 6, 5, 4}, VisualOrder(levels))
+
+	test.AssertEquals(t, []int{1, 1, 1, 2, 2}, BidiLevels([]rune("אב 12")))
+	test.AssertEquals(t, []int{0, 0, 0, 1, 1}, BidiLevels([]rune("ab\nאב")))
+}
+
+func TestShapeText(t *testing.T) {
+	m := testMetrics{}
+
+	s := ShapeText([]rune("AV"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 8}}, s.Offsets)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 8}, {X: 18}}, s.Carets)
+	test.AssertEquals(t, math.Size{W: 18, H: 12}, s.Size)
+
+	s = ShapeText([]rune("אב"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 10}, {X: 0}}, s.Offsets)
+	test.AssertEquals(t, []math.Point{{X: 20}, {X: 10}, {X: 0}}, s.Carets)
+
+	s = ShapeText([]rune("e\u0301"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 10}}, s.Offsets)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 0}, {X: 10}}, s.Carets)
+
+	// The vowel sign I is drawn before its consonants, with the caret before
+	// the cluster.
+	s = ShapeText([]rune("\u0915\u093f"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 10}, {X: 0}}, s.Offsets)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 0}, {X: 20}}, s.Carets)
+	s = ShapeText([]rune("\u0915\u094d\u0937\u093f"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 10}, {X: 20}, {X: 20}, {X: 0}}, s.Offsets)
+
+	s = ShapeText([]rune("a\nb"), 12, m)
+	test.AssertEquals(t, []math.Point{{X: 0}, {X: 10}, {X: 0, Y: 12}}, s.Offsets)
+	test.AssertEquals(t, math.Size{W: 10, H: 24}, s.Size)
+
+	s = ShapeText(nil, 12, m)
+	test.AssertEquals(t, math.Size{H: 12}, s.Size)
+}
+
+func TestShapeGlyphs(t *testing.T) {
+	m := testMetrics{}
+	test.AssertEquals(t, []rune{0xfe91, 0xfe90}, ShapeGlyphs([]rune("بب"), m))
+	test.AssertEquals(t, []rune{0xfe8f}, ShapeGlyphs([]rune("ب"), m))
+	test.AssertEquals(t, []rune{0xfefb, 0}, ShapeGlyphs([]rune("لا"), m))
+	test.AssertEquals(t, []rune{0xfe91, 0xfefc, 0}, ShapeGlyphs([]rune("بلا"), m))
+	test.AssertEquals(t, []rune("abc"), ShapeGlyphs([]rune("abc"), m))
+}
+
+func TestShapeTextWrap(t *testing.T) {
+	m := testMetrics{}
+
+	tb := &TextBlock{Runes: []rune("ab cd ef"), Wrap: WrapWord, WrapWidth: 50}
+	s := ShapeTextBlock(tb, 12, m)
+	test.AssertEquals(t, []math.Point{
+		{X: 0}, {X: 10}, {X: 20}, {X: 30}, {X: 40}, {X: 50},
+		{X: 0, Y: 12}, {X: 10, Y: 12},
+	}, s.Offsets)
+	test.AssertEquals(t, math.Point{X: 0, Y: 12}, s.Carets[6])
+	test.AssertEquals(t, math.Size{W: 50, H: 24}, s.Size)
+
+	tb.LineSpacing = 1.5
+	s = ShapeTextBlock(tb, 12, m)
+	test.AssertEquals(t, math.Point{X: 0, Y: 18}, s.Offsets[6])
+	test.AssertEquals(t, math.Size{W: 50, H: 30}, s.Size)
+
+	tb = &TextBlock{Runes: []rune("abcdef"), Wrap: WrapWord, WrapWidth: 25}
+	s = ShapeTextBlock(tb, 12, m)
+	test.AssertEquals(t, math.Point{X: 0, Y: 24}, s.Offsets[4])
+	test.AssertEquals(t, math.Size{W: 20, H: 36}, s.Size)
+
+	tb = &TextBlock{Runes: []rune("ab cd"), Wrap: WrapChar, WrapWidth: 25}
+	s = ShapeTextBlock(tb, 12, m)
+	test.AssertEquals(t, []math.Point{
+		{X: 0}, {X: 10}, {X: 20}, {X: 0, Y: 12}, {X: 10, Y: 12},
+	}, s.Offsets)
+
+	tb = &TextBlock{Runes: []rune("ab cd ef")}
+	test.AssertEquals(t, math.Size{W: 80, H: 12}, ShapeTextBlock(tb, 12, m).Size)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guixtest
+
+import (
+	"image"
+	"image/color"
+)
+
+var (
+	diffMatch    = color.RGBA{0, 0, 0, 255}
+	diffMismatch = color.RGBA{255, 0, 255, 255}
+)
+
+// Compare compares the images expected and actual pixel by pixel, returning
+// a difference image and the number of pixels with a color channel that
+// differs by more than tolerance. Pixels that match are drawn in the
+// difference image as a faded copy of expected, mismatching pixels are drawn
+// magenta. Images of different sizes are compared over the union of their
+// bounds, with pixels outside of either image counted as mismatching.
+func Compare(expected, actual image.Image, tolerance uint8) (diff *image.RGBA, count int) {
+	eb, ab := expected.Bounds(), actual.Bounds()
+	size := eb.Size()
+	if ab.Dx() > size.X {
+		size.X = ab.Dx()
+	}
+	if ab.Dy() > size.Y {
+		size.Y = ab.Dy()
+	}
+	diff = image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			ep, ap := image.Pt(eb.Min.X+x, eb.Min.Y+y), image.Pt(ab.Min.X+x, ab.Min.Y+y)
+			if !ep.In(eb) || !ap.In(ab) {
+				diff.SetRGBA(x, y, diffMismatch)
+				count++
+				continue
+			}
+			e := color.RGBAModel.Convert(expected.At(ep.X, ep.Y)).(color.RGBA)
+			a := color.RGBAModel.Convert(actual.At(ap.X, ap.Y)).(color.RGBA)
+			if channelDiff(e.R, a.R) > tolerance ||
+				channelDiff(e.G, a.G) > tolerance ||
+				channelDiff(e.B, a.B) > tolerance ||
+				channelDiff(e.A, a.A) > tolerance {
+				diff.SetRGBA(x, y, diffMismatch)
+				count++
+			} else {
+				diff.SetRGBA(x, y, fade(e))
+			}
+		}
+	}
+	return diff, count
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// fade returns c blended 75% towards diffMatch.
+func fade(c color.RGBA) color.RGBA {
+	return color.RGBA{
+		R: diffMatch.R + c.R/4,
+		G: diffMatch.G + c.G/4,
+		B: diffMatch.B + c.B/4,
+		A: 255,
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guixtest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	test "github.com/vcaesar/guix/testing"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompareEqual(t *testing.T) {
+	a := solid(4, 4, color.RGBA{10, 20, 30, 255})
+	b := solid(4, 4, color.RGBA{10, 20, 30, 255})
+	_, count := Compare(a, b, 0)
+	test.AssertEquals(t, 0, count)
+}
+
+func TestCompareTolerance(t *testing.T) {
+	a := solid(4, 4, color.RGBA{10, 20, 30, 255})
+	b := solid(4, 4, color.RGBA{12, 20, 30, 255})
+	b.SetRGBA(1, 2, color.RGBA{20, 20, 30, 255})
+
+	_, count := Compare(a, b, 0)
+	test.AssertEquals(t, 16, count)
+
+	diff, count := Compare(a, b, 2)
+	test.AssertEquals(t, 1, count)
+	test.AssertEquals(t, diffMismatch, diff.RGBAAt(1, 2))
+	test.AssertEquals(t, false, diff.RGBAAt(0, 0) == diffMismatch)
+}
+
+func TestCompareSizeMismatch(t *testing.T) {
+	a := solid(4, 4, color.RGBA{0, 0, 0, 255})
+	b := solid(4, 2, color.RGBA{0, 0, 0, 255})
+	diff, count := Compare(a, b, 0)
+	test.AssertEquals(t, 8, count)
+	test.AssertEquals(t, image.Rect(0, 0, 4, 4), diff.Bounds())
+}
+
+func TestRender(t *testing.T) {
+	img := Render(Options{}, nil)
+	test.AssertEquals(t, image.Rect(0, 0, 200, 100), img.Bounds())
+
+	img = Render(Options{Scale: 2}, nil)
+	test.AssertEquals(t, image.Rect(0, 0, 400, 200), img.Bounds())
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package guixtest provides golden-image snapshot testing and scripted input
+// for guix controls.
+//
+// Controls are rendered off-screen with the soft driver and compared against
+// PNG files held in the testdata directory of the package under test. Run the
+// tests with the -update flag to regenerate the golden images.
+//
+// Run creates a window and a Robot that drives it with simulated mouse and
+// keyboard input, allowing interactions to be tested end to end.
+package guixtest
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+)
+
+// Update, when true, causes AssertGolden to rewrite golden images instead of
+// comparing against them. It is set with the -update command line flag.
+var Update = flag.Bool("update", false, "Regenerate golden images instead of comparing against them.")
+
+// GoldenDir is the directory, relative to the package under test, that holds
+// the golden images.
+var GoldenDir = "testdata"
+
+// Options controls how a control is rendered and compared.
+type Options struct {
+	// Size is the size of the window holding the control, in DIPs.
+	// If zero, a 200x100 window is used.
+	Size math.Size
+
+	// Scale is the display scaling of the window. If zero, a scale of 1 is
+	// used.
+	Scale float32
+
+	// Tolerance is the largest difference allowed between the golden and
+	// rendered value of any pixel color channel.
+	Tolerance uint8
+
+	// CreateTheme creates the theme used to create the window and control.
+	// If nil, the dark theme is used.
+	CreateTheme func(driver guix.Driver) guix.Theme
+}
+
+// Render creates a window with the options o, adds the control returned by
+// create and returns the rendered window in pixels. create is called on the
+// UI go-routine. If create is nil then the empty window is rendered.
+func Render(o Options, create func(theme guix.Theme) guix.Control) *image.RGBA {
+	var img *image.RGBA
+	Run(o, func(r *Robot) {
+		if create != nil {
+			r.Do(func() { r.Window.AddChild(create(r.Theme)) })
+		}
+		img = r.Frame()
+	})
+	return img
+}
+
+// AssertSnapshot renders the control returned by create with Render and
+// compares the result against the golden image name with AssertGolden.
+func AssertSnapshot(t *testing.T, name string, o Options, create func(theme guix.Theme) guix.Control) {
+	t.Helper()
+	AssertGolden(t, name, Render(o, create), o.Tolerance)
+}
+
+// AssertGolden compares img against the golden image GoldenDir/name.png.
+// Any pixel color channel that differs by more than tolerance fails the
+// test, and the rendered and difference images are written alongside the
+// golden image as name.actual.png and name.diff.png.
+// If the -update flag is set, the golden image is replaced with img.
+func AssertGolden(t *testing.T, name string, img image.Image, tolerance uint8) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".png")
+	if *Update {
+		if err := WritePNG(path, img); err != nil {
+			t.Fatalf("Failed to update golden image: %v", err)
+		}
+		return
+	}
+
+	golden, err := ReadPNG(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden image (run with -update to create it): %v", err)
+	}
+
+	diff, count := Compare(golden, img, tolerance)
+	if count == 0 {
+		return
+	}
+	actualPath := filepath.Join(GoldenDir, name+".actual.png")
+	diffPath := filepath.Join(GoldenDir, name+".diff.png")
+	if err := WritePNG(actualPath, img); err != nil {
+		t.Errorf("Failed to write %s: %v", actualPath, err)
+	}
+	if err := WritePNG(diffPath, diff); err != nil {
+		t.Errorf("Failed to write %s: %v", diffPath, err)
+	}
+	t.Errorf("%s: %d pixels differ by more than %d. See %s and %s",
+		path, count, tolerance, actualPath, diffPath)
+}
+
+// ReadPNG decodes the PNG file at path.
+func ReadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// WritePNG encodes img to the PNG file at path, creating any missing parent
+// directories.
+func WritePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
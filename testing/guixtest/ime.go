@@ -0,0 +1,81 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guixtest
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// IME is a scripted stand-in for an input method editor (IME). It composes
+// text into the focused control of the Robot's window, raising the same
+// composition events on the viewport as a platform input method.
+//
+// Like Robot methods, IME methods must not be called on the UI go-routine,
+// and wait for the events to be processed before returning.
+type IME struct {
+	r         *Robot
+	composing bool
+}
+
+// IME returns the input method used to compose text in the Robot's window.
+func (r *Robot) IME() *IME {
+	if r.ime == nil {
+		r.ime = &IME{r: r}
+	}
+	return r.ime
+}
+
+// Preedit sets the pre-edit text and the rune index of its cursor, starting
+// a composition if none is in progress.
+func (i *IME) Preedit(text string, cursor int) {
+	if i.composing {
+		i.r.viewport.InjectCompositionUpdate(text, cursor)
+	} else {
+		i.composing = true
+		i.r.viewport.InjectCompositionStart(text, cursor)
+	}
+	i.r.Flush()
+}
+
+// Commit ends the composition, committing text.
+func (i *IME) Commit(text string) {
+	if !i.composing {
+		i.r.viewport.InjectCompositionStart("", 0)
+	}
+	i.composing = false
+	i.r.viewport.InjectCompositionEnd(text)
+	i.r.Flush()
+}
+
+// Cancel ends the composition without committing any text.
+func (i *IME) Cancel() {
+	if i.composing {
+		i.composing = false
+		i.r.viewport.InjectCompositionEnd("")
+		i.r.Flush()
+	}
+}
+
+// Compose types the runes of input one at a time as the pre-edit text, as
+// when spelling out a word phonetically, and then commits text. For example
+// Compose("nihao", "你好") composes the pinyin for "你好".
+func (i *IME) Compose(input, commit string) {
+	runes := []rune(input)
+	for n := 1; n <= len(runes); n++ {
+		i.Preedit(string(runes[:n]), n)
+	}
+	i.Commit(commit)
+}
+
+// Composing returns true while a composition is in progress.
+func (i *IME) Composing() bool {
+	return i.composing
+}
+
+// CandidateRect returns the bounds, in DIPs relative to the window, that the
+// input method's candidate window is anchored to.
+func (i *IME) CandidateRect() math.Rect {
+	return i.r.viewport.CompositionRect()
+}
@@ -0,0 +1,230 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guixtest
+
+import (
+	"image"
+	"time"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/drivers/soft"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/themes/dark"
+)
+
+// Robot drives a window with simulated input. Input is injected into the
+// window's soft viewport, so it is delivered through the same mouse, keyboard
+// and focus controllers as real input.
+//
+// Robot methods must not be called on the UI go-routine. Each method waits for
+// the UI go-routine to process the input and any layout or redraw it causes
+// before returning.
+type Robot struct {
+	Driver guix.Driver
+	Theme  guix.Theme
+	Window guix.Window
+
+	// Modifier is the keyboard modifier held while pressing and releasing
+	// mouse buttons.
+	Modifier guix.KeyboardModifier
+
+	viewport   soft.Viewport
+	lastUpTime map[guix.MouseButton]time.Time
+	ime        *IME
+}
+
+// Run creates a window with the options o and calls f with a Robot for the
+// window. f is called on a go-routine other than the UI go-routine. Run
+// returns once f has returned and the driver has terminated. If f panics
+// then Run panics with the same value.
+func Run(o Options, f func(r *Robot)) {
+	if o.Size == math.ZeroSize {
+		o.Size = math.Size{W: 200, H: 100}
+	}
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	if o.CreateTheme == nil {
+		o.CreateTheme = dark.CreateTheme
+	}
+
+	var failure interface{}
+	soft.StartDriver(func(driver guix.Driver) {
+		go func() {
+			defer driver.Terminate()
+			defer func() { failure = recover() }()
+
+			r := &Robot{
+				Driver:     driver,
+				lastUpTime: make(map[guix.MouseButton]time.Time),
+			}
+			driver.CallSync(func() {
+				r.Theme = o.CreateTheme(driver)
+				w, h := o.Size.ScaleS(o.Scale).WH()
+				r.Window = r.Theme.CreateWindow(w, h, "guixtest")
+				r.Window.SetScale(o.Scale)
+				r.viewport = softViewport(r.Window.(interface {
+					Viewport() guix.Viewport
+				}).Viewport())
+			})
+			r.Flush()
+			f(r)
+		}()
+	})
+	if failure != nil {
+		panic(failure)
+	}
+}
+
+// softViewport returns the soft.Viewport v, or the soft.Viewport wrapped by v.
+func softViewport(v guix.Viewport) soft.Viewport {
+	for {
+		if s, ok := v.(soft.Viewport); ok {
+			return s
+		}
+		v = v.(interface {
+			Unwrap() guix.Viewport
+		}).Unwrap()
+	}
+}
+
+// Flush waits for the UI go-routine to process all pending events and calls.
+func (r *Robot) Flush() {
+	// The first call processes the queued input, the second any layout or
+	// redraw requested while handling it.
+	r.Driver.CallSync(func() {})
+	r.Driver.CallSync(func() {})
+}
+
+// Do calls f on the UI go-routine and then flushes.
+func (r *Robot) Do(f func()) {
+	r.Driver.CallSync(f)
+	r.Flush()
+}
+
+// Frame returns the most recently rendered frame of the window, in pixels.
+func (r *Robot) Frame() *image.RGBA {
+	r.Flush()
+	return r.viewport.Frame()
+}
+
+// Center returns the center of the control c, in window coordinates.
+func (r *Robot) Center(c guix.Control) math.Point {
+	var p math.Point
+	r.Driver.CallSync(func() {
+		p = guix.ChildToParent(c.Size().Rect().Mid(), c, r.Window)
+	})
+	return p
+}
+
+// MouseMove moves the cursor to the window point p.
+func (r *Robot) MouseMove(p math.Point) {
+	r.viewport.InjectMouseMove(p)
+	r.Flush()
+}
+
+// MouseExit moves the cursor outside of the window.
+func (r *Robot) MouseExit() {
+	r.viewport.InjectMouseExit()
+	r.Flush()
+}
+
+// MouseDown presses the mouse button b at the current cursor position.
+func (r *Robot) MouseDown(b guix.MouseButton) {
+	r.viewport.InjectMouseDown(b, r.Modifier)
+	r.Flush()
+}
+
+// MouseUp releases the mouse button b at the current cursor position.
+func (r *Robot) MouseUp(b guix.MouseButton) {
+	r.viewport.InjectMouseUp(b, r.Modifier)
+	r.lastUpTime[b] = time.Now()
+	r.Flush()
+}
+
+// Click moves the cursor to p and clicks the left mouse button. If the
+// previous click was recent enough to form a double-click, Click first waits
+// for guix.DoubleClickTime to elapse.
+func (r *Robot) Click(p math.Point) {
+	r.ClickButton(p, guix.MouseButtonLeft)
+}
+
+// ClickButton is the same as Click, using the mouse button b.
+func (r *Robot) ClickButton(p math.Point, b guix.MouseButton) {
+	if d := guix.DoubleClickTime - time.Since(r.lastUpTime[b]); d > 0 {
+		time.Sleep(d)
+	}
+	r.MouseMove(p)
+	r.MouseDown(b)
+	r.MouseUp(b)
+}
+
+// DoubleClick moves the cursor to p and clicks the left mouse button twice in
+// quick succession.
+func (r *Robot) DoubleClick(p math.Point) {
+	r.Click(p)
+	r.MouseDown(guix.MouseButtonLeft)
+	r.MouseUp(guix.MouseButtonLeft)
+}
+
+// Scroll moves the cursor to p and scrolls the mouse wheel by x and y lines.
+func (r *Robot) Scroll(p math.Point, x, y int) {
+	r.MouseMove(p)
+	r.viewport.InjectMouseScroll(x, y)
+	r.Flush()
+}
+
+// KeyDown presses the key k.
+func (r *Robot) KeyDown(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	r.viewport.InjectKeyDown(k, m)
+	r.Flush()
+}
+
+// KeyUp releases the key k.
+func (r *Robot) KeyUp(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	r.viewport.InjectKeyUp(k, m)
+	r.Flush()
+}
+
+// KeyRepeat raises a key repeat for the key k.
+func (r *Robot) KeyRepeat(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	r.viewport.InjectKeyRepeat(k, m)
+	r.Flush()
+}
+
+// KeyPress presses and then releases the key k.
+func (r *Robot) KeyPress(k guix.KeyboardKey, m guix.KeyboardModifier) {
+	r.KeyDown(k, m)
+	r.KeyUp(k, m)
+}
+
+// KeyStroke types the character c.
+func (r *Robot) KeyStroke(c rune, m guix.KeyboardModifier) {
+	r.viewport.InjectKeyStroke(c, m)
+	r.Flush()
+}
+
+// Type types each of the characters of s.
+func (r *Robot) Type(s string) {
+	for _, c := range s {
+		r.viewport.InjectKeyStroke(c, guix.ModNone)
+	}
+	r.Flush()
+}
+
+// SetFocus gives keyboard focus to the control c, or removes focus from the
+// window if c is nil, returning false if c cannot be focused.
+func (r *Robot) SetFocus(c guix.Control) bool {
+	var ok bool
+	r.Do(func() { ok = r.Window.SetFocus(c) })
+	return ok
+}
+
+// Focus returns the control with keyboard focus, or nil if there is none.
+func (r *Robot) Focus() guix.Focusable {
+	var f guix.Focusable
+	r.Driver.CallSync(func() { f = r.Window.Focus() })
+	return f
+}
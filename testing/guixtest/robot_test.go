@@ -0,0 +1,150 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guixtest
+
+import (
+	"testing"
+
+	"github.com/vcaesar/guix"
+	test "github.com/vcaesar/guix/testing"
+)
+
+func TestRobotHover(t *testing.T) {
+	Run(Options{}, func(r *Robot) {
+		var b guix.Button
+		r.Do(func() {
+			b = r.Theme.CreateButton()
+			b.SetText("Hover")
+			r.Window.AddChild(b)
+		})
+		r.MouseMove(r.Center(b))
+		test.AssertEquals(t, true, b.IsMouseOver())
+		r.MouseExit()
+		test.AssertEquals(t, false, b.IsMouseOver())
+	})
+}
+
+func TestRobotClickBubbles(t *testing.T) {
+	Run(Options{}, func(r *Robot) {
+		var b guix.Button
+		buttonClicks, layoutClicks, windowClicks := 0, 0, 0
+		r.Do(func() {
+			l := r.Theme.CreateLinearLayout()
+			b = r.Theme.CreateButton()
+			b.SetText("Click")
+			b.OnClick(func(guix.MouseEvent) { buttonClicks++ })
+			l.AddChild(b)
+			l.OnClick(func(guix.MouseEvent) { layoutClicks++ })
+			r.Window.OnClick(func(guix.MouseEvent) { windowClicks++ })
+			r.Window.AddChild(l)
+		})
+
+		// Buttons consume left clicks.
+		r.Click(r.Center(b))
+		test.AssertEquals(t, 1, buttonClicks)
+		test.AssertEquals(t, 0, layoutClicks)
+		test.AssertEquals(t, 0, windowClicks)
+
+		// Other buttons bubble up to the window.
+		r.ClickButton(r.Center(b), guix.MouseButtonRight)
+		test.AssertEquals(t, 2, buttonClicks)
+		test.AssertEquals(t, 1, layoutClicks)
+		test.AssertEquals(t, 1, windowClicks)
+	})
+}
+
+func TestRobotDoubleClick(t *testing.T) {
+	Run(Options{}, func(r *Robot) {
+		var b guix.Button
+		clicks, doubleClicks := 0, 0
+		r.Do(func() {
+			b = r.Theme.CreateButton()
+			b.SetText("Click")
+			b.OnClick(func(guix.MouseEvent) { clicks++ })
+			b.OnDoubleClick(func(guix.MouseEvent) { doubleClicks++ })
+			r.Window.AddChild(b)
+		})
+		r.DoubleClick(r.Center(b))
+		test.AssertEquals(t, 1, clicks)
+		test.AssertEquals(t, 1, doubleClicks)
+	})
+}
+
+func TestRobotFocusAndType(t *testing.T) {
+	Run(Options{}, func(r *Robot) {
+		var t1, t2 guix.TextBox
+		r.Do(func() {
+			l := r.Theme.CreateLinearLayout()
+			t1 = r.Theme.CreateTextBox()
+			t2 = r.Theme.CreateTextBox()
+			l.AddChild(t1)
+			l.AddChild(t2)
+			r.Window.AddChild(l)
+		})
+
+		r.Click(r.Center(t1))
+		test.AssertEquals(t, true, r.Focus() == t1)
+		r.Type("hi")
+
+		r.KeyPress(guix.KeyTab, guix.ModNone)
+		test.AssertEquals(t, true, r.Focus() == t2)
+		r.Type("abc")
+		r.KeyPress(guix.KeyBackspace, guix.ModNone)
+
+		r.KeyPress(guix.KeyTab, guix.ModShift)
+		test.AssertEquals(t, true, r.Focus() == t1)
+
+		r.SetFocus(nil)
+		test.AssertEquals(t, true, r.Focus() == nil)
+
+		var text1, text2 string
+		r.Do(func() { text1, text2 = t1.Text(), t2.Text() })
+		test.AssertEquals(t, "hi", text1)
+		test.AssertEquals(t, "ab", text2)
+	})
+}
+
+func TestRobotIME(t *testing.T) {
+	Run(Options{}, func(r *Robot) {
+		var b guix.TextBox
+		starts, updates, ends := 0, 0, 0
+		r.Do(func() {
+			b = r.Theme.CreateTextBox()
+			b.OnCompositionStart(func(guix.CompositionEvent) { starts++ })
+			b.OnCompositionUpdate(func(guix.CompositionEvent) { updates++ })
+			b.OnCompositionEnd(func(guix.CompositionEvent) { ends++ })
+			r.Window.AddChild(b)
+		})
+		r.SetFocus(b)
+		r.Type("a")
+
+		ime := r.IME()
+		ime.Preedit("ni", 2)
+		r.Do(func() {
+			test.AssertEquals(t, "ani", b.Text())
+			test.AssertEquals(t, true, b.IsComposing())
+		})
+		rect := ime.CandidateRect()
+		test.AssertEquals(t, true, rect.W() > 0 && rect.H() > 0)
+
+		// Key strokes are left to the input method while composing.
+		r.Type("x")
+		ime.Preedit("nihao", 5)
+		test.AssertEquals(t, true, ime.CandidateRect().W() > rect.W())
+		ime.Commit("你好")
+		r.Do(func() {
+			test.AssertEquals(t, "a你好", b.Text())
+			test.AssertEquals(t, false, b.IsComposing())
+			test.AssertEquals(t, []int{3}, b.Carets())
+		})
+		test.AssertEquals(t, 1, starts)
+		test.AssertEquals(t, 1, updates)
+		test.AssertEquals(t, 1, ends)
+
+		ime.Compose("ma", "")
+		r.Type("!")
+		r.Do(func() { test.AssertEquals(t, "a你好!", b.Text()) })
+	})
+}
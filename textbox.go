@@ -36,4 +36,126 @@ type TextBox interface {
 	LineIndex(runeIndex int) int
 	LineStart(line int) int
 	LineEnd(line int) int
+
+	// ReadOnly returns true if the text box rejects edits by the user.
+	ReadOnly() bool
+
+	// SetReadOnly sets whether the text box rejects edits by the user. The
+	// text can still be selected and copied, and changed with SetText.
+	SetReadOnly(bool)
+
+	// PasswordChar returns the rune that masks the text, or 0 if the text is
+	// displayed unmasked.
+	PasswordChar() rune
+
+	// SetPasswordChar sets the rune displayed in place of each rune of the
+	// text. While set, the text cannot be copied or cut. A value of 0
+	// displays the text unmasked.
+	SetPasswordChar(rune)
+
+	// Placeholder returns the hint displayed while the text box is empty.
+	Placeholder() string
+
+	// SetPlaceholder sets the hint displayed while the text box is empty.
+	SetPlaceholder(string)
+
+	PlaceholderColor() Color
+	SetPlaceholderColor(Color)
+
+	// EditFilter returns the filter applied to edits by the user, or nil if
+	// the edits are not filtered.
+	EditFilter() TextBoxEditFilter
+
+	// SetEditFilter sets the filter applied to edits by the user before they
+	// change the text, such as a RuneFilter or the Filter of a TextMask.
+	SetEditFilter(TextBoxEditFilter)
+
+	// MaxLength returns the maximum number of runes that can be entered, or 0
+	// if the length is not limited.
+	MaxLength() int
+
+	// SetMaxLength limits the number of runes that can be entered. The limit
+	// is applied after the edit filter. A value of 0 removes the limit.
+	SetMaxLength(int)
+
+	// Validator returns the validator of the text, or nil.
+	Validator() TextBoxValidator
+
+	// SetValidator sets the validator that checks the text each time it
+	// changes. Invalid text is not rejected, but flagged by the theme.
+	SetValidator(TextBoxValidator)
+
+	// ValidationError returns the error returned by the validator for the
+	// current text, or nil if the text is valid.
+	ValidationError() error
+
+	// OnValidationChanged subscribes f to be called with the new validation
+	// error when it changes, which is nil once the text becomes valid.
+	OnValidationChanged(f func(error)) EventSubscription
+
+	// Undo reverts the last change to the text, returning false if there is
+	// nothing to undo.
+	Undo() bool
+
+	// Redo reapplies the last change reverted by Undo, returning false if
+	// there is nothing to redo.
+	Redo() bool
+
+	CanUndo() bool
+	CanRedo() bool
+
+	// FindQuery returns the query used to find and replace text.
+	FindQuery() FindQuery
+
+	// SetFindQuery sets the query used to find and replace text.
+	SetFindQuery(FindQuery)
+
+	// FindNext selects the next match of the find query after the start of
+	// the first selection, wrapping around to the start of the text. It
+	// returns false if there are no matches, and an error if the query is not
+	// a valid regular expression.
+	FindNext() (bool, error)
+
+	// FindPrevious selects the previous match of the find query before the
+	// start of the first selection, wrapping around to the end of the text.
+	// It returns false if there are no matches, and an error if the query is
+	// not a valid regular expression.
+	FindPrevious() (bool, error)
+
+	// SelectAllMatches selects every match of the find query, returning the
+	// number of matches, and an error if the query is not a valid regular
+	// expression.
+	SelectAllMatches() (int, error)
+
+	// ReplaceMatch replaces the selected match of the find query with
+	// replacement and selects the next match. If no match is selected, the
+	// next match is selected instead and ReplaceMatch returns false. An
+	// error is returned if the query is not a valid regular expression.
+	ReplaceMatch(replacement string) (bool, error)
+
+	// ReplaceAllMatches replaces every match of the find query with
+	// replacement as a single undoable edit, returning the number of
+	// matches replaced, and an error if the query is not a valid regular
+	// expression.
+	ReplaceAllMatches(replacement string) (int, error)
+
+	ShowFindBar()
+	HideFindBar()
+	IsFindBarShowing() bool
+
+	// OnCompositionStart subscribes f to be called when an input method
+	// starts composing text in the text box.
+	OnCompositionStart(f func(CompositionEvent)) EventSubscription
+
+	// OnCompositionUpdate subscribes f to be called when the pre-edit text
+	// shown in the text box changes.
+	OnCompositionUpdate(f func(CompositionEvent)) EventSubscription
+
+	// OnCompositionEnd subscribes f to be called when the pre-edit text is
+	// committed to the text box, or the composition is cancelled.
+	OnCompositionEnd(f func(CompositionEvent)) EventSubscription
+
+	// IsComposing returns true while an input method composes text in the
+	// text box.
+	IsComposing() bool
 }
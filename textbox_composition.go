@@ -0,0 +1,116 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"github.com/vcaesar/guix/math"
+)
+
+// BeginComposition starts composing text with an input method at the last
+// caret. Selected text is deleted, and the other carets are removed. The
+// pre-edit text is held in the text, but is not recorded in the history until
+// it is committed with EndComposition. Moving the carets away from the
+// pre-edit text commits it as it is.
+func (t *TextBoxController) BeginComposition() {
+	if t.composing {
+		return
+	}
+	if t.SelectionCount() > 1 || t.FirstSelection().Length() > 0 {
+		t.ReplaceAll("")
+		t.SetCaret(t.LastCaret())
+	}
+	t.composing = true
+	t.compositionSelections = t.Selections()
+	t.compositionStart = t.LastCaret()
+	t.compositionEnd = t.compositionStart
+}
+
+// UpdateComposition replaces the pre-edit text with text, placing the caret
+// at the rune index cursor of text. A composition is started if none is in
+// progress.
+func (t *TextBoxController) UpdateComposition(text []rune, cursor int) {
+	t.BeginComposition()
+	s := t.compositionStart
+	edit := t.replaceComposition(text)
+	cursor = s + math.Clamp(cursor, 0, len(text))
+	t.selections = TextSelectionList{{cursor, cursor, false}}
+	t.onTextChanged.Fire([]TextBoxEdit{edit})
+	t.selectionChanged()
+}
+
+// EndComposition replaces the pre-edit text with the committed text, which is
+// passed through the edit filter and recorded in the history as typed text.
+// An empty or rejected commit cancels the composition.
+func (t *TextBoxController) EndComposition(commit []rune) {
+	if !t.composing {
+		if len(commit) > 0 {
+			t.ReplaceAllRunes(commit)
+			t.Deselect(false)
+		}
+		return
+	}
+	if len(commit) > 0 && t.editFilter != nil {
+		// The commit is filtered as if typed in place of the pre-edit text.
+		s, e := t.compositionStart, t.compositionEnd
+		text := append(append([]rune(nil), t.text[:s]...), t.text[e:]...)
+		filtered, ok := t.editFilter(text, s, s, commit)
+		if !ok {
+			filtered = nil
+		}
+		commit = filtered
+	}
+	// The selection spans the pre-edit text, so that it spans the committed
+	// text once updated for the edit.
+	t.selections = TextSelectionList{{t.compositionStart, t.compositionEnd, false}}
+	edit := t.replaceComposition(commit)
+	t.recordReplacement(t.compositionStart, nil, commit)
+	t.textEdited([]TextBoxEdit{edit})
+	t.Deselect(false)
+}
+
+// CancelComposition removes the pre-edit text, ending the composition.
+func (t *TextBoxController) CancelComposition() {
+	if t.composing {
+		t.EndComposition(nil)
+	}
+}
+
+// commitComposition commits the pre-edit text as it is, ending the
+// composition. It is called before the carets are moved, as the composition
+// is at the caret.
+func (t *TextBoxController) commitComposition() {
+	if t.composing {
+		selections, n := t.Selections(), len(t.text)
+		s, e := t.compositionStart, t.compositionEnd
+		t.EndComposition(append([]rune(nil), t.text[s:e]...))
+		if len(t.text) == n {
+			// The carets move from where they were in the pre-edit text.
+			t.selections = selections
+		}
+	}
+}
+
+// IsComposing returns true if text is being composed with an input method.
+func (t *TextBoxController) IsComposing() bool {
+	return t.composing
+}
+
+// Composition returns the range of runes holding the pre-edit text. The range
+// is empty if no composition is in progress.
+func (t *TextBoxController) Composition() (start, end int) {
+	if !t.composing {
+		return 0, 0
+	}
+	return t.compositionStart, t.compositionEnd
+}
+
+// replaceComposition replaces the pre-edit text with text, without recording
+// the change in the history.
+func (t *TextBoxController) replaceComposition(text []rune) TextBoxEdit {
+	runes, edit := t.ReplaceAt(t.text, t.compositionStart, t.compositionEnd, text)
+	t.setTextRunesNoEvent(runes)
+	t.compositionEnd = t.compositionStart + len(text)
+	return edit
+}
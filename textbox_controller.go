@@ -27,6 +27,18 @@ type TextBoxController struct {
 	locationHistory             [][]int
 	locationHistoryIndex        int
 	storeCaretLocationsNextEdit bool
+	history                     []textBoxChange
+	historyIndex                int // The number of changes that can be undone
+	historyDepth                int
+	replacements                []textBoxReplacement // The replacements of the edit in progress
+	historySelections           TextSelectionList    // The selections after the last change
+	caretsMoved                 bool                 // True if the carets moved since the last change
+	composing                   bool                 // True while an input method composes text
+	compositionStart            int                  // The start of the pre-edit text
+	compositionEnd              int                  // The end of the pre-edit text
+	compositionSelections       TextSelectionList    // The selections before the composition
+	editFilter                  TextBoxEditFilter
+	lineHidden                  func(line int) bool
 }
 
 func CreateTextBoxController() *TextBoxController {
@@ -35,14 +47,48 @@ func CreateTextBoxController() *TextBoxController {
 		onTextChanged:      CreateEvent(func([]TextBoxEdit) {}),
 	}
 	t.selections = TextSelectionList{TextSelection{}}
+	t.historyDepth = DefaultTextBoxHistoryDepth
+	t.historySelections = t.Selections()
 	return t
 }
 
 func (t *TextBoxController) textEdited(edits []TextBoxEdit) {
+	before := t.historySelections
+	if t.composing {
+		// Edits commit any pre-edit text, as part of the same change.
+		before = t.compositionSelections
+		if len(t.replacements) == 0 {
+			// The edit replaced nothing, so replaceAt did not record it.
+			s, e := t.compositionStart, t.compositionEnd
+			t.recordReplacement(s, nil, t.text[s:e])
+		}
+		t.composing = false
+	}
 	t.updateSelectionsForEdits(edits)
+	t.recordChange(before)
+	t.historySelections = t.Selections()
 	t.onTextChanged.Fire(edits)
 }
 
+// selectionChanged fires the selection changed event. Selections changed by
+// an edit, rather than by moving the carets, are restored by Redo.
+func (t *TextBoxController) selectionChanged() {
+	t.historySelections = t.Selections()
+	if !t.caretsMoved && !t.composing && t.historyIndex > 0 && t.historyIndex == len(t.history) {
+		t.history[t.historyIndex-1].after = t.historySelections
+	}
+	t.onSelectionChanged.Fire()
+}
+
+// moveCarets records that the carets were moved, so the location is stored
+// before the next edit, and the next edit is not merged with the previous
+// change in the history. Any pre-edit text is committed first.
+func (t *TextBoxController) moveCarets() {
+	t.commitComposition()
+	t.storeCaretLocationsNextEdit = true
+	t.caretsMoved = true
+}
+
 func (t *TextBoxController) updateSelectionsForEdits(edits []TextBoxEdit) {
 	min := 0
 	max := len(t.text)
@@ -220,33 +266,108 @@ func (t *TextBoxController) SetText(str string) {
 	t.SetTextRunes(StringToRuneArray(str))
 }
 
+// SetTextRunes replaces the text, clearing the history of changes.
 func (t *TextBoxController) SetTextRunes(text []rune) {
+	t.composing = false // The pre-edit text is replaced too
 	t.setTextRunesNoEvent(text)
+	t.ClearHistory()
 	t.textEdited([]TextBoxEdit{})
 }
 
+// SetTextEdits replaces the text with one that differs from it by the edits.
+// As the edits do not hold the replaced runes, the change cannot be undone
+// and the history is cleared.
 func (t *TextBoxController) SetTextEdits(text []rune, edits []TextBoxEdit) {
+	t.composing = false // The pre-edit text is replaced too
+	t.ClearHistory()
 	t.setTextRunesNoEvent(text)
 	t.textEdited(edits)
 }
 
+// SetLineHidden sets the function reporting whether a line is hidden, such as
+// by code folding. Carets moved by the Index functions skip the hidden lines.
+// A nil function shows every line.
+func (t *TextBoxController) SetLineHidden(f func(line int) bool) {
+	t.lineHidden = f
+}
+
+// IsLineHidden returns true if the line is hidden.
+func (t *TextBoxController) IsLineHidden(line int) bool {
+	return t.lineHidden != nil && t.lineHidden(line)
+}
+
+// previousVisibleLine returns the last line before l that is not hidden, or
+// -1 if there is none.
+func (t *TextBoxController) previousVisibleLine(l int) int {
+	l--
+	for l >= 0 && t.IsLineHidden(l) {
+		l--
+	}
+	return l
+}
+
+// nextVisibleLine returns the first line after l that is not hidden, or
+// LineCount if there is none.
+func (t *TextBoxController) nextVisibleLine(l int) int {
+	l++
+	for l < t.LineCount() && t.IsLineHidden(l) {
+		l++
+	}
+	return l
+}
+
+// visibleLeft returns i if it is on a visible line, or else the end of the
+// previous visible line.
+func (t *TextBoxController) visibleLeft(i int) int {
+	l := t.LineIndex(i)
+	if !t.IsLineHidden(l) {
+		return i
+	}
+	if p := t.previousVisibleLine(l); p >= 0 {
+		return t.LineEnd(p)
+	}
+	return t.visibleRight(i)
+}
+
+// visibleRight returns i if it is on a visible line, or else the start of the
+// next visible line.
+func (t *TextBoxController) visibleRight(i int) int {
+	l := t.LineIndex(i)
+	if !t.IsLineHidden(l) {
+		return i
+	}
+	if n := t.nextVisibleLine(l); n < t.LineCount() {
+		return t.LineStart(n)
+	}
+	if p := t.previousVisibleLine(l); p >= 0 {
+		return t.LineEnd(p)
+	}
+	return i
+}
+
 func (t *TextBoxController) IndexFirst(i int) int {
-	return 0
+	return t.visibleRight(0)
 }
 
 func (t *TextBoxController) IndexLast(i int) int {
-	return len(t.text)
+	return t.visibleLeft(len(t.text))
 }
 
+// IndexLeft returns the index of the start of the grapheme cluster before i.
 func (t *TextBoxController) IndexLeft(i int) int {
-	return math.Max(i-1, 0)
+	return t.visibleLeft(PrevGrapheme(t.text, i))
 }
 
+// IndexRight returns the index of the start of the grapheme cluster after i.
 func (t *TextBoxController) IndexRight(i int) int {
-	return math.Min(i+1, len(t.text))
+	return t.visibleRight(NextGrapheme(t.text, i))
 }
 
 func (t *TextBoxController) IndexWordLeft(i int) int {
+	return t.visibleLeft(t.indexWordLeft(i))
+}
+
+func (t *TextBoxController) indexWordLeft(i int) int {
 	i--
 	if i >= 0 {
 		wasInWord := t.RuneInWord(t.text[i])
@@ -263,6 +384,10 @@ func (t *TextBoxController) IndexWordLeft(i int) int {
 }
 
 func (t *TextBoxController) IndexWordRight(i int) int {
+	return t.visibleRight(t.indexWordRight(i))
+}
+
+func (t *TextBoxController) indexWordRight(i int) int {
 	if i < len(t.text) {
 		wasInWord := t.RuneInWord(t.text[i])
 		for i < len(t.text)-1 {
@@ -280,18 +405,18 @@ func (t *TextBoxController) IndexWordRight(i int) int {
 func (t *TextBoxController) IndexUp(i int) int {
 	l := t.LineIndex(i)
 	x := i - t.LineStart(l)
-	if l > 0 {
-		return math.Min(t.LineStart(l-1)+x, t.LineEnd(l-1))
+	if p := t.previousVisibleLine(l); p >= 0 {
+		return math.Min(t.LineStart(p)+x, t.LineEnd(p))
 	} else {
-		return 0
+		return t.IndexFirst(i)
 	}
 }
 
 func (t *TextBoxController) IndexDown(i int) int {
 	l := t.LineIndex(i)
 	x := i - t.LineStart(l)
-	if l < t.LineCount()-1 {
-		return math.Min(t.LineStart(l+1)+x, t.LineEnd(l+1))
+	if n := t.nextVisibleLine(l); n < t.LineCount() {
+		return math.Min(t.LineStart(n)+x, t.LineEnd(n))
 	} else {
 		return t.LineEnd(l)
 	}
@@ -316,49 +441,50 @@ func (t *TextBoxController) IndexEnd(i int) int {
 type SelectionTransform func(int) int
 
 func (t *TextBoxController) ClearSelections() {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.SetCaret(t.Caret(0))
 }
 
 func (t *TextBoxController) SetCaret(c int) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.selections = TextSelectionList{}
 	t.AddCaret(c)
 }
 
 func (t *TextBoxController) AddCaret(c int) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.AddSelection(TextSelection{c, c, false})
 }
 
 func (t *TextBoxController) AddSelection(s TextSelection) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	interval.Merge(&t.selections, s)
-	t.onSelectionChanged.Fire()
+	t.selectionChanged()
 }
 
 func (t *TextBoxController) SetSelection(s TextSelection) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.selections = []TextSelection{s}
-	t.onSelectionChanged.Fire()
+	t.selectionChanged()
 }
 
 func (t *TextBoxController) SetSelections(s TextSelectionList) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.selections = s
 	if len(s) == 0 {
 		t.AddCaret(0)
 	} else {
-		t.onSelectionChanged.Fire()
+		t.selectionChanged()
 	}
 }
 
 func (t *TextBoxController) SelectAll() {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.SetSelection(TextSelection{0, len(t.text), false})
 }
 
 func (t *TextBoxController) RestorePreviousSelections() {
+	t.commitComposition()
 	if t.locationHistoryIndex == len(t.locationHistory) {
 		t.StoreCaretLocations()
 		t.locationHistoryIndex--
@@ -370,11 +496,13 @@ func (t *TextBoxController) RestorePreviousSelections() {
 		for i, l := range locations {
 			t.selections[i] = TextSelection{l, l, false}
 		}
-		t.onSelectionChanged.Fire()
+		t.caretsMoved = true
+		t.selectionChanged()
 	}
 }
 
 func (t *TextBoxController) RestoreNextSelections() {
+	t.commitComposition()
 	if t.locationHistoryIndex < len(t.locationHistory)-1 {
 		t.locationHistoryIndex++
 		locations := t.locationHistory[t.locationHistoryIndex]
@@ -382,29 +510,30 @@ func (t *TextBoxController) RestoreNextSelections() {
 		for i, l := range locations {
 			t.selections[i] = TextSelection{l, l, false}
 		}
-		t.onSelectionChanged.Fire()
+		t.caretsMoved = true
+		t.selectionChanged()
 	}
 }
 
 func (t *TextBoxController) AddCarets(transform SelectionTransform) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	up := t.selections.Transform(0, transform)
 	for _, s := range up {
 		interval.Merge(&t.selections, s)
 	}
-	t.onSelectionChanged.Fire()
+	t.selectionChanged()
 }
 
 func (t *TextBoxController) GrowSelections(transform SelectionTransform) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.selections = t.selections.TransformCarets(0, transform)
-	t.onSelectionChanged.Fire()
+	t.selectionChanged()
 }
 
 func (t *TextBoxController) MoveSelections(transform SelectionTransform) {
-	t.storeCaretLocationsNextEdit = true
+	t.moveCarets()
 	t.selections = t.selections.Transform(0, transform)
-	t.onSelectionChanged.Fire()
+	t.selectionChanged()
 }
 
 func (t *TextBoxController) AddCaretsUp()       { t.AddCarets(t.IndexUp) }
@@ -431,45 +560,25 @@ func (t *TextBoxController) MoveHome()          { t.MoveSelections(t.IndexHome)
 func (t *TextBoxController) MoveEnd()           { t.MoveSelections(t.IndexEnd) }
 
 func (t *TextBoxController) Delete() {
-	t.maybeStoreCaretLocations()
-	text := t.text
-	edits := []TextBoxEdit{}
-	for i := len(t.selections) - 1; i >= 0; i-- {
-		s := t.selections[i]
-		if s.start == s.end && s.end < len(t.text) {
-			copy(text[s.start:], text[s.start+1:])
-			text = text[:len(text)-1]
-			edits = append(edits, TextBoxEdit{s.start, -1})
-		} else {
-			copy(text[s.start:], text[s.end:])
-			l := s.Length()
-			text = text[:len(text)-l]
-			edits = append(edits, TextBoxEdit{s.start, -l})
+	ranges := make(TextSelectionList, len(t.selections))
+	for i, s := range t.selections {
+		if s.start == s.end {
+			s.end = NextGrapheme(t.text, s.end)
 		}
-		t.selections[i] = TextSelection{s.end, s.end, false}
+		ranges[i] = TextSelection{s.start, s.end, false}
 	}
-	t.SetTextEdits(text, edits)
+	t.replaceRanges(ranges, func(TextSelection) []rune { return nil })
 }
 
 func (t *TextBoxController) Backspace() {
-	t.maybeStoreCaretLocations()
-	text := t.text
-	edits := []TextBoxEdit{}
-	for i := len(t.selections) - 1; i >= 0; i-- {
-		s := t.selections[i]
+	ranges := make(TextSelectionList, len(t.selections))
+	for i, s := range t.selections {
 		if s.start == s.end && s.start > 0 {
-			copy(text[s.start-1:], text[s.start:])
-			text = text[:len(text)-1]
-			edits = append(edits, TextBoxEdit{s.start - 1, -1})
-		} else {
-			copy(text[s.start:], text[s.end:])
-			l := s.Length()
-			text = text[:len(text)-l]
-			edits = append(edits, TextBoxEdit{s.start - 1, -l})
+			s.start--
 		}
-		t.selections[i] = TextSelection{s.end, s.end, false}
+		ranges[i] = TextSelection{s.start, s.end, false}
 	}
-	t.SetTextEdits(text, edits)
+	t.replaceRanges(ranges, func(TextSelection) []rune { return nil })
 }
 
 func (t *TextBoxController) ReplaceAll(str string) {
@@ -485,15 +594,34 @@ func (t *TextBoxController) Replace(f func(sel TextSelection) string) {
 }
 
 func (t *TextBoxController) ReplaceRunes(f func(sel TextSelection) []rune) {
-	t.maybeStoreCaretLocations()
+	t.replaceRanges(t.selections, f)
+}
+
+// replaceRanges replaces each of the ordered ranges with the runes returned by
+// f, leaving the ranges selected. The replacements are passed through the
+// edit filter, and if it rejects any of them the text and the selections are
+// left unchanged and false is returned.
+func (t *TextBoxController) replaceRanges(ranges TextSelectionList, f func(sel TextSelection) []rune) bool {
 	text, edit, edits := t.text, TextBoxEdit{}, []TextBoxEdit{}
-	for i := len(t.selections) - 1; i >= 0; i-- {
-		s := t.selections[i]
-		text, edit = t.ReplaceAt(text, s.start, s.end, f(s))
+	if t.editFilter != nil {
+		// The filter may reject a later replacement, so edit a copy.
+		text = append([]rune(nil), t.text...)
+	}
+	for i := len(ranges) - 1; i >= 0; i-- {
+		s := ranges[i]
+		replacement, ok := t.filterEdit(text, s.start, s.end, f(s))
+		if !ok {
+			t.replacements = nil
+			return false
+		}
+		text, edit = t.replaceAt(text, s.start, s.end, replacement)
 		edits = append(edits, edit)
 	}
+	t.maybeStoreCaretLocations()
+	t.selections = ranges
 	t.setTextRunesNoEvent(text)
 	t.textEdited(edits)
+	return true
 }
 
 func (t *TextBoxController) ReplaceAt(text []rune, s, e int, replacement []rune) ([]rune, TextBoxEdit) {
@@ -539,12 +667,13 @@ func (t *TextBoxController) IndentSelection(tabWidth int) {
 		}
 		for l := lie; l >= lis; l-- {
 			ls := t.LineStart(l)
-			text, edit = t.ReplaceAt(text, ls, ls, tab)
+			text, edit = t.replaceAt(text, ls, ls, tab)
 			edits = append(edits, edit)
 		}
 		lastLine = lis
 	}
-	t.SetTextEdits(text, edits)
+	t.setTextRunesNoEvent(text)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) UnindentSelection(tabWidth int) {
@@ -560,16 +689,21 @@ func (t *TextBoxController) UnindentSelection(tabWidth int) {
 			c := math.Min(t.LineIndent(l), tabWidth)
 			if c > 0 {
 				ls := t.LineStart(l)
-				text, edit = t.ReplaceAt(text, ls, ls+c, []rune{})
+				text, edit = t.replaceAt(text, ls, ls+c, []rune{})
 				edits = append(edits, edit)
 			}
 		}
 		lastLine = lis
 	}
-	t.SetTextEdits(text, edits)
+	t.setTextRunesNoEvent(text)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) RuneInWord(r rune) bool {
+	return runeInWord(r)
+}
+
+func runeInWord(r rune) bool {
 	switch {
 	case unicode.IsLetter(r), unicode.IsNumber(r), r == '_':
 		return true
@@ -605,7 +739,7 @@ func (t *TextBoxController) Deselect(moveCaretToStart bool) (deselected bool) {
 		t.selections[i] = s
 	}
 	if deselected {
-		t.onSelectionChanged.Fire()
+		t.selectionChanged()
 	}
 	return
 }
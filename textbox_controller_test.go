@@ -165,6 +165,18 @@ func TestTBCDeleteAtEnd(t *testing.T) {
 	assertTBCTextAndSelectionsEqual(t, "ħęľĺő|ŵōřŀď|", c)
 }
 
+func TestTBCDeleteCombiningMark(t *testing.T) {
+	c := parseTBC("a|e\u0301b")
+	c.Delete()
+	assertTBCTextAndSelectionsEqual(t, "a|b", c)
+}
+
+func TestTBCDeleteZWJSequence(t *testing.T) {
+	c := parseTBC("a|\U0001F469\u200D\U0001F4BBb|\U0001F44D\U0001F3FD")
+	c.Delete()
+	assertTBCTextAndSelectionsEqual(t, "a|b|", c)
+}
+
 func TestTBCDeleteSelection(t *testing.T) {
 	c := parseTBC("ħ[ęľ}ĺ{ő]\n[ŵ}{ō]řŀď|")
 	c.Delete()
@@ -276,3 +288,338 @@ func TestTBCUnindentSelection(t *testing.T) {
 	c.UnindentSelection(2)
 	assertTBCTextAndSelectionsEqual(t, "a{aa\n  b]bb|bb\n    [cc}\nddd\ne{e][e}e\n", c)
 }
+
+func TestTBCIndexGraphemes(t *testing.T) {
+	tbc := CreateTextBoxController()
+	tbc.SetText("ae\u0301b")
+	test.AssertEquals(t, 1, tbc.IndexRight(0))
+	test.AssertEquals(t, 3, tbc.IndexRight(1))
+	test.AssertEquals(t, 4, tbc.IndexRight(4))
+	test.AssertEquals(t, 1, tbc.IndexLeft(3))
+	test.AssertEquals(t, 0, tbc.IndexLeft(1))
+	test.AssertEquals(t, 0, tbc.IndexLeft(0))
+}
+
+// typeTBC types the runes of s into c, as TextBox does for key strokes.
+func typeTBC(c *TextBoxController, s string) {
+	for _, r := range s {
+		c.ReplaceAllRunes([]rune{r})
+		c.Deselect(false)
+	}
+}
+
+func TestTBCUndoTyping(t *testing.T) {
+	c := parseTBC("ab|")
+	test.AssertEquals(t, false, c.CanUndo())
+	typeTBC(c, "cd ef")
+	assertTBCTextAndSelectionsEqual(t, "abcd ef|", c)
+
+	// Typing is undone a word at a time.
+	test.AssertEquals(t, true, c.Undo())
+	assertTBCTextAndSelectionsEqual(t, "abcd |", c)
+	test.AssertEquals(t, true, c.Undo())
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+	test.AssertEquals(t, false, c.CanUndo())
+	test.AssertEquals(t, false, c.Undo())
+
+	test.AssertEquals(t, true, c.Redo())
+	assertTBCTextAndSelectionsEqual(t, "abcd |", c)
+	test.AssertEquals(t, true, c.Redo())
+	assertTBCTextAndSelectionsEqual(t, "abcd ef|", c)
+	test.AssertEquals(t, false, c.CanRedo())
+}
+
+func TestTBCUndoMovedCarets(t *testing.T) {
+	c := parseTBC("ab|")
+	typeTBC(c, "c")
+	c.MoveLeft()
+	typeTBC(c, "d")
+	assertTBCTextAndSelectionsEqual(t, "abd|c", c)
+
+	// Moving the caret ends the group of typing.
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|c", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+}
+
+func TestTBCUndoRestoresSelections(t *testing.T) {
+	c := parseTBC("a{bc]d e|f")
+	c.Delete()
+	assertTBCTextAndSelectionsEqual(t, "a|d e|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a{bc]d e|f", c)
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "a|d e|", c)
+}
+
+func TestTBCUndoDiscardsRedo(t *testing.T) {
+	c := parseTBC("a|")
+	typeTBC(c, "b")
+	c.Undo()
+	c.Backspace()
+	assertTBCTextAndSelectionsEqual(t, "|", c)
+	test.AssertEquals(t, false, c.CanRedo())
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|", c)
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCUndoMultiCaretTyping(t *testing.T) {
+	c := parseTBC("|one\ntwo\nthree|")
+	typeTBC(c, "ab")
+	assertTBCTextAndSelectionsEqual(t, "ab|one\ntwo\nthreeab|", c)
+
+	// Each keystroke records only the typed runes, and typing at every caret
+	// is grouped.
+	test.AssertEquals(t, 1, len(c.history))
+	for _, r := range c.history[0].replacements {
+		test.AssertEquals(t, 0, len(r.removed))
+		test.AssertEquals(t, 1, len(r.inserted))
+	}
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "|one\ntwo\nthree|", c)
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "ab|one\ntwo\nthreeab|", c)
+}
+
+func TestTBCUndoIndent(t *testing.T) {
+	c := parseTBC("a|\nb|")
+	c.IndentSelection(2)
+	test.AssertEquals(t, "  a\n  b", c.Text())
+	c.UnindentSelection(1)
+	test.AssertEquals(t, " a\n b", c.Text())
+	c.Undo()
+	test.AssertEquals(t, "  a\n  b", c.Text())
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|\nb|", c)
+}
+
+func TestTBCSetTextEditsClearsHistory(t *testing.T) {
+	c := parseTBC("|")
+	typeTBC(c, "a")
+	c.SetTextEdits([]rune("ab"), []TextBoxEdit{{1, 1}})
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCHistoryDepth(t *testing.T) {
+	c := parseTBC("|")
+	c.SetHistoryDepth(2)
+	for i := 0; i < 3; i++ {
+		typeTBC(c, "\n")
+	}
+	test.AssertEquals(t, true, c.Undo())
+	test.AssertEquals(t, true, c.Undo())
+	test.AssertEquals(t, false, c.Undo())
+	test.AssertEquals(t, "\n", c.Text())
+
+	c.SetHistoryDepth(0)
+	typeTBC(c, "a")
+	test.AssertEquals(t, false, c.CanUndo())
+	test.AssertEquals(t, false, c.CanRedo())
+}
+
+func TestTBCSetTextClearsHistory(t *testing.T) {
+	c := parseTBC("|")
+	typeTBC(c, "a")
+	c.SetText("b")
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+// assertFound asserts that a find returned the result want without error.
+func assertFound(t *testing.T, want, got interface{}, err error) {
+	test.AssertEquals(t, nil, err)
+	test.AssertEquals(t, want, got)
+}
+
+func TestTBCSelectNextPreviousMatch(t *testing.T) {
+	c := parseTBC("ab ab |ab")
+	q := FindQuery{Text: "ab"}
+	found, err := c.SelectNextMatch(q, c.FirstCaret())
+	assertFound(t, true, found, err)
+	test.AssertEquals(t, TextSelectionList{{6, 8, false}}, c.Selections())
+	found, err = c.SelectNextMatch(q, 7)
+	assertFound(t, true, found, err)
+	test.AssertEquals(t, TextSelectionList{{0, 2, false}}, c.Selections())
+	found, err = c.SelectPreviousMatch(q, 0)
+	assertFound(t, true, found, err)
+	test.AssertEquals(t, TextSelectionList{{6, 8, false}}, c.Selections())
+	found, err = c.SelectPreviousMatch(q, 6)
+	assertFound(t, true, found, err)
+	test.AssertEquals(t, TextSelectionList{{3, 5, false}}, c.Selections())
+	test.AssertEquals(t, true, c.IsMatchSelected(q))
+	found, err = c.SelectNextMatch(FindQuery{Text: "x"}, 0)
+	assertFound(t, false, found, err)
+	test.AssertEquals(t, TextSelectionList{{3, 5, false}}, c.Selections())
+}
+
+func TestTBCSelectAllMatches(t *testing.T) {
+	c := parseTBC("|a.b a.b axb")
+	n, err := c.SelectAllMatches(FindQuery{Text: "a.b"})
+	assertFound(t, 2, n, err)
+	test.AssertEquals(t, TextSelectionList{{0, 3, false}, {4, 7, false}}, c.Selections())
+	typeTBC(c, "c")
+	assertTBCTextAndSelectionsEqual(t, "c| c| axb", c)
+}
+
+func TestTBCReplaceMatch(t *testing.T) {
+	c := parseTBC("|one two one")
+	q := FindQuery{Text: "one"}
+	replaced, err := c.ReplaceMatch(q, "1")
+	assertFound(t, false, replaced, err)
+	c.SelectNextMatch(q, 0)
+	replaced, err = c.ReplaceMatch(q, "1")
+	assertFound(t, true, replaced, err)
+	test.AssertEquals(t, "1 two one", c.Text())
+	test.AssertEquals(t, TextSelectionList{{6, 9, false}}, c.Selections())
+	c.Undo()
+	test.AssertEquals(t, "one two one", c.Text())
+}
+
+func TestTBCReplaceAllMatchesUndo(t *testing.T) {
+	c := parseTBC("x=1, y=22|")
+	typeTBC(c, ";")
+	q := FindQuery{Text: `(\w)=(\d+)`, Regexp: true}
+	n, err := c.ReplaceAllMatches(q, "$2=$1")
+	assertFound(t, 2, n, err)
+	assertTBCTextAndSelectionsEqual(t, "1=x|, 22=y|;", c)
+
+	// The replacements are undone as a single change, restoring the caret.
+	test.AssertEquals(t, true, c.Undo())
+	assertTBCTextAndSelectionsEqual(t, "x=1, y=22;|", c)
+	test.AssertEquals(t, true, c.Redo())
+	assertTBCTextAndSelectionsEqual(t, "1=x|, 22=y|;", c)
+	n, err = c.ReplaceAllMatches(FindQuery{Text: "("}, "")
+	assertFound(t, 0, n, err)
+}
+
+func TestTBCFindInvalidRegexp(t *testing.T) {
+	c := parseTBC("|(ab)")
+	q := FindQuery{Text: "(a", Regexp: true}
+	_, err := c.FindMatches(q)
+	test.AssertEquals(t, true, err != nil)
+	found, err := c.SelectNextMatch(q, 0)
+	test.AssertEquals(t, false, found)
+	test.AssertEquals(t, true, err != nil)
+	found, err = c.SelectPreviousMatch(q, 0)
+	test.AssertEquals(t, false, found)
+	test.AssertEquals(t, true, err != nil)
+	n, err := c.SelectAllMatches(q)
+	test.AssertEquals(t, 0, n)
+	test.AssertEquals(t, true, err != nil)
+	test.AssertEquals(t, false, c.IsMatchSelected(q))
+	replaced, err := c.ReplaceMatch(q, "x")
+	test.AssertEquals(t, false, replaced)
+	test.AssertEquals(t, true, err != nil)
+	n, err = c.ReplaceAllMatches(q, "x")
+	test.AssertEquals(t, 0, n)
+	test.AssertEquals(t, true, err != nil)
+	assertTBCTextAndSelectionsEqual(t, "|(ab)", c)
+}
+
+func TestTBCComposition(t *testing.T) {
+	c := parseTBC("a|b")
+	edits := 0
+	c.OnTextChanged(func([]TextBoxEdit) { edits++ })
+	c.UpdateComposition([]rune("n"), 1)
+	c.UpdateComposition([]rune("ni"), 1)
+	test.AssertEquals(t, true, c.IsComposing())
+	assertTBCTextAndSelectionsEqual(t, "an|ib", c)
+	s, e := c.Composition()
+	test.AssertEquals(t, 1, s)
+	test.AssertEquals(t, 3, e)
+	test.AssertEquals(t, 2, edits)
+
+	// The pre-edit text is not part of the history.
+	test.AssertEquals(t, false, c.CanUndo())
+
+	c.EndComposition([]rune("你"))
+	test.AssertEquals(t, false, c.IsComposing())
+	assertTBCTextAndSelectionsEqual(t, "a你|b", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|b", c)
+}
+
+func TestTBCCompositionCancel(t *testing.T) {
+	c := parseTBC("ab|c")
+	c.SelectLeft()
+
+	// Selected text is replaced by the composition.
+	c.UpdateComposition([]rune("ka"), 2)
+	assertTBCTextAndSelectionsEqual(t, "aka|c", c)
+	c.CancelComposition()
+	assertTBCTextAndSelectionsEqual(t, "a|c", c)
+	c.Undo()
+	test.AssertEquals(t, "abc", c.Text())
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCCompositionCommittedByEdit(t *testing.T) {
+	c := parseTBC("a|b|")
+	c.UpdateComposition([]rune("ka"), 1)
+	assertTBCTextAndSelectionsEqual(t, "abk|a", c)
+	c.ReplaceAll("!")
+	c.Deselect(false)
+	test.AssertEquals(t, false, c.IsComposing())
+	assertTBCTextAndSelectionsEqual(t, "abk!|a", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+}
+
+func TestTBCCompositionCommittedByEmptyEdit(t *testing.T) {
+	c := parseTBC("a|c")
+	c.UpdateComposition([]rune("x"), 1)
+	c.UnindentSelection(4)
+	test.AssertEquals(t, false, c.IsComposing())
+	assertTBCTextAndSelectionsEqual(t, "ax|c", c)
+	for c.Undo() {
+	}
+	assertTBCTextAndSelectionsEqual(t, "a|c", c)
+}
+
+func TestTBCCompositionCommittedByCaretMove(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText("ab")
+	c.SetCaret(2)
+	c.UpdateComposition([]rune("xyz"), 3)
+	c.SetCaret(5)
+	test.AssertEquals(t, false, c.IsComposing())
+	c.EndComposition([]rune("Z"))
+	assertTBCTextAndSelectionsEqual(t, "abxyzZ|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "abxyz|", c)
+	c.ReplaceAllRunes([]rune("!"))
+	c.Undo()
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+}
+
+func TestTBCCompositionCaretMove(t *testing.T) {
+	c := parseTBC("a|b")
+	c.UpdateComposition([]rune("ka"), 1)
+	c.MoveLeft()
+	test.AssertEquals(t, false, c.IsComposing())
+	assertTBCTextAndSelectionsEqual(t, "a|kab", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|b", c)
+}
+
+func TestTBCHiddenLines(t *testing.T) {
+	c := parseTBC("A|A\nBB\nCC\nDD")
+	c.SetLineHidden(func(line int) bool { return line == 1 || line == 2 })
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "AA\nBB\nCC\nD|D", c)
+	c.MoveUp()
+	assertTBCTextAndSelectionsEqual(t, "A|A\nBB\nCC\nDD", c)
+	c.MoveEnd()
+	c.MoveRight()
+	assertTBCTextAndSelectionsEqual(t, "AA\nBB\nCC\n|DD", c)
+	c.MoveLeft()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC\nDD", c)
+
+	c.SetLineHidden(func(line int) bool { return line >= 1 })
+	c.MoveLast()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC\nDD", c)
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC\nDD", c)
+}
@@ -0,0 +1,102 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"regexp"
+)
+
+// TextBoxEditFilter filters an edit before it is applied to the text of a
+// TextBoxController. It is called with the text before the edit, the range
+// of runes s to e being replaced and the replacement, and returns the runes to
+// insert in place of the range. Returning false rejects the edit, leaving the
+// text unchanged.
+//
+// The text must not be modified by the filter.
+type TextBoxEditFilter func(text []rune, s, e int, replacement []rune) ([]rune, bool)
+
+// ChainEditFilters returns an edit filter that applies each of the filters in
+// turn, passing the replacement returned by one filter to the next.
+func ChainEditFilters(filters ...TextBoxEditFilter) TextBoxEditFilter {
+	return func(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+		for _, f := range filters {
+			if f == nil {
+				continue
+			}
+			var ok bool
+			if replacement, ok = f(text, s, e, replacement); !ok {
+				return nil, false
+			}
+		}
+		return replacement, true
+	}
+}
+
+// RuneFilter returns an edit filter that drops the inserted runes for which
+// accept returns false. For example RuneFilter(unicode.IsDigit) only accepts
+// digits.
+func RuneFilter(accept func(rune) bool) TextBoxEditFilter {
+	return func(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+		filtered := make([]rune, 0, len(replacement))
+		for _, r := range replacement {
+			if accept(r) {
+				filtered = append(filtered, r)
+			}
+		}
+		return filtered, len(filtered) > 0 || len(replacement) == 0
+	}
+}
+
+// MaxLengthFilter returns an edit filter that truncates inserted text so that
+// the text does not exceed max runes.
+func MaxLengthFilter(max int) TextBoxEditFilter {
+	return func(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+		room := max - (len(text) - (e - s))
+		if room < len(replacement) {
+			if room <= 0 && s == e {
+				return nil, false
+			}
+			if room < 0 {
+				room = 0
+			}
+			replacement = replacement[:room]
+		}
+		return replacement, true
+	}
+}
+
+// RegexpFilter returns an edit filter that rejects edits that would leave
+// text not matched by re. As the text is filtered while it is typed, re
+// should match every prefix of the text it accepts, such as `^\d{0,3}$`.
+func RegexpFilter(re *regexp.Regexp) TextBoxEditFilter {
+	return func(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+		edited := make([]rune, 0, len(text)-(e-s)+len(replacement))
+		edited = append(edited, text[:s]...)
+		edited = append(edited, replacement...)
+		edited = append(edited, text[e:]...)
+		return replacement, re.MatchString(string(edited))
+	}
+}
+
+// EditFilter returns the filter applied to edits of the text, or nil if the
+// edits are not filtered.
+func (t *TextBoxController) EditFilter() TextBoxEditFilter {
+	return t.editFilter
+}
+
+// SetEditFilter sets the filter applied to edits of the text, which include
+// typed, pasted, deleted and committed text. Edits made by SetText, Undo and
+// Redo are not filtered.
+func (t *TextBoxController) SetEditFilter(f TextBoxEditFilter) {
+	t.editFilter = f
+}
+
+// filterEdit passes an edit through the edit filter, if one is set.
+func (t *TextBoxController) filterEdit(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+	if t.editFilter == nil {
+		return replacement, true
+	}
+	return t.editFilter(text, s, e, replacement)
+}
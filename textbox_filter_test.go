@@ -0,0 +1,112 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	test "github.com/vcaesar/guix/testing"
+	"regexp"
+	"testing"
+	"unicode"
+)
+
+// typeText replaces the selections with each rune of s in turn, as typed.
+func typeText(c *TextBoxController, s string) {
+	for _, r := range s {
+		c.ReplaceAllRunes([]rune{r})
+		c.Deselect(false)
+	}
+}
+
+func TestTBCRuneFilter(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetEditFilter(RuneFilter(unicode.IsDigit))
+	typeText(c, "1a2")
+	test.AssertEquals(t, "12", c.Text())
+	test.AssertEquals(t, []int{2}, c.Carets())
+	c.ReplaceAll("3x4")
+	c.Deselect(false)
+	test.AssertEquals(t, "1234", c.Text())
+	c.Backspace()
+	test.AssertEquals(t, "123", c.Text())
+}
+
+func TestTBCMaxLengthFilter(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetEditFilter(MaxLengthFilter(4))
+	typeText(c, "abcdef")
+	test.AssertEquals(t, "abcd", c.Text())
+	c.SetCaret(0)
+	c.ReplaceAll("xyz")
+	test.AssertEquals(t, "abcd", c.Text())
+	c.SetSelection(CreateTextSelection(1, 3, false))
+	c.ReplaceAll("123")
+	test.AssertEquals(t, "a12d", c.Text())
+}
+
+func TestTBCRegexpFilter(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetEditFilter(RegexpFilter(regexp.MustCompile(`^-?\d*$`)))
+	typeText(c, "-1-2")
+	test.AssertEquals(t, "-12", c.Text())
+	c.SetCaret(1)
+	c.Backspace()
+	test.AssertEquals(t, "12", c.Text())
+}
+
+func TestTBCRejectedEditUnchanged(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText("ab")
+	c.SetSelections(TextSelectionList{{0, 0, false}, {2, 2, false}})
+	c.SetEditFilter(func(text []rune, s, e int, r []rune) ([]rune, bool) {
+		return r, s != 0
+	})
+	c.ReplaceAll("x")
+	test.AssertEquals(t, "ab", c.Text())
+	test.AssertEquals(t, []int{0, 2}, c.Carets())
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCTextMaskFilter(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetEditFilter(TextMask("99/99/9999").Filter())
+	typeText(c, "12a052020")
+	test.AssertEquals(t, "12/05/2020", c.Text())
+	typeText(c, "1")
+	test.AssertEquals(t, "12/05/2020", c.Text())
+
+	// Only the end of the text can be deleted.
+	c.SetCaret(3)
+	c.Backspace()
+	test.AssertEquals(t, "12/05/2020", c.Text())
+	c.SetCaret(10)
+	c.Backspace()
+	c.Backspace()
+	test.AssertEquals(t, "12/05/20", c.Text())
+
+	// Text inside the mask can be overwritten.
+	c.SetSelection(CreateTextSelection(0, 2, false))
+	c.ReplaceAll("31")
+	test.AssertEquals(t, "31/05/20", c.Text())
+	c.SetSelection(CreateTextSelection(0, 2, false))
+	c.ReplaceAll("3")
+	test.AssertEquals(t, "31/05/20", c.Text())
+
+	// Pasted text can hold the literals.
+	c.SetText("")
+	c.ReplaceAll("01/02/2003")
+	test.AssertEquals(t, "01/02/2003", c.Text())
+}
+
+func TestTBCCompositionFiltered(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetEditFilter(RuneFilter(unicode.IsDigit))
+	c.UpdateComposition([]rune("1a"), 2)
+	c.EndComposition([]rune("1a"))
+	test.AssertEquals(t, "1", c.Text())
+	c.UpdateComposition([]rune("b"), 1)
+	c.EndComposition([]rune("b"))
+	test.AssertEquals(t, "1", c.Text())
+	test.AssertEquals(t, []int{1}, c.Carets())
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+// FindMatches returns the matches of q in the text, in order. An error is
+// returned if the query is not a valid regular expression.
+func (t *TextBoxController) FindMatches(q FindQuery) ([]FindMatch, error) {
+	return q.Find(t.text)
+}
+
+// SelectNextMatch selects the first match of q that starts at or after the
+// index from, wrapping around to the start of the text. It returns false if
+// there are no matches, and an error if the query is not a valid regular
+// expression.
+func (t *TextBoxController) SelectNextMatch(q FindQuery, from int) (bool, error) {
+	matches, err := q.Find(t.text)
+	if len(matches) == 0 {
+		return false, err
+	}
+	m := matches[0]
+	for _, n := range matches {
+		if n.Start >= from {
+			m = n
+			break
+		}
+	}
+	t.SetSelection(m.Selection())
+	return true, nil
+}
+
+// SelectPreviousMatch selects the last match of q that starts before the
+// index from, wrapping around to the end of the text. It returns false if
+// there are no matches, and an error if the query is not a valid regular
+// expression.
+func (t *TextBoxController) SelectPreviousMatch(q FindQuery, from int) (bool, error) {
+	matches, err := q.Find(t.text)
+	if len(matches) == 0 {
+		return false, err
+	}
+	m := matches[len(matches)-1]
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i].Start < from {
+			m = matches[i]
+			break
+		}
+	}
+	t.SetSelection(m.Selection())
+	return true, nil
+}
+
+// SelectAllMatches selects every match of q, so that each can be edited with
+// its own caret. It returns the number of matches, leaving the selections
+// unchanged if there are none, and an error if the query is not a valid
+// regular expression.
+func (t *TextBoxController) SelectAllMatches(q FindQuery) (int, error) {
+	matches, err := q.Find(t.text)
+	if len(matches) == 0 {
+		return 0, err
+	}
+	sel := make(TextSelectionList, len(matches))
+	for i, m := range matches {
+		sel[i] = m.Selection()
+	}
+	t.SetSelections(sel)
+	return len(matches), nil
+}
+
+// selectedMatch returns the match of q spanned by the first selection.
+func (t *TextBoxController) selectedMatch(q FindQuery) (FindMatch, bool, error) {
+	matches, err := q.Find(t.text)
+	sel := t.FirstSelection()
+	for _, m := range matches {
+		if m.Start == sel.start && m.End == sel.end {
+			return m, true, nil
+		}
+	}
+	return FindMatch{}, false, err
+}
+
+// IsMatchSelected returns true if the first selection spans a match of q. It
+// returns false if the query is not a valid regular expression.
+func (t *TextBoxController) IsMatchSelected(q FindQuery) bool {
+	_, found, _ := t.selectedMatch(q)
+	return found
+}
+
+// ReplaceMatch replaces the first selection with replacement if it spans a
+// match of q, and then selects the next match. It returns true if a match
+// was replaced, and an error if the query is not a valid regular expression.
+func (t *TextBoxController) ReplaceMatch(q FindQuery, replacement string) (bool, error) {
+	m, found, err := t.selectedMatch(q)
+	if !found {
+		return false, err
+	}
+	t.replaceMatches([]FindMatch{m}, replacement)
+	t.SelectNextMatch(q, t.FirstCaret())
+	return true, nil
+}
+
+// ReplaceAllMatches replaces every match of q with replacement in a single
+// edit, which is undone as one change. It returns the number of matches
+// replaced, and an error if the query is not a valid regular expression.
+func (t *TextBoxController) ReplaceAllMatches(q FindQuery, replacement string) (int, error) {
+	matches, err := q.Find(t.text)
+	if len(matches) > 0 {
+		t.replaceMatches(matches, replacement)
+	}
+	return len(matches), err
+}
+
+// replaceMatches replaces the matches with replacement in a single edit,
+// leaving a caret after each replacement.
+func (t *TextBoxController) replaceMatches(matches []FindMatch, replacement string) {
+	t.moveCarets()
+	replacements := make(map[int][]rune, len(matches))
+	t.selections = make(TextSelectionList, len(matches))
+	for i, m := range matches {
+		t.selections[i] = m.Selection()
+		replacements[m.Start] = m.Replacement(replacement)
+	}
+	t.ReplaceRunes(func(sel TextSelection) []rune { return replacements[sel.start] })
+	t.Deselect(false)
+}
@@ -0,0 +1,227 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"unicode"
+)
+
+// DefaultTextBoxHistoryDepth is the number of changes that a
+// TextBoxController can undo by default.
+const DefaultTextBoxHistoryDepth = 100
+
+// textBoxReplacement is a replacement of the runes removed at the index at
+// with the runes inserted.
+type textBoxReplacement struct {
+	at       int
+	removed  []rune
+	inserted []rune
+}
+
+// textBoxChange is an entry of the edit history of a TextBoxController. It
+// holds the replacements of one or more consecutive edits, in the order they
+// were applied to the text.
+type textBoxChange struct {
+	replacements []textBoxReplacement
+	ends         []int             // The caret indices after typing, or nil if not typing
+	before       TextSelectionList // The selections before the change
+	after        TextSelectionList // The selections after the change
+}
+
+// isTyping returns true if the replacements only insert runes on a single
+// line at each caret, as typing does. Replacements of multiple carets are
+// applied from the last caret to the first.
+func isTyping(rs []textBoxReplacement) bool {
+	for i, r := range rs {
+		if len(r.removed) > 0 || len(r.inserted) == 0 || i > 0 && r.at >= rs[i-1].at {
+			return false
+		}
+		for _, c := range r.inserted {
+			if c == '\n' {
+				return false
+			}
+		}
+	}
+	return len(rs) > 0
+}
+
+// insertionEnds returns the indices of the ends of the runes inserted by the
+// typing replacements, in the text after all of them are applied.
+func insertionEnds(rs []textBoxReplacement) []int {
+	ends, shift := make([]int, len(rs)), 0
+	for i := len(rs) - 1; i >= 0; i-- {
+		shift += len(rs[i].inserted)
+		ends[i] = rs[i].at + shift
+	}
+	return ends
+}
+
+// merge appends the change n to c if both are typing, n continues where c
+// ended at every caret and n does not start a new word. It returns true if n
+// was merged.
+func (c *textBoxChange) merge(n textBoxChange) bool {
+	if c.ends == nil || n.ends == nil || len(n.replacements) != len(c.ends) {
+		return false
+	}
+	last := c.replacements[len(c.replacements)-len(c.ends):]
+	for i, r := range n.replacements {
+		if r.at != c.ends[i] {
+			return false
+		}
+		prev := last[i].inserted[len(last[i].inserted)-1]
+		if unicode.IsSpace(prev) && !unicode.IsSpace(r.inserted[0]) {
+			return false
+		}
+	}
+	c.replacements = append(c.replacements, n.replacements...)
+	c.ends = n.ends
+	c.after = n.after
+	return true
+}
+
+// replaceAt calls ReplaceAt, recording the replacement for the history.
+func (t *TextBoxController) replaceAt(text []rune, s, e int, replacement []rune) ([]rune, TextBoxEdit) {
+	if t.composing && len(t.replacements) == 0 {
+		// The edit commits the pre-edit text, which is not yet recorded.
+		t.recordReplacement(t.compositionStart, nil, t.text[t.compositionStart:t.compositionEnd])
+	}
+	t.recordReplacement(s, text[s:e], replacement)
+	return t.ReplaceAt(text, s, e, replacement)
+}
+
+// recordReplacement adds a replacement to those of the edit in progress,
+// which are recorded in the history by textEdited.
+func (t *TextBoxController) recordReplacement(at int, removed, inserted []rune) {
+	if len(removed) > 0 || len(inserted) > 0 {
+		t.replacements = append(t.replacements, textBoxReplacement{
+			at:       at,
+			removed:  append([]rune(nil), removed...),
+			inserted: append([]rune(nil), inserted...),
+		})
+	}
+}
+
+// recordChange adds the replacements of the edit to the history. before
+// holds the selections before the change.
+func (t *TextBoxController) recordChange(before TextSelectionList) {
+	c := textBoxChange{
+		replacements: t.replacements,
+		before:       before,
+		after:        t.Selections(),
+	}
+	t.replacements = nil
+	if len(c.replacements) == 0 || t.historyDepth == 0 {
+		return
+	}
+	if isTyping(c.replacements) {
+		c.ends = insertionEnds(c.replacements)
+	}
+
+	t.history = t.history[:t.historyIndex]
+	merged := false
+	if n := len(t.history); n > 0 && !t.caretsMoved {
+		merged = t.history[n-1].merge(c)
+	}
+	if !merged {
+		t.history = append(t.history, c)
+		if n := len(t.history); n > t.historyDepth {
+			t.history = append([]textBoxChange{}, t.history[n-t.historyDepth:]...)
+		}
+	}
+	t.historyIndex = len(t.history)
+	t.caretsMoved = false
+}
+
+// applyChange applies the replacements of c, or reverts them if undo is
+// true, and sets the selections, without recording the change in the
+// history.
+func (t *TextBoxController) applyChange(c textBoxChange, undo bool) {
+	text, edits := t.text, make([]TextBoxEdit, len(c.replacements))
+	for i := range c.replacements {
+		r, old, new := c.replacements[i], c.replacements[i].removed, c.replacements[i].inserted
+		if undo {
+			r = c.replacements[len(c.replacements)-1-i]
+			old, new = r.inserted, r.removed
+		}
+		text, edits[i] = t.ReplaceAt(text, r.at, r.at+len(old), new)
+	}
+	selections := c.after
+	if undo {
+		selections = c.before
+	}
+	t.setTextRunesNoEvent(text)
+	t.onTextChanged.Fire(edits)
+	t.moveCarets()
+	t.selections = append(TextSelectionList{}, selections...)
+	t.selectionChanged()
+}
+
+// CanUndo returns true if there is a change that Undo can revert.
+func (t *TextBoxController) CanUndo() bool {
+	return t.historyIndex > 0
+}
+
+// CanRedo returns true if there is a change that Redo can reapply.
+func (t *TextBoxController) CanRedo() bool {
+	return t.historyIndex < len(t.history)
+}
+
+// Undo reverts the last change to the text, restoring the selections from
+// before the change. Consecutive typing is undone as a single change. Undo
+// returns false if there is nothing to undo.
+func (t *TextBoxController) Undo() bool {
+	t.CancelComposition()
+	if !t.CanUndo() {
+		return false
+	}
+	t.historyIndex--
+	c := t.history[t.historyIndex]
+	t.applyChange(c, true)
+	return true
+}
+
+// Redo reapplies the last change reverted by Undo, restoring the selections
+// from after the change. Redo returns false if there is nothing to redo.
+func (t *TextBoxController) Redo() bool {
+	t.CancelComposition()
+	if !t.CanRedo() {
+		return false
+	}
+	c := t.history[t.historyIndex]
+	t.historyIndex++
+	t.applyChange(c, false)
+	return true
+}
+
+// HistoryDepth returns the maximum number of changes that can be undone.
+func (t *TextBoxController) HistoryDepth() int {
+	return t.historyDepth
+}
+
+// SetHistoryDepth sets the maximum number of changes that can be undone,
+// discarding the oldest changes beyond it. A depth of 0 disables the
+// history.
+func (t *TextBoxController) SetHistoryDepth(depth int) {
+	if depth < 0 {
+		panic("History depth cannot be negative")
+	}
+	t.historyDepth = depth
+	if n := t.historyIndex; n > depth {
+		t.history = append([]textBoxChange{}, t.history[n-depth:]...)
+		t.historyIndex = depth
+	}
+	if len(t.history) > depth {
+		t.history = t.history[:depth]
+	}
+}
+
+// ClearHistory discards all changes, so that they cannot be undone or
+// redone.
+func (t *TextBoxController) ClearHistory() {
+	t.history = nil
+	t.historyIndex = 0
+	t.replacements = nil
+	t.historySelections = t.Selections()
+}
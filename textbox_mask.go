@@ -0,0 +1,85 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// TextMask is a pattern for formatted text, such as "99/99/9999" for a date
+// or "(999) 999-9999" for a phone number. Each rune of the mask holds one rune
+// of the text:
+//
+//	9  a digit
+//	a  a letter
+//	*  any rune
+//
+// Any other rune of the mask is a literal, which is inserted automatically
+// when the text is typed.
+type TextMask string
+
+// textMaskAccepts returns true if r can be entered in the slot of a mask.
+func textMaskAccepts(slot, r rune) bool {
+	switch slot {
+	case '9':
+		return unicode.IsDigit(r)
+	case 'a':
+		return unicode.IsLetter(r)
+	case '*':
+		return true
+	}
+	return r == slot
+}
+
+func isTextMaskLiteral(r rune) bool {
+	return r != '9' && r != 'a' && r != '*'
+}
+
+// Filter returns an edit filter that only accepts text following the mask,
+// inserting the literals of the mask as the text is typed. To keep the text
+// aligned with the mask, text can only be deleted from the end, and text
+// inside the mask can only be overwritten with the same number of runes.
+func (m TextMask) Filter() TextBoxEditFilter {
+	mask := []rune(string(m))
+	return func(text []rune, s, e int, replacement []rune) ([]rune, bool) {
+		if len(replacement) == 0 {
+			return nil, e == len(text)
+		}
+		out := make([]rune, 0, len(replacement))
+		p := s
+		for _, r := range replacement {
+			for p < len(mask) && isTextMaskLiteral(mask[p]) && r != mask[p] {
+				out = append(out, mask[p])
+				p++
+			}
+			if p >= len(mask) || !textMaskAccepts(mask[p], r) {
+				return nil, false
+			}
+			out = append(out, r)
+			p++
+		}
+		if e < len(text) && len(out) != e-s {
+			return nil, false
+		}
+		return out, true
+	}
+}
+
+// Validate returns an error if text does not fill the mask. It can be used
+// as a TextBoxValidator.
+func (m TextMask) Validate(text string) error {
+	mask, runes := []rune(string(m)), []rune(text)
+	if len(runes) == len(mask) {
+		valid := true
+		for i, r := range runes {
+			valid = valid && textMaskAccepts(mask[i], r)
+		}
+		if valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected the format %s", string(m))
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+// TextBoxValidator validates the text of a TextBox, returning an error
+// describing why the text is invalid, or nil if the text is valid.
+type TextBoxValidator func(text string) error
+
+// ChainValidators returns a validator that returns the error of the first of
+// the validators that rejects the text.
+func ChainValidators(validators ...TextBoxValidator) TextBoxValidator {
+	return func(text string) error {
+		for _, v := range validators {
+			if v == nil {
+				continue
+			}
+			if err := v(text); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RequiredValidator rejects empty text.
+func RequiredValidator(text string) error {
+	if text == "" {
+		return errors.New("a value is required")
+	}
+	return nil
+}
+
+// LengthValidator returns a validator that rejects text shorter than min or
+// longer than max runes.
+func LengthValidator(min, max int) TextBoxValidator {
+	return func(text string) error {
+		switch n := len([]rune(text)); {
+		case n < min:
+			return fmt.Errorf("expected at least %d characters", min)
+		case n > max:
+			return fmt.Errorf("expected at most %d characters", max)
+		}
+		return nil
+	}
+}
+
+// NumberValidator returns a validator that rejects text that is not a number
+// between min and max inclusive.
+func NumberValidator(min, max float64) TextBoxValidator {
+	return func(text string) error {
+		f, err := strconv.ParseFloat(text, 64)
+		switch {
+		case err != nil:
+			return errors.New("expected a number")
+		case f < min || f > max:
+			return fmt.Errorf("expected a number between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// RegexpValidator returns a validator that rejects text not matched by re,
+// with an error holding message.
+func RegexpValidator(re *regexp.Regexp, message string) TextBoxValidator {
+	return func(text string) error {
+		if !re.MatchString(text) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+
+// IPAddressValidator rejects text that is not an IPv4 or IPv6 address.
+func IPAddressValidator(text string) error {
+	if net.ParseIP(text) == nil {
+		return errors.New("expected an IP address")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package guix
+
+import (
+	test "github.com/vcaesar/guix/testing"
+	"regexp"
+	"testing"
+)
+
+// valid returns the texts of texts accepted by v.
+func valid(v TextBoxValidator, texts ...string) []string {
+	accepted := []string{}
+	for _, text := range texts {
+		if v(text) == nil {
+			accepted = append(accepted, text)
+		}
+	}
+	return accepted
+}
+
+func TestValidators(t *testing.T) {
+	test.AssertEquals(t, []string{"a"}, valid(RequiredValidator, "", "a"))
+	test.AssertEquals(t, []string{"ab", "abc"}, valid(LengthValidator(2, 3), "a", "ab", "abc", "abcd"))
+	test.AssertEquals(t, []string{"0", "-1.5", "10"}, valid(NumberValidator(-2, 10), "0", "-1.5", "10", "11", "1a", ""))
+	test.AssertEquals(t, []string{"ab1"}, valid(RegexpValidator(regexp.MustCompile(`^[a-z]+\d$`), "bad"), "ab1", "1ab"))
+	test.AssertEquals(t, []string{"192.168.0.1", "::1"}, valid(IPAddressValidator, "192.168.0.1", "::1", "256.1.1.1", "1.2.3"))
+	test.AssertEquals(t, []string{"12/05/2020"}, valid(TextMask("99/99/9999").Validate, "12/05/2020", "12/05/20", "12-05-2020"))
+}
+
+func TestChainValidators(t *testing.T) {
+	v := ChainValidators(RequiredValidator, nil, NumberValidator(0, 1))
+	test.AssertEquals(t, "a value is required", v("").Error())
+	test.AssertEquals(t, "expected a number between 0 and 1", v("2").Error())
+	test.AssertEquals(t, nil, v("1"))
+}
@@ -10,16 +10,28 @@ type Theme interface {
 	SetDefaultFont(Font)
 	DefaultMonospaceFont() Font
 	SetDefaultMonospaceFont(Font)
+
+	// FontRegistry returns the registry holding the theme's fonts, which may
+	// be used to register additional families, weights and styles.
+	FontRegistry() *FontRegistry
+
+	// Font returns the variant of the default font, such as FontBold or
+	// FontHeading1, for use with Label.SetFont or TextBox.SetFont. If the
+	// variant cannot be created, the default font is returned.
+	Font(FontVariant) Font
+
 	CreateBubbleOverlay() BubbleOverlay
 	CreateButton() Button
 	CreateCodeEditor() CodeEditor
 	CreateDropDownList() DropDownList
+	CreateFindBar() FindBar
 	CreateImage() Image
 	CreateLabel() Label
 	CreateLinearLayout() LinearLayout
 	CreateList() List
 	CreatePanelHolder() PanelHolder
 	CreateProgressBar() ProgressBar
+	CreateRichLabel() RichLabel
 	CreateScrollBar() ScrollBar
 	CreateScrollLayout() ScrollLayout
 	CreateSplitterLayout() SplitterLayout
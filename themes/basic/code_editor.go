@@ -20,9 +20,14 @@ func CreateCodeEditor(theme *Theme) guix.CodeEditor {
 	t.theme = theme
 	t.Init(t, theme.Driver(), theme, theme.DefaultMonospaceFont())
 	t.SetTextColor(theme.TextBoxDefaultStyle.FontColor)
+	t.SetPlaceholderColor(theme.TextBoxPlaceholderStyle.FontColor)
 	t.SetMargin(math.Spacing{L: 3, T: 3, R: 3, B: 3})
 	t.SetPadding(math.Spacing{L: 3, T: 3, R: 3, B: 3})
 	t.SetBorderPen(guix.TransparentPen)
+	t.FindLayer().SetBackgroundColor(theme.FindMatchStyle.Brush.Color)
+	t.FindLayer().SetBorderColor(theme.FindMatchStyle.Pen.Color)
+	t.SetGutterBrush(theme.CodeGutterStyle.Brush)
+	t.SetLineNumberColors(theme.CodeGutterStyle.FontColor, theme.CodeGutterActiveStyle.FontColor)
 
 	return t
 }
@@ -41,5 +46,6 @@ func (t *CodeEditor) CreateSuggestionList() guix.List {
 	l := t.theme.CreateList()
 	l.SetBackgroundBrush(t.theme.CodeSuggestionListStyle.Brush)
 	l.SetBorderPen(t.theme.CodeSuggestionListStyle.Pen)
+	l.SetShadow(t.theme.CodeSuggestionListStyle.Shadow)
 	return l
 }
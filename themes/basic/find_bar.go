@@ -0,0 +1,22 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package basic
+
+import (
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/mixins"
+)
+
+func CreateFindBar(theme *Theme) guix.FindBar {
+	b := &mixins.FindBar{}
+	b.Init(b, theme)
+	b.SetMargin(math.Spacing{L: 3, T: 3, R: 3, B: 3})
+	b.SetPadding(math.Spacing{L: 2, T: 2, R: 2, B: 2})
+	b.SetBackgroundBrush(theme.FindBarStyle.Brush)
+	b.SetBorderPen(theme.FindBarStyle.Pen)
+	b.SetShadow(theme.FindBarStyle.Shadow)
+	return b
+}
@@ -12,6 +12,10 @@ type Style struct {
 	FontColor guix.Color
 	Brush     guix.Brush
 	Pen       guix.Pen
+
+	// Shadow is the drop shadow of controls that float above others, such as
+	// overlays and popup lists.
+	Shadow guix.Shadow
 }
 
 func CreateStyle(fontColor, brushColor, penColor guix.Color, penWidth float32) Style {
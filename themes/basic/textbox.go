@@ -19,6 +19,7 @@ func CreateTextBox(theme *Theme) guix.TextBox {
 	t := &TextBox{}
 	t.Init(t, theme.Driver(), theme, theme.DefaultFont())
 	t.SetTextColor(theme.TextBoxDefaultStyle.FontColor)
+	t.SetPlaceholderColor(theme.TextBoxPlaceholderStyle.FontColor)
 	t.SetMargin(math.Spacing{L: 3, T: 3, R: 3, B: 3})
 	t.SetPadding(math.Spacing{L: 3, T: 3, R: 3, B: 3})
 	t.SetBackgroundBrush(theme.TextBoxDefaultStyle.Brush)
@@ -32,6 +33,8 @@ func CreateTextBox(theme *Theme) guix.TextBox {
 		t.SetBorderPen(theme.TextBoxDefaultStyle.Pen)
 	})
 
+	t.OnValidationChanged(func(error) { t.Redraw() })
+
 	t.theme = theme
 
 	return t
@@ -41,6 +44,12 @@ func CreateTextBox(theme *Theme) guix.TextBox {
 func (t *TextBox) Paint(c guix.Canvas) {
 	t.TextBox.Paint(c)
 
+	if t.ValidationError() != nil {
+		r := t.Size().Rect().ContractI(1)
+		s := t.theme.TextBoxInvalidStyle
+		c.DrawRoundedRect(r, 3, 3, 3, 3, s.Pen, s.Brush)
+	}
+
 	if t.HasFocus() {
 		r := t.Size().Rect()
 		s := t.theme.FocusedStyle
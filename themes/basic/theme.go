@@ -12,6 +12,8 @@ type Theme struct {
 	DriverInfo               guix.Driver
 	DefaultFontInfo          guix.Font
 	DefaultMonospaceFontInfo guix.Font
+	FontRegistryInfo         *guix.FontRegistry
+	DefaultFontFamily        string
 
 	WindowBackground guix.Color
 
@@ -19,12 +21,17 @@ type Theme struct {
 	ButtonDefaultStyle        Style
 	ButtonOverStyle           Style
 	ButtonPressedStyle        Style
+	CodeGutterActiveStyle     Style
+	CodeGutterStyle           Style
 	CodeSuggestionListStyle   Style
 	DropDownListDefaultStyle  Style
 	DropDownListOverStyle     Style
+	FindBarStyle              Style
+	FindMatchStyle            Style
 	FocusedStyle              Style
 	HighlightStyle            Style
 	LabelStyle                Style
+	LinkStyle                 Style
 	PanelBackgroundStyle      Style
 	ScrollBarBarDefaultStyle  Style
 	ScrollBarBarOverStyle     Style
@@ -37,7 +44,9 @@ type Theme struct {
 	TabOverStyle              Style
 	TabPressedStyle           Style
 	TextBoxDefaultStyle       Style
+	TextBoxInvalidStyle       Style
 	TextBoxOverStyle          Style
+	TextBoxPlaceholderStyle   Style
 }
 
 // guix.Theme compliance
@@ -61,6 +70,22 @@ func (t *Theme) SetDefaultMonospaceFont(f guix.Font) {
 	t.DefaultMonospaceFontInfo = f
 }
 
+func (t *Theme) FontRegistry() *guix.FontRegistry {
+	return t.FontRegistryInfo
+}
+
+func (t *Theme) Font(v guix.FontVariant) guix.Font {
+	def := t.DefaultFont()
+	if t.FontRegistryInfo == nil || t.DefaultFontFamily == "" || def == nil {
+		return def
+	}
+	f, err := t.FontRegistryInfo.Font(v.Key(t.DefaultFontFamily, def.Size()))
+	if err != nil {
+		return def
+	}
+	return f
+}
+
 func (t *Theme) CreateBubbleOverlay() guix.BubbleOverlay {
 	return CreateBubbleOverlay(t)
 }
@@ -77,6 +102,10 @@ func (t *Theme) CreateDropDownList() guix.DropDownList {
 	return CreateDropDownList(t)
 }
 
+func (t *Theme) CreateFindBar() guix.FindBar {
+	return CreateFindBar(t)
+}
+
 func (t *Theme) CreateImage() guix.Image {
 	return CreateImage(t)
 }
@@ -101,6 +130,10 @@ func (t *Theme) CreateProgressBar() guix.ProgressBar {
 	return CreateProgressBar(t)
 }
 
+func (t *Theme) CreateRichLabel() guix.RichLabel {
+	return CreateRichLabel(t)
+}
+
 func (t *Theme) CreateScrollBar() guix.ScrollBar {
 	return CreateScrollBar(t)
 }
@@ -0,0 +1,746 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package basic_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/vcaesar/guix"
+	"github.com/vcaesar/guix/math"
+	"github.com/vcaesar/guix/mixins"
+	"github.com/vcaesar/guix/testing/guixtest"
+)
+
+// Glyph coverage may differ slightly between architectures due to floating
+// point contraction, so allow a small per-channel tolerance.
+const tolerance = 8
+
+func snapshot(t *testing.T, name string, create func(theme guix.Theme) guix.Control) {
+	guixtest.AssertSnapshot(t, name, guixtest.Options{
+		Size:      math.Size{W: 160, H: 80},
+		Tolerance: tolerance,
+	}, create)
+}
+
+func TestBubbleOverlay(t *testing.T) {
+	snapshot(t, "bubble_overlay", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLabel()
+		l.SetText("Bubble")
+		b := theme.CreateBubbleOverlay()
+		b.Show(l, math.Point{X: 80, Y: 4})
+		return b
+	})
+}
+
+func TestButton(t *testing.T) {
+	snapshot(t, "button", func(theme guix.Theme) guix.Control {
+		b := theme.CreateButton()
+		b.SetText("OK")
+		return b
+	})
+}
+
+func TestCodeEditor(t *testing.T) {
+	snapshot(t, "code_editor", func(theme guix.Theme) guix.Control {
+		e := theme.CreateCodeEditor()
+		e.SetText("func main() {\n\tprintln(1)\n}")
+		return e
+	})
+}
+
+func TestCodeEditorGutter(t *testing.T) {
+	snapshot(t, "code_editor_gutter", func(theme guix.Theme) guix.Control {
+		e := theme.CreateCodeEditor()
+		e.SetText("a := 1\nb := 2\nc := 3\nd := 4")
+		e.AddMarker(0, guix.BreakpointMarker)
+		e.AddMarker(1, guix.WarningMarker)
+		e.AddMarker(1, guix.DiffModifiedMarker)
+		e.AddMarker(2, guix.ErrorMarker)
+		e.AddMarker(2, guix.DiffAddedMarker)
+		e.AddMarker(3, guix.DiffRemovedMarker)
+		return e
+	})
+}
+
+func TestCodeEditorMarkers(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var e guix.CodeEditor
+		var breakpoint *guix.CodeMarker
+		clicked, gutterLines := 0, []int{}
+		r.Do(func() {
+			e = r.Theme.CreateCodeEditor()
+			e.SetText("one\ntwo\nthree")
+			breakpoint = e.AddMarker(1, guix.BreakpointMarker)
+			breakpoint.OnClick(func(guix.MouseEvent) { clicked++ })
+			e.OnGutterClicked(func(line int, _ guix.MouseEvent) { gutterLines = append(gutterLines, line) })
+			r.Window.AddChild(e)
+		})
+		line := func() (l int) {
+			r.Do(func() { l = e.LineIndex(breakpoint.RuneIndex()) })
+			return
+		}
+
+		// Inserting a line above moves the marker down.
+		r.SetFocus(e)
+		r.KeyPress(guix.KeyEnter, 0)
+		if got := line(); got != 2 {
+			t.Errorf("expected marker on line 2, got %d", got)
+		}
+		// Typing at the start of the marked line keeps the marker on it.
+		r.KeyPress(guix.KeyDown, 0)
+		r.KeyPress(guix.KeyDown, 0)
+		r.Type("x")
+		if got := line(); got != 2 {
+			t.Errorf("expected marker on line 2, got %d", got)
+		}
+		r.Do(func() {
+			if got := len(e.MarkersAt(2)); got != 1 {
+				t.Errorf("expected 1 marker on line 2, got %d", got)
+			}
+		})
+
+		// Click the marker icon, then the line number of the first line.
+		var marker, number math.Point
+		r.Do(func() {
+			gutter := guix.FindControl(e.(guix.Parent), func(c guix.Control) bool {
+				_, ok := c.(*mixins.CodeEditorGutter)
+				return ok
+			})
+			w, h := gutter.Size().W, gutter.Size().H
+			lt := e.Padding().LT()
+			marker = guix.ChildToParent(lt.Add(math.Point{X: h / 2, Y: 2*h + h/2}), e, r.Window)
+			number = guix.ChildToParent(lt.Add(math.Point{X: w - 8, Y: h / 2}), e, r.Window)
+		})
+		r.Click(marker)
+		r.Click(number)
+		if clicked != 1 {
+			t.Errorf("expected 1 marker click, got %d", clicked)
+		}
+		if len(gutterLines) != 2 || gutterLines[0] != 2 || gutterLines[1] != 0 {
+			t.Errorf("expected gutter clicks on lines [2 0], got %v", gutterLines)
+		}
+	})
+}
+
+func TestCodeEditorFoldingSnapshot(t *testing.T) {
+	snapshot(t, "code_editor_folding", func(theme guix.Theme) guix.Control {
+		e := theme.CreateCodeEditor()
+		e.SetText("a {\n  b\n}\nc {\n  d\n}")
+		e.SetDesiredWidth(140)
+		e.Fold(3)
+		return e
+	})
+}
+
+func TestCodeEditorFoldingEmptyLineSnapshot(t *testing.T) {
+	snapshot(t, "code_editor_folding_empty_line", func(theme guix.Theme) guix.Control {
+		e := theme.CreateCodeEditor()
+		e.SetText("a\n\n  b\nc")
+		e.SetFoldProvider(&countingFoldProvider{ranges: []guix.CodeFoldRange{{Start: 1, End: 2}}})
+		e.SetDesiredWidth(140)
+		e.Fold(1)
+		return e
+	})
+}
+
+func TestCodeEditorFolding(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var e guix.CodeEditor
+		const text = "a {\n  b\n  c\n}\nd"
+		r.Do(func() {
+			e = r.Theme.CreateCodeEditor()
+			e.SetText(text)
+			r.Window.AddChild(e)
+		})
+		r.Do(func() {
+			if !e.Fold(0) {
+				t.Errorf("expected line 0 to fold")
+			}
+			if e.Fold(1) {
+				t.Errorf("expected no fold range on line 1")
+			}
+			for l, hidden := range []bool{false, true, true, false, false} {
+				if got := e.IsLineHidden(l); got != hidden {
+					t.Errorf("line %d: expected hidden %v, got %v", l, hidden, got)
+				}
+			}
+			if e.Text() != text {
+				t.Errorf("folding changed the text to %q", e.Text())
+			}
+		})
+
+		// The caret skips the folded lines.
+		r.SetFocus(e)
+		r.KeyPress(guix.KeyDown, 0)
+		r.Do(func() {
+			if got := e.LineIndex(e.Carets()[0]); got != 3 {
+				t.Errorf("expected caret on line 3, got %d", got)
+			}
+		})
+
+		// Editing above the fold keeps it on its line.
+		r.KeyPress(guix.KeyUp, 0)
+		r.KeyPress(guix.KeyHome, 0)
+		r.KeyPress(guix.KeyEnter, 0)
+		r.Do(func() {
+			if e.IsFolded(0) || !e.IsFolded(1) || !e.IsLineHidden(3) {
+				t.Errorf("expected the fold to move to line 1")
+			}
+		})
+
+		// Placing a caret in a folded line unfolds it.
+		r.Do(func() {
+			e.Select(guix.TextSelectionList{guix.CreateTextSelection(e.LineStart(2), e.LineStart(2), false)})
+			if e.IsFolded(1) || e.IsLineHidden(2) {
+				t.Errorf("expected the caret to unfold line 1")
+			}
+		})
+
+		// Disabling folding unfolds every range.
+		r.Do(func() {
+			e.FoldAll()
+			if !e.IsFolded(1) {
+				t.Errorf("expected line 1 to be folded")
+			}
+			e.SetFoldingEnabled(false)
+			if e.IsFolded(1) || len(e.FoldRanges()) != 0 {
+				t.Errorf("expected no folds with folding disabled")
+			}
+		})
+	})
+}
+
+// countingFoldProvider returns fixed fold ranges, counting the requests.
+type countingFoldProvider struct {
+	ranges []guix.CodeFoldRange
+	calls  int
+}
+
+func (p *countingFoldProvider) FoldRanges() []guix.CodeFoldRange {
+	p.calls++
+	return p.ranges
+}
+
+func TestCodeEditorFoldRangesComputedLazily(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var e guix.CodeEditor
+		p := &countingFoldProvider{ranges: []guix.CodeFoldRange{{Start: 1, End: 2}}}
+		r.Do(func() {
+			e = r.Theme.CreateCodeEditor()
+			e.SetText("a\nb {\n  c\n}")
+			e.SetFoldProvider(p)
+			r.Window.AddChild(e)
+			e.Fold(1)
+			e.Select(guix.TextSelectionList{guix.CreateTextSelection(0, 0, false)})
+		})
+		r.Do(func() {
+			p.calls = 0
+			for _, c := range "x\ny" {
+				if c == '\n' {
+					e.KeyPress(guix.KeyboardEvent{Key: guix.KeyEnter})
+				} else {
+					e.KeyStroke(guix.KeyStrokeEvent{Character: c})
+				}
+			}
+			if p.calls != 0 {
+				t.Errorf("expected no fold range requests while editing, got %d", p.calls)
+			}
+			// The fold moves with the edits without the ranges being requested.
+			if !e.IsFolded(2) || !e.IsLineHidden(3) || e.IsLineHidden(4) {
+				t.Errorf("expected the fold to move to line 2")
+			}
+			e.FoldRanges()
+			e.FoldRanges()
+			if p.calls != 1 {
+				t.Errorf("expected 1 fold range request, got %d", p.calls)
+			}
+		})
+	})
+}
+
+func TestFindBar(t *testing.T) {
+	// The find bar is wider than the other snapshots.
+	guixtest.AssertSnapshot(t, "find_bar", guixtest.Options{
+		Size:      math.Size{W: 400, H: 80},
+		Tolerance: tolerance,
+	}, func(theme guix.Theme) guix.Control {
+		b := theme.CreateFindBar()
+		b.SetQuery(guix.FindQuery{Text: "one", MatchCase: true})
+		b.SetReplacement("two")
+		return b
+	})
+}
+
+func TestDropDownList(t *testing.T) {
+	snapshot(t, "drop_down_list", func(theme guix.Theme) guix.Control {
+		adapter := guix.CreateDefaultAdapter()
+		adapter.SetItems([]string{"One", "Two", "Three"})
+		d := theme.CreateDropDownList()
+		d.SetAdapter(adapter)
+		d.Select("Two")
+		return d
+	})
+}
+
+func TestImage(t *testing.T) {
+	snapshot(t, "image", func(theme guix.Theme) guix.Control {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				img.SetRGBA(x, y, color.RGBA{uint8(x * 16), uint8(y * 16), 128, 255})
+			}
+		}
+		i := theme.CreateImage()
+		i.SetTexture(theme.Driver().CreateTexture(img, 1))
+		return i
+	})
+}
+
+func TestLabel(t *testing.T) {
+	snapshot(t, "label", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLabel()
+		l.SetText("Hello world")
+		return l
+	})
+}
+
+func TestLabelWrap(t *testing.T) {
+	snapshot(t, "label_wrap", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLabel()
+		l.SetText("The quick brown fox jumps over the lazy dog")
+		l.SetMultiline(true)
+		l.SetWrap(guix.WrapWord)
+		return l
+	})
+}
+
+func TestLabelEllipsis(t *testing.T) {
+	snapshot(t, "label_ellipsis", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLabel()
+		l.SetText("The quick brown fox jumps over the lazy dog")
+		l.SetEllipsis(guix.EllipsisMiddle)
+		return l
+	})
+}
+
+func TestLinearLayout(t *testing.T) {
+	snapshot(t, "linear_layout", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLinearLayout()
+		l.SetDirection(guix.LeftToRight)
+		for _, s := range []string{"A", "B", "C"} {
+			b := theme.CreateButton()
+			b.SetText(s)
+			l.AddChild(b)
+		}
+		return l
+	})
+}
+
+func TestList(t *testing.T) {
+	snapshot(t, "list", func(theme guix.Theme) guix.Control {
+		adapter := guix.CreateDefaultAdapter()
+		adapter.SetItems([]string{"One", "Two", "Three"})
+		l := theme.CreateList()
+		l.SetAdapter(adapter)
+		l.Select("Two")
+		return l
+	})
+}
+
+func TestPanelHolder(t *testing.T) {
+	snapshot(t, "panel_holder", func(theme guix.Theme) guix.Control {
+		p := theme.CreatePanelHolder()
+		for _, s := range []string{"First", "Second"} {
+			l := theme.CreateLabel()
+			l.SetText(s)
+			p.AddPanel(l, s)
+		}
+		return p
+	})
+}
+
+func TestProgressBar(t *testing.T) {
+	snapshot(t, "progress_bar", func(theme guix.Theme) guix.Control {
+		p := theme.CreateProgressBar()
+		p.SetDesiredSize(math.Size{W: 120, H: 16})
+		p.SetTarget(100)
+		p.SetProgress(40)
+		return p
+	})
+}
+
+func TestRichLabel(t *testing.T) {
+	snapshot(t, "rich_label", func(theme guix.Theme) guix.Control {
+		red, code := guix.Red, guix.Gray30
+		s := guix.CreateAttributedString("Some ")
+		s.Append("bold", guix.TextAttributes{Font: theme.Font(guix.FontBold)})
+		s.Append(", ", guix.TextAttributes{})
+		s.Append("italic", guix.TextAttributes{Font: theme.Font(guix.FontItalic)})
+		s.Append(", red", guix.TextAttributes{Color: &red, Underline: true})
+		s.Append(" and ", guix.TextAttributes{Strikethrough: true})
+		s.Append("code()", guix.TextAttributes{Font: theme.DefaultMonospaceFont(), BackgroundColor: &code})
+		s.Append(" text with a ", guix.TextAttributes{})
+		s.Append("link", guix.TextAttributes{Link: "https://example.com"})
+		l := theme.CreateRichLabel()
+		l.SetText(s)
+		return l
+	})
+}
+
+func TestThemeFontVariants(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		r.Do(func() {
+			def := r.Theme.DefaultFont()
+			variants := []guix.Font{def}
+			for _, v := range []guix.FontVariant{guix.FontBold, guix.FontItalic, guix.FontBoldItalic} {
+				f := r.Theme.Font(v)
+				for _, o := range variants {
+					if f == o {
+						t.Errorf("Font(%v) is the same face as another variant", v)
+					}
+				}
+				if f.Size() != def.Size() {
+					t.Errorf("Font(%v): expected size %d, got %d", v, def.Size(), f.Size())
+				}
+				variants = append(variants, f)
+			}
+		})
+	})
+}
+
+func TestRichLabelLinkClicked(t *testing.T) {
+	clicked := ""
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var l guix.RichLabel
+		r.Do(func() {
+			s := guix.CreateAttributedString("")
+			s.Append("Click me", guix.TextAttributes{Link: "target"})
+			l = r.Theme.CreateRichLabel()
+			l.SetText(s)
+			l.OnLinkClicked(func(_ guix.MouseEvent, link string) { clicked = link })
+			r.Window.AddChild(l)
+		})
+		r.Click(r.Center(l))
+	})
+	if clicked != "target" {
+		t.Errorf("Expected the link to be clicked, got %q", clicked)
+	}
+}
+
+func TestScrollBar(t *testing.T) {
+	snapshot(t, "scroll_bar", func(theme guix.Theme) guix.Control {
+		s := theme.CreateScrollBar()
+		s.SetOrientation(guix.Horizontal)
+		s.SetScrollLimit(100)
+		s.SetScrollPosition(20, 60)
+		return s
+	})
+}
+
+func TestScrollLayout(t *testing.T) {
+	snapshot(t, "scroll_layout", func(theme guix.Theme) guix.Control {
+		l := theme.CreateLinearLayout()
+		for _, s := range []string{"One", "Two", "Three", "Four", "Five"} {
+			b := theme.CreateButton()
+			b.SetText(s)
+			l.AddChild(b)
+		}
+		s := theme.CreateScrollLayout()
+		s.SetChild(l)
+		return s
+	})
+}
+
+func TestSplitterLayout(t *testing.T) {
+	snapshot(t, "splitter_layout", func(theme guix.Theme) guix.Control {
+		s := theme.CreateSplitterLayout()
+		s.SetOrientation(guix.Horizontal)
+		for _, t := range []string{"Left", "Right"} {
+			l := theme.CreateLabel()
+			l.SetText(t)
+			s.AddChild(l)
+		}
+		return s
+	})
+}
+
+func TestTableLayout(t *testing.T) {
+	snapshot(t, "table_layout", func(theme guix.Theme) guix.Control {
+		l := theme.CreateTableLayout()
+		l.SetGrid(2, 2)
+		for i, s := range []string{"1", "2", "3", "4"} {
+			b := theme.CreateButton()
+			b.SetText(s)
+			l.SetChildAt(i%2, i/2, 1, 1, b)
+		}
+		return l
+	})
+}
+
+// testTreeNode is a guix.TreeNode identified by its name.
+type testTreeNode struct {
+	name     string
+	children []*testTreeNode
+}
+
+func (n *testTreeNode) Count() int                 { return len(n.children) }
+func (n *testTreeNode) NodeAt(i int) guix.TreeNode { return n.children[i] }
+func (n *testTreeNode) Item() guix.AdapterItem     { return n.name }
+func (n *testTreeNode) ItemIndex(item guix.AdapterItem) int {
+	for i, c := range n.children {
+		if c.name == item || c.ItemIndex(item) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+func (n *testTreeNode) Create(theme guix.Theme) guix.Control {
+	l := theme.CreateLabel()
+	l.SetText(n.name)
+	return l
+}
+
+// testTreeAdapter is a guix.TreeAdapter of the children of its root node.
+type testTreeAdapter struct {
+	guix.AdapterBase
+	testTreeNode
+}
+
+func (a *testTreeAdapter) Size(guix.Theme) math.Size {
+	return math.Size{W: math.MaxSize.W, H: 18}
+}
+
+func TestTree(t *testing.T) {
+	snapshot(t, "tree", func(theme guix.Theme) guix.Control {
+		leaf := func(name string) *testTreeNode { return &testTreeNode{name: name} }
+		a := &testTreeAdapter{testTreeNode: testTreeNode{children: []*testTreeNode{
+			{name: "Fruit", children: []*testTreeNode{leaf("Apple"), leaf("Pear")}},
+			{name: "Vegetables", children: []*testTreeNode{leaf("Leek")}},
+		}}}
+		tree := theme.CreateTree()
+		tree.SetAdapter(a)
+		tree.Show("Pear")
+		tree.Select("Apple")
+		return tree
+	})
+}
+
+func TestTextBox(t *testing.T) {
+	snapshot(t, "textbox", func(theme guix.Theme) guix.Control {
+		b := theme.CreateTextBox()
+		b.SetText("Some text")
+		return b
+	})
+}
+
+func TestTextBoxPlaceholder(t *testing.T) {
+	snapshot(t, "textbox_placeholder", func(theme guix.Theme) guix.Control {
+		b := theme.CreateTextBox()
+		b.SetPlaceholder("Search")
+		return b
+	})
+}
+
+func TestTextBoxReadOnly(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var b guix.TextBox
+		r.Do(func() {
+			b = r.Theme.CreateTextBox()
+			b.SetText("fixed")
+			b.SetReadOnly(true)
+			r.Window.AddChild(b)
+		})
+		r.SetFocus(b)
+		r.KeyPress(guix.KeyA, guix.ModControl)
+		r.Type("typed")
+		r.KeyPress(guix.KeyBackspace, 0)
+		r.KeyPress(guix.KeyX, guix.ModControl)
+		r.KeyPress(guix.KeyV, guix.ModControl)
+		r.Do(func() {
+			if got := b.Text(); got != "fixed" {
+				t.Errorf("expected text %q, got %q", "fixed", got)
+			}
+		})
+
+		r.KeyPress(guix.KeyC, guix.ModControl)
+		if got, _ := r.Driver.GetClipboard(); got != "fixed" {
+			t.Errorf("expected clipboard %q, got %q", "fixed", got)
+		}
+	})
+}
+
+func TestTextBoxPassword(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var b guix.TextBox
+		r.Do(func() {
+			b = r.Theme.CreateTextBox()
+			b.SetPasswordChar('*')
+			r.Window.AddChild(b)
+			r.Driver.SetClipboard("clipboard")
+		})
+		r.SetFocus(b)
+		r.Type("secret")
+		r.KeyPress(guix.KeyA, guix.ModControl)
+		r.KeyPress(guix.KeyC, guix.ModControl)
+		r.KeyPress(guix.KeyX, guix.ModControl)
+		r.Do(func() {
+			if got := b.Text(); got != "secret" {
+				t.Errorf("expected text %q, got %q", "secret", got)
+			}
+		})
+		if got, _ := r.Driver.GetClipboard(); got != "clipboard" {
+			t.Errorf("expected clipboard %q, got %q", "clipboard", got)
+		}
+	})
+}
+
+func TestTextBoxValidation(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var b guix.TextBox
+		changes := []error{}
+		r.Do(func() {
+			mask := guix.TextMask("99/99/9999")
+			b = r.Theme.CreateTextBox()
+			b.SetEditFilter(mask.Filter())
+			b.SetValidator(mask.Validate)
+			b.OnValidationChanged(func(err error) { changes = append(changes, err) })
+			r.Window.AddChild(b)
+		})
+		r.SetFocus(b)
+		r.Type("1x2")
+		r.Do(func() {
+			if got := b.Text(); got != "12" {
+				t.Errorf("expected text %q, got %q", "12", got)
+			}
+			if b.ValidationError() == nil {
+				t.Errorf("expected a validation error")
+			}
+		})
+		r.Type("052020")
+		r.Do(func() {
+			if err := b.ValidationError(); err != nil {
+				t.Errorf("expected no validation error, got %v", err)
+			}
+			b.SetMaxLength(2)
+			b.SetEditFilter(nil)
+			b.SetText("")
+		})
+		r.Type("abc")
+		r.Do(func() {
+			if got := b.Text(); got != "ab" {
+				t.Errorf("expected text %q, got %q", "ab", got)
+			}
+			if len(changes) != 2 || changes[0] != nil || changes[1] == nil {
+				t.Errorf("unexpected validation changes %v", changes)
+			}
+		})
+	})
+}
+
+func TestTextBoxUndo(t *testing.T) {
+	for _, name := range []string{"TextBox", "CodeEditor"} {
+		guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+			var b guix.TextBox
+			r.Do(func() {
+				if name == "TextBox" {
+					b = r.Theme.CreateTextBox()
+				} else {
+					b = r.Theme.CreateCodeEditor()
+				}
+				r.Window.AddChild(b)
+			})
+			r.SetFocus(b)
+			r.Type("one two")
+			expect := func(expected string) {
+				var got string
+				r.Do(func() { got = b.Text() })
+				if got != expected {
+					t.Errorf("%s: expected text %q, got %q", name, expected, got)
+				}
+			}
+
+			r.KeyPress(guix.KeyZ, guix.ModControl)
+			expect("one ")
+			r.KeyPress(guix.KeyZ, guix.ModControl)
+			expect("")
+			r.KeyPress(guix.KeyZ, guix.ModControl|guix.ModShift)
+			expect("one ")
+			r.Type("!")
+			expect("one !")
+		})
+	}
+}
+
+func TestCodeEditorFind(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var e guix.CodeEditor
+		r.Do(func() {
+			e = r.Theme.CreateCodeEditor()
+			r.Window.AddChild(e)
+		})
+		r.SetFocus(e)
+		r.Type("one two one")
+		expect := func(what string, expected, got interface{}) {
+			if expected != got {
+				t.Errorf("%s: expected %v, got %v", what, expected, got)
+			}
+		}
+		carets := func() (c int) {
+			r.Do(func() { c = e.Carets()[0] })
+			return
+		}
+
+		r.KeyPress(guix.KeyF, guix.ModControl)
+		r.Do(func() { expect("find bar showing", true, e.IsFindBarShowing()) })
+		r.Type("one")
+		expect("caret after incremental find", 3, carets())
+		r.Do(func() { expect("highlighted matches", 2, len(e.FindLayer().Spans())) })
+		r.KeyPress(guix.KeyEnter, 0)
+		expect("caret after next", 11, carets())
+		r.KeyPress(guix.KeyEnter, guix.ModShift)
+		expect("caret after previous", 3, carets())
+
+		r.KeyPress(guix.KeyEscape, 0)
+		r.Do(func() {
+			expect("find bar showing", false, e.IsFindBarShowing())
+			expect("highlighted matches", 0, len(e.FindLayer().Spans()))
+			n, err := e.ReplaceAllMatches("1")
+			expect("replaced", 2, n)
+			expect("replace error", nil, err)
+			expect("text", "1 two 1", e.Text())
+		})
+		r.KeyPress(guix.KeyZ, guix.ModControl)
+		r.Do(func() { expect("text", "one two one", e.Text()) })
+	})
+}
+
+func TestFindBarInvalidRegexp(t *testing.T) {
+	guixtest.Run(guixtest.Options{}, func(r *guixtest.Robot) {
+		var b guix.FindBar
+		r.Do(func() {
+			target := r.Theme.CreateTextBox()
+			target.SetText("(a)")
+			b = r.Theme.CreateFindBar()
+			b.SetTarget(target)
+			b.SetQuery(guix.FindQuery{Regexp: true})
+			r.Window.AddChild(b)
+			b.FocusQuery()
+		})
+		r.Type("(a")
+		r.Do(func() {
+			if b.QueryError() == nil {
+				t.Errorf("expected an error for the query %q", b.Query().Text)
+			}
+		})
+		r.Type(")")
+		r.Do(func() {
+			if err := b.QueryError(); err != nil {
+				t.Errorf("expected no error for the query %q, got %v", b.Query().Text, err)
+			}
+		})
+	})
+}
@@ -9,18 +9,26 @@ import (
 
 	"github.com/vcaesar/guix"
 	"github.com/vcaesar/guix/gxfont"
+	"github.com/vcaesar/guix/math"
 	"github.com/vcaesar/guix/themes/basic"
 )
 
 func CreateTheme(driver guix.Driver) guix.Theme {
-	defaultFont, err := driver.CreateFont(gxfont.Default, 12)
+	fonts := guix.CreateFontRegistry(driver)
+	fonts.Register(gxfont.DefaultFamily, guix.FontWeightNormal, guix.FontStyleNormal, gxfont.Default)
+	fonts.Register(gxfont.DefaultFamily, guix.FontWeightBold, guix.FontStyleNormal, gxfont.Bold)
+	fonts.Register(gxfont.DefaultFamily, guix.FontWeightNormal, guix.FontStyleItalic, gxfont.Italic)
+	fonts.Register(gxfont.DefaultFamily, guix.FontWeightBold, guix.FontStyleItalic, gxfont.BoldItalic)
+	fonts.Register(gxfont.MonospaceFamily, guix.FontWeightNormal, guix.FontStyleNormal, gxfont.Monospace)
+
+	defaultFont, err := fonts.Font(guix.FontKey{Family: gxfont.DefaultFamily, Weight: guix.FontWeightNormal, Size: 12})
 	if err == nil {
 		defaultFont.LoadGlyphs(32, 126)
 	} else {
 		fmt.Printf("Warning: Failed to load default font - %v\n", err)
 	}
 
-	defaultMonospaceFont, err := driver.CreateFont(gxfont.Monospace, 12)
+	defaultMonospaceFont, err := fonts.Font(guix.FontKey{Family: gxfont.MonospaceFamily, Weight: guix.FontWeightNormal, Size: 12})
 	if err == nil {
 		defaultFont.LoadGlyphs(32, 126)
 	} else {
@@ -36,10 +44,21 @@ func CreateTheme(driver guix.Driver) guix.Theme {
 	neonBlue := guix.ColorFromHex(0xFF5C8CFF)
 	focus := guix.ColorFromHex(0xA0C4D6FF)
 
-	return &basic.Theme{
+	invalid := guix.ColorFromHex(0xFFE05050)
+
+	findMatch := neonBlue
+	findMatch.A = 0.35
+
+	shadowColor := guix.Black
+	shadowColor.A = 0.6
+	popupShadow := guix.CreateShadow(math.Vec2{Y: 2}, 8, 0, shadowColor)
+
+	t := &basic.Theme{
 		DriverInfo:               driver,
 		DefaultFontInfo:          defaultFont,
 		DefaultMonospaceFontInfo: defaultMonospaceFont,
+		FontRegistryInfo:         fonts,
+		DefaultFontFamily:        gxfont.DefaultFamily,
 		WindowBackground:         guix.Black,
 
 		//                                   fontColor    brushColor   penColor
@@ -47,12 +66,17 @@ func CreateTheme(driver guix.Driver) guix.Theme {
 		ButtonDefaultStyle:        basic.CreateStyle(guix.Gray80, guix.Gray10, guix.Gray20, 1.0),
 		ButtonOverStyle:           basic.CreateStyle(guix.Gray90, guix.Gray15, guix.Gray50, 1.0),
 		ButtonPressedStyle:        basic.CreateStyle(guix.Gray20, guix.Gray70, guix.Gray30, 1.0),
+		CodeGutterActiveStyle:     basic.CreateStyle(guix.Gray90, guix.Transparent, guix.Transparent, 0.0),
+		CodeGutterStyle:           basic.CreateStyle(guix.Gray50, guix.Transparent, guix.Transparent, 0.0),
 		CodeSuggestionListStyle:   basic.CreateStyle(guix.Gray80, guix.Gray20, guix.Gray10, 1.0),
 		DropDownListDefaultStyle:  basic.CreateStyle(guix.Gray80, guix.Gray10, guix.Gray20, 1.0),
 		DropDownListOverStyle:     basic.CreateStyle(guix.Gray80, guix.Gray15, guix.Gray50, 1.0),
+		FindBarStyle:              basic.CreateStyle(guix.Gray80, guix.Gray20, guix.Gray10, 1.0),
+		FindMatchStyle:            basic.CreateStyle(guix.Gray80, findMatch, neonBlue, 1.0),
 		FocusedStyle:              basic.CreateStyle(guix.Gray80, guix.Transparent, focus, 1.0),
 		HighlightStyle:            basic.CreateStyle(guix.Gray80, guix.Transparent, neonBlue, 2.0),
 		LabelStyle:                basic.CreateStyle(guix.Gray80, guix.Transparent, guix.Transparent, 0.0),
+		LinkStyle:                 basic.CreateStyle(neonBlue, guix.Transparent, guix.Transparent, 0.0),
 		PanelBackgroundStyle:      basic.CreateStyle(guix.Gray80, guix.Gray10, guix.Gray15, 1.0),
 		ScrollBarBarDefaultStyle:  basic.CreateStyle(guix.Gray80, guix.Gray30, guix.Gray40, 1.0),
 		ScrollBarBarOverStyle:     basic.CreateStyle(guix.Gray80, guix.Gray50, guix.Gray60, 1.0),
@@ -65,6 +89,12 @@ func CreateTheme(driver guix.Driver) guix.Theme {
 		TabOverStyle:              basic.CreateStyle(guix.Gray90, guix.Gray30, guix.Gray50, 1.0),
 		TabPressedStyle:           basic.CreateStyle(guix.Gray20, guix.Gray70, guix.Gray30, 1.0),
 		TextBoxDefaultStyle:       basic.CreateStyle(guix.Gray80, guix.Gray10, guix.Gray20, 1.0),
+		TextBoxInvalidStyle:       basic.CreateStyle(invalid, guix.Transparent, invalid, 1.0),
 		TextBoxOverStyle:          basic.CreateStyle(guix.Gray80, guix.Gray10, guix.Gray50, 1.0),
+		TextBoxPlaceholderStyle:   basic.CreateStyle(guix.Gray50, guix.Transparent, guix.Transparent, 0.0),
 	}
+	t.BubbleOverlayStyle.Shadow = popupShadow
+	t.CodeSuggestionListStyle.Shadow = popupShadow
+	t.FindBarStyle.Shadow = popupShadow
+	return t
 }
@@ -42,7 +42,7 @@ func ValidateHierarchy(p Parent) {
 	for _, c := range p.Children() {
 		if p != c.Control.Parent() {
 			panic(fmt.Errorf("Child's parent is not as expected.\nChild: %s\nExpected parent: %s",
-				Path(c.Control), Path(p)))
+				ControlPath(c.Control), ControlPath(p)))
 		}
 		if cp, ok := c.Control.(Parent); ok {
 			ValidateHierarchy(cp)
@@ -136,7 +136,7 @@ func ChildToParent(coord math.Point, from Control, to Parent) math.Point {
 	for {
 		p := c.Parent()
 		if p == nil {
-			panic(fmt.Errorf("Control detached: %s", Path(c)))
+			panic(fmt.Errorf("Control detached: %s", ControlPath(c)))
 		}
 		child := p.Children().Find(c)
 		if child == nil {
@@ -169,7 +169,7 @@ func TransformCoordinate(coord math.Point, from, to Control) math.Point {
 
 	ancestor := CommonAncestor(from, to)
 	if ancestor == nil {
-		panic(fmt.Errorf("No common ancestor between %s and %s", Path(from), Path(to)))
+		panic(fmt.Errorf("No common ancestor between %s and %s", ControlPath(from), ControlPath(to)))
 	}
 
 	if parent, ok := ancestor.(Control); !ok || parent != from {
@@ -106,4 +106,21 @@ type Viewport interface {
 	// OnKeyStroke subscribes f to be called whenever a keyboard key-stroke event
 	// is raised while the viewport has focus.
 	OnKeyStroke(f func(KeyStrokeEvent)) EventSubscription
+
+	// OnCompositionStart subscribes f to be called whenever an input method
+	// starts composing text while the viewport has focus.
+	OnCompositionStart(f func(CompositionEvent)) EventSubscription
+
+	// OnCompositionUpdate subscribes f to be called whenever the pre-edit text
+	// of an input method changes.
+	OnCompositionUpdate(f func(CompositionEvent)) EventSubscription
+
+	// OnCompositionEnd subscribes f to be called whenever an input method
+	// commits or cancels the text it was composing.
+	OnCompositionEnd(f func(CompositionEvent)) EventSubscription
+
+	// SetCompositionRect sets the bounds of the pre-edit text in DIPs, relative
+	// to the top-left of the viewport. The input method's candidate window is
+	// anchored to it.
+	SetCompositionRect(math.Rect)
 }
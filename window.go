@@ -72,6 +72,10 @@ type Window interface {
 	KeyPress(KeyboardEvent)
 	KeyStroke(KeyStrokeEvent)
 
+	// SetCompositionRect sets the bounds of the pre-edit text of an input
+	// method, relative to the window, to anchor its candidate window.
+	SetCompositionRect(math.Rect)
+
 	// Events
 	OnClose(func()) EventSubscription
 	OnResize(func()) EventSubscription
@@ -87,4 +91,7 @@ type Window interface {
 	OnKeyUp(func(KeyboardEvent)) EventSubscription
 	OnKeyRepeat(func(KeyboardEvent)) EventSubscription
 	OnKeyStroke(func(KeyStrokeEvent)) EventSubscription
+	OnCompositionStart(func(CompositionEvent)) EventSubscription
+	OnCompositionUpdate(func(CompositionEvent)) EventSubscription
+	OnCompositionEnd(func(CompositionEvent)) EventSubscription
 }